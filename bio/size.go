@@ -10,7 +10,7 @@ func SizeVarint(n int) int {
 	}
 
 	if n <= 0xffffffff {
-		return 3
+		return 5
 	}
 
 	return 9
@@ -9,10 +9,26 @@ type VerifyFlag uint64
 const (
 	DefaultSequence = math.MaxUint32
 
+	// ReplaceableSequence signals that a transaction may be replaced
+	// by one paying a higher fee.
+	ReplaceableSequence = math.MaxUint32 - 2
+
 	ReceiveBranch = uint32(0)
 	ChangeBranch  = uint32(1)
 
 	SignMessageMagic = "handshake signed message:\n"
+
+	WitnessScaleFactor = 4
+
+	// MaxTxWeight is the largest transaction weight relayed by hsd.
+	MaxTxWeight = 400000
+
+	// TargetSpacing is the target number of seconds between blocks.
+	TargetSpacing = 10 * 60
+
+	// MaxMoney is the total supply of HNS in subunits. No output or
+	// transaction can exceed it.
+	MaxMoney = 2040000000 * 1000000
 )
 
 var (
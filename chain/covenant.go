@@ -261,3 +261,53 @@ func NewFinalizeCovenant(name string, weak bool, renewalHash gcrypto.Hash, heigh
 		},
 	}
 }
+
+const (
+	// MaxBlockOpens, MaxBlockUpdates, and MaxBlockRenewals are the consensus
+	// limits on name covenants per block. A transaction exceeding them can
+	// never be mined.
+	MaxBlockOpens    = 300
+	MaxBlockUpdates  = 600
+	MaxBlockRenewals = 600
+
+	// MaxTxOpens, MaxTxUpdates, and MaxTxRenewals limit name covenants per
+	// transaction. A transaction at the per-block limit could only be mined
+	// in a block without any other covenants of the same type, so single
+	// transactions are held to half of it.
+	MaxTxOpens    = MaxBlockOpens / 2
+	MaxTxUpdates  = MaxBlockUpdates / 2
+	MaxTxRenewals = MaxBlockRenewals / 2
+)
+
+func CountOpens(outputs []*Output) int {
+	var total int
+	for _, out := range outputs {
+		if out.Covenant.Type == CovenantOpen {
+			total++
+		}
+	}
+	return total
+}
+
+func CountUpdates(outputs []*Output) int {
+	var total int
+	for _, out := range outputs {
+		switch out.Covenant.Type {
+		case CovenantClaim, CovenantRegister, CovenantUpdate, CovenantRenew,
+			CovenantTransfer, CovenantFinalize, CovenantRevoke:
+			total++
+		}
+	}
+	return total
+}
+
+func CountRenewals(outputs []*Output) int {
+	var total int
+	for _, out := range outputs {
+		switch out.Covenant.Type {
+		case CovenantRegister, CovenantRenew, CovenantFinalize:
+			total++
+		}
+	}
+	return total
+}
@@ -0,0 +1,29 @@
+package chain
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCountCovenants(t *testing.T) {
+	var outputs []*Output
+	for _, typ := range []CovenantType{
+		CovenantNone,
+		CovenantOpen,
+		CovenantOpen,
+		CovenantBid,
+		CovenantRegister,
+		CovenantUpdate,
+		CovenantRenew,
+		CovenantFinalize,
+		CovenantRevoke,
+	} {
+		outputs = append(outputs, &Output{
+			Covenant: &Covenant{Type: typ},
+		})
+	}
+
+	require.Equal(t, 2, CountOpens(outputs))
+	require.Equal(t, 5, CountUpdates(outputs))
+	require.Equal(t, 3, CountRenewals(outputs))
+}
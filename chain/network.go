@@ -11,22 +11,24 @@ const (
 )
 
 type Network struct {
-	Net              wire.BitcoinNet
-	Name             string
-	WalletPort       int
-	NodePort         int
-	AddressHRP       string
-	HasReserved      bool
-	ClaimPeriod      int
-	CoinbaseMaturity int
-	RenewalMaturity  int
-	RolloutInterval  int
-	AuctionStart     int
-	TreeInterval     int
-	BiddingPeriod    int
-	RevealPeriod     int
-	TransferLockup   int
-	KeyPrefix        *NetworkKeyPrefix
+	Net                 wire.BitcoinNet
+	Name                string
+	WalletPort          int
+	NodePort            int
+	AddressHRP          string
+	HasReserved         bool
+	ClaimPeriod         int
+	CoinbaseMaturity    int
+	RenewalMaturity     int
+	RolloutInterval     int
+	AuctionStart        int
+	TreeInterval        int
+	BiddingPeriod       int
+	RevealPeriod        int
+	TransferLockup      int
+	RenewalWindow       int
+	ExpiryWarningBlocks int
+	KeyPrefix           *NetworkKeyPrefix
 
 	chainParams *chaincfg.Params
 }
@@ -39,21 +41,23 @@ type NetworkKeyPrefix struct {
 }
 
 var NetworkMain = &Network{
-	Net:              1533997779,
-	Name:             "main",
-	WalletPort:       12039,
-	NodePort:         12037,
-	AddressHRP:       "hs",
-	HasReserved:      true,
-	ClaimPeriod:      210240,
-	CoinbaseMaturity: 100,
-	RenewalMaturity:  4320,
-	RolloutInterval:  1008,
-	AuctionStart:     2016,
-	TreeInterval:     36,
-	BiddingPeriod:    720,
-	RevealPeriod:     1440,
-	TransferLockup:   288,
+	Net:                 1533997779,
+	Name:                "main",
+	WalletPort:          12039,
+	NodePort:            12037,
+	AddressHRP:          "hs",
+	HasReserved:         true,
+	ClaimPeriod:         210240,
+	CoinbaseMaturity:    100,
+	RenewalMaturity:     4320,
+	RolloutInterval:     1008,
+	AuctionStart:        2016,
+	TreeInterval:        36,
+	BiddingPeriod:       720,
+	RevealPeriod:        1440,
+	TransferLockup:      288,
+	RenewalWindow:       105120,
+	ExpiryWarningBlocks: 4320,
 	KeyPrefix: &NetworkKeyPrefix{
 		Private:  0x80,
 		XPub:     [4]byte{0x04, 0x88, 0xb2, 0x1e},
@@ -63,21 +67,23 @@ var NetworkMain = &Network{
 }
 
 var NetworkRegtest = &Network{
-	Net:              2922943951,
-	Name:             "regtest",
-	WalletPort:       14039,
-	NodePort:         14037,
-	AddressHRP:       "rs",
-	HasReserved:      false,
-	ClaimPeriod:      250000,
-	CoinbaseMaturity: 2,
-	RenewalMaturity:  50,
-	RolloutInterval:  2,
-	AuctionStart:     0,
-	TreeInterval:     5,
-	BiddingPeriod:    5,
-	RevealPeriod:     10,
-	TransferLockup:   10,
+	Net:                 2922943951,
+	Name:                "regtest",
+	WalletPort:          14039,
+	NodePort:            14037,
+	AddressHRP:          "rs",
+	HasReserved:         false,
+	ClaimPeriod:         250000,
+	CoinbaseMaturity:    2,
+	RenewalMaturity:     50,
+	RolloutInterval:     2,
+	AuctionStart:        0,
+	TreeInterval:        5,
+	BiddingPeriod:       5,
+	RevealPeriod:        10,
+	TransferLockup:      10,
+	RenewalWindow:       5000,
+	ExpiryWarningBlocks: 100,
 	KeyPrefix: &NetworkKeyPrefix{
 		Private:  0x5a,
 		XPub:     [4]byte{0xea, 0xb4, 0xfa, 0x05},
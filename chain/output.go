@@ -13,7 +13,7 @@ type Output struct {
 }
 
 func (o *Output) Size() int {
-	return 8 + o.Covenant.Size()
+	return 8 + o.Address.Size() + o.Covenant.Size()
 }
 
 func (o *Output) WriteTo(w io.Writer) (int64, error) {
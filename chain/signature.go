@@ -3,6 +3,7 @@ package chain
 import (
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
 	"math/big"
 )
 
@@ -33,3 +34,39 @@ func DeserializeSignature(b []byte) (*btcec.Signature, error) {
 	sig.S = new(big.Int).SetBytes(b[32:])
 	return sig, nil
 }
+
+// MessageHash returns the digest signed by signmessage.
+func MessageHash(msg []byte) []byte {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte(SignMessageMagic))
+	h.Write(msg)
+	return h.Sum(nil)
+}
+
+// VerifyMessage checks that sig, a 64-byte signature as produced by
+// signmessage, was made over msg by the key behind addr. Signatures don't
+// carry a recovery ID, so each possible public key is tried in turn.
+func VerifyMessage(addr *Address, msg []byte, sig []byte) (bool, error) {
+	if !addr.IsPubkeyHash() {
+		return false, errors.New("can only verify messages signed by pubkey hash addresses")
+	}
+	if len(sig) != 64 {
+		return false, errors.New("mal-formed signature")
+	}
+
+	hash := MessageHash(msg)
+	compact := make([]byte, 65)
+	copy(compact[1:], sig)
+	for recID := byte(0); recID < 4; recID++ {
+		// 27 marks a compact signature and 4 a compressed public key
+		compact[0] = 27 + 4 + recID
+		pub, _, err := btcec.RecoverCompact(btcec.S256(), compact, hash)
+		if err != nil {
+			continue
+		}
+		if NewAddressFromPubkey(pub).Equal(addr) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
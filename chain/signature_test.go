@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestVerifyMessage(t *testing.T) {
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err)
+	addr := NewAddressFromPubkey(priv.PubKey())
+	msg := []byte("hello world")
+
+	for i := 0; i < 10; i++ {
+		sig, err := priv.Sign(MessageHash(msg))
+		require.NoError(t, err)
+		ok, err := VerifyMessage(addr, msg, SerializeSignature(sig))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = VerifyMessage(addr, []byte("goodbye world"), SerializeSignature(sig))
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+
+	other, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err)
+	sig, err := other.Sign(MessageHash(msg))
+	require.NoError(t, err)
+	ok, err := VerifyMessage(addr, msg, SerializeSignature(sig))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = VerifyMessage(addr, msg, make([]byte, 63))
+	require.Error(t, err)
+	_, err = VerifyMessage(NewAddressFromHash(make([]byte, 32)), msg, SerializeSignature(sig))
+	require.Error(t, err)
+}
@@ -89,6 +89,21 @@ func (tx *Transaction) ReadFrom(r io.Reader) (n int64, err error) {
 	return g.N, nil
 }
 
+// VirtualSize returns the transaction's weight divided by the witness
+// scale factor, which is the size fee rates are measured against.
+func (tx *Transaction) VirtualSize() int {
+	base, err := tx.writeTo(io.Discard, false)
+	if err != nil {
+		panic(err)
+	}
+	total, err := tx.WriteTo(io.Discard)
+	if err != nil {
+		panic(err)
+	}
+	weight := int(base)*(WitnessScaleFactor-1) + int(total)
+	return (weight + WitnessScaleFactor - 1) / WitnessScaleFactor
+}
+
 func (tx *Transaction) Bytes() []byte {
 	buf := new(bytes.Buffer)
 	if _, err := tx.WriteTo(buf); err != nil {
@@ -32,6 +32,14 @@ func NewP2PKHWitness(sig *btcec.Signature, sigOpts SigOpt, pub *btcec.PublicKey)
 	}
 }
 
+func (wit *Witness) Size() int {
+	size := bio.SizeVarint(len(wit.Items))
+	for _, item := range wit.Items {
+		size += bio.SizeVarint(len(item)) + len(item)
+	}
+	return size
+}
+
 func (wit *Witness) WriteTo(w io.Writer) (int64, error) {
 	g := bio.NewGuardWriter(w)
 	bio.WriteVarint(g, uint64(len(wit.Items)))
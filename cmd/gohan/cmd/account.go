@@ -1,17 +1,46 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/wallet"
+	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/kurumiimari/gohan/walletdb"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"os"
 	"strconv"
+	"time"
 )
 
 var (
-	accountID  string
-	createOnly bool
+	accountID     string
+	createOnly    bool
+	useCoins      []string
+	excludeCoins  []string
+	coinSelection string
+	noRBF         bool
+	bumpMethod    string
+	sendCSV       string
+	txsName       string
+	txsNamePrefix string
+	txsCovenant   string
+	txsAddress    string
+	txsDirection  string
+	txsMinValue   float64
+	txsMaxValue   float64
+	txsMinHeight  int
+	txsMaxHeight  int
+	txsSince      string
+	txsUntil      string
+	txsStatus     string
+	txsSort       string
+	txsAscending  bool
+	txsCursor     string
+	addrsBranch   string
+	discoverAddrs bool
 )
 
 var accountInfoCmd = &cobra.Command{
@@ -34,6 +63,9 @@ var accountInfoCmd = &cobra.Command{
 var accountTxsCmd = &cobra.Command{
 	Use:   "transactions <page> <per-page>",
 	Short: "Lists transactions for an account",
+	Long: `Lists transactions for an account, newest first. Use the flags to filter
+and sort the list. When there are more results, the cursor for the next
+page is printed to stderr; pass it back with --cursor to continue.`,
 	Aliases: []string{
 		"txs",
 	},
@@ -53,18 +85,90 @@ var accountTxsCmd = &cobra.Command{
 			perPage = intArg(args[1], 50)
 		}
 
+		filter, err := txFilterFromFlags(cmd, perPage, (page-1)*perPage)
+		if err != nil {
+			return err
+		}
+
 		client, err := apiClient()
 		if err != nil {
 			return err
 		}
-		res, err := client.GetAccountTransactions(accountID, perPage, (page-1)*perPage)
+		res, next, err := client.SearchTransactions(accountID, filter)
 		if err != nil {
 			return err
 		}
+		if next != "" {
+			fmt.Fprintf(os.Stderr, "next cursor: %s\n", next)
+		}
 		return printJSON(res)
 	},
 }
 
+func txFilterFromFlags(cmd *cobra.Command, count, offset int) (*walletdb.TransactionFilter, error) {
+	if txsCursor != "" {
+		offset = 0
+	}
+	q := api.PaginationQuery(count, offset)
+	setIfNotEmpty := func(key, val string) {
+		if val != "" {
+			q.Set(key, val)
+		}
+	}
+	setIfNotEmpty("name", txsName)
+	setIfNotEmpty("name_prefix", txsNamePrefix)
+	setIfNotEmpty("covenant", txsCovenant)
+	setIfNotEmpty("address", txsAddress)
+	setIfNotEmpty("direction", txsDirection)
+	setIfNotEmpty("status", txsStatus)
+	setIfNotEmpty("sort", txsSort)
+	setIfNotEmpty("cursor", txsCursor)
+	if txsAscending {
+		q.Set("order", "asc")
+	}
+	if cmd.Flags().Changed("min-value") {
+		q.Set("min_value", strconv.FormatUint(uint64(txsMinValue*1000000), 10))
+	}
+	if cmd.Flags().Changed("max-value") {
+		q.Set("max_value", strconv.FormatUint(uint64(txsMaxValue*1000000), 10))
+	}
+	if cmd.Flags().Changed("min-height") {
+		q.Set("min_height", strconv.Itoa(txsMinHeight))
+	}
+	if cmd.Flags().Changed("max-height") {
+		q.Set("max_height", strconv.Itoa(txsMaxHeight))
+	}
+	for key, val := range map[string]string{
+		"start_time": txsSince,
+		"end_time":   txsUntil,
+	} {
+		if val == "" {
+			continue
+		}
+		ts, err := parseTimeArg(val)
+		if err != nil {
+			return nil, err
+		}
+		q.Set(key, strconv.FormatInt(ts, 10))
+	}
+	return api.ParseTransactionFilter(q)
+}
+
+// parseTimeArg accepts a date (YYYY-MM-DD), an RFC 3339 timestamp, or a
+// Unix timestamp.
+func parseTimeArg(in string) (int64, error) {
+	if ts, err := strconv.ParseInt(in, 10, 64); err == nil {
+		return ts, nil
+	}
+	if t, err := time.Parse("2006-01-02", in); err == nil {
+		return t.Unix(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, in); err == nil {
+		return t.Unix(), nil
+	}
+	return 0, errors.Errorf("invalid time %s", in)
+}
+
 var accountNamesCmd = &cobra.Command{
 	Use:   "names",
 	Short: "Lists names for an account",
@@ -102,8 +206,15 @@ var accountNameHistoryCmd = &cobra.Command{
 var accountSendCmd = &cobra.Command{
 	Use:   "send <recipient-address> <amount-whole-hns> [override-fee-rate-subunits]",
 	Short: "Sends funds",
-	Args:  cobra.RangeArgs(2, 3),
+	Args:  cobra.RangeArgs(0, 3),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if sendCSV != "" {
+			return sendToCSV(args)
+		}
+		if len(args) < 2 {
+			return errors.New("must specify a recipient address and amount")
+		}
+
 		_, err := chain.NewAddressFromBech32(args[0])
 		if err != nil {
 			return errors.New("invalid recipient address")
@@ -123,7 +234,7 @@ var accountSendCmd = &cobra.Command{
 			}
 		}
 
-		client, err := apiClient()
+		client, err := coinControlClient()
 		if err != nil {
 			return err
 		}
@@ -135,6 +246,37 @@ var accountSendCmd = &cobra.Command{
 	},
 }
 
+func sendToCSV(args []string) error {
+	if len(args) > 1 {
+		return errors.New("only a fee rate may be specified alongside --csv")
+	}
+
+	var feeRate uint64
+	if len(args) == 1 {
+		var err error
+		feeRate, err = strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return errors.New("invalid override fee rate")
+		}
+	}
+
+	f, err := os.Open(sendCSV)
+	if err != nil {
+		return errors.Wrap(err, "error opening recipients file")
+	}
+	defer f.Close()
+
+	client, err := coinControlClient()
+	if err != nil {
+		return err
+	}
+	res, err := client.SendCSV(accountID, f, feeRate, createOnly)
+	if err != nil {
+		return err
+	}
+	return printJSON(res)
+}
+
 var accountOpenCmd = &cobra.Command{
 	Use:   "open <name> [override-fee-rate-subunits]",
 	Short: "Opens a name for bidding",
@@ -150,7 +292,7 @@ var accountOpenCmd = &cobra.Command{
 			}
 		}
 
-		client, err := apiClient()
+		client, err := coinControlClient()
 		if err != nil {
 			return err
 		}
@@ -186,7 +328,7 @@ var accountBidCmd = &cobra.Command{
 			}
 		}
 
-		client, err := apiClient()
+		client, err := coinControlClient()
 		if err != nil {
 			return err
 		}
@@ -221,7 +363,7 @@ var accountRevealCmd = &cobra.Command{
 			}
 		}
 
-		client, err := apiClient()
+		client, err := coinControlClient()
 		if err != nil {
 			return err
 		}
@@ -249,7 +391,7 @@ var accountRedeemCmd = &cobra.Command{
 			}
 		}
 
-		client, err := apiClient()
+		client, err := coinControlClient()
 		if err != nil {
 			return err
 		}
@@ -286,7 +428,7 @@ var accountUpdateCmd = &cobra.Command{
 			}
 		}
 
-		client, err := apiClient()
+		client, err := coinControlClient()
 		if err != nil {
 			return err
 		}
@@ -317,7 +459,7 @@ var accountTransferCmd = &cobra.Command{
 			}
 		}
 
-		client, err := apiClient()
+		client, err := coinControlClient()
 		if err != nil {
 			return err
 		}
@@ -344,7 +486,7 @@ var accountFinalizeCmd = &cobra.Command{
 			}
 		}
 
-		client, err := apiClient()
+		client, err := coinControlClient()
 		if err != nil {
 			return err
 		}
@@ -356,6 +498,289 @@ var accountFinalizeCmd = &cobra.Command{
 	},
 }
 
+var accountBatchCmd = &cobra.Command{
+	Use:   "batch <actions-json-file> [override-fee-rate-subunits]",
+	Short: "Performs several name actions in a single transaction",
+	Long: `Performs several name actions in a single transaction. The actions file
+contains a JSON array of objects with a type (OPEN, BID, REVEAL, REDEEM, RENEW,
+or UPDATE) and a name. Bids also take a value and lockup in subunits, and
+updates take a resource.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return errors.Wrap(err, "error opening actions file")
+		}
+		defer f.Close()
+
+		var actions []*wallet.BatchAction
+		if err := json.NewDecoder(f).Decode(&actions); err != nil {
+			return errors.Wrap(err, "invalid actions file")
+		}
+
+		var feeRate uint64
+		if len(args) == 2 {
+			feeRate, err = strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return errors.New("invalid override fee rate")
+			}
+		}
+
+		client, err := coinControlClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.Batch(accountID, actions, feeRate, createOnly)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var accountBumpFeeCmd = &cobra.Command{
+	Use:   "bump <tx-hash> [fee-rate-subunits]",
+	Short: "Bumps the fee of an unconfirmed transaction",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var feeRate uint64
+		if len(args) > 1 {
+			var err error
+			feeRate, err = strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return errors.New("invalid fee rate")
+			}
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.BumpFee(accountID, args[0], bumpMethod, feeRate)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var accountAbandonCmd = &cobra.Command{
+	Use:   "abandon <tx-hash>",
+	Short: "Removes an unconfirmed transaction from the wallet",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		return client.AbandonTx(accountID, args[0])
+	},
+}
+
+var accountEventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Streams account events as they happen",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		events, err := client.SubscribeEvents(context.Background(), accountID)
+		if err != nil {
+			return err
+		}
+		for evt := range events {
+			out, err := json.Marshal(evt)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+		}
+		return errors.New("event stream closed")
+	},
+}
+
+var accountAutoRevealCmd = &cobra.Command{
+	Use:   "auto-reveal <on|off>",
+	Short: "Enables or disables automatically revealing bids",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var enabled bool
+		switch args[0] {
+		case "on":
+			enabled = true
+		case "off":
+		default:
+			return errors.New("must specify on or off")
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		return client.SetAutoReveal(accountID, enabled)
+	},
+}
+
+var accountAutoSweepCmd = &cobra.Command{
+	Use:   "auto-sweep <on|off> [register-resource]",
+	Short: "Enables or disables automatically redeeming and registering closed auctions",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var enabled bool
+		switch args[0] {
+		case "on":
+			enabled = true
+		case "off":
+		default:
+			return errors.New("must specify on or off")
+		}
+
+		var resource *chain.Resource
+		if len(args) > 1 {
+			resource = new(chain.Resource)
+			if err := json.Unmarshal([]byte(args[1]), resource); err != nil {
+				return errors.Wrap(err, "invalid resource")
+			}
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		return client.SetAutoSweep(accountID, enabled, resource)
+	},
+}
+
+var accountAutoRenewCmd = &cobra.Command{
+	Use:   "auto-renew <blocks|off>",
+	Short: "Enables or disables automatically renewing names before they expire",
+	Long:  "Enables or disables automatically renewing names. When enabled, owned names are renewed once they are within the given number of blocks of expiring.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var blocks int
+		if args[0] != "off" {
+			var err error
+			blocks, err = strconv.Atoi(args[0])
+			if err != nil || blocks <= 0 {
+				return errors.New("must specify a positive number of blocks or off")
+			}
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		return client.SetAutoRenew(accountID, blocks)
+	},
+}
+
+var accountCoinsCmd = &cobra.Command{
+	Use:   "coins",
+	Short: "Lists unspent coins for an account",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.Coins(accountID)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var accountAddressesCmd = &cobra.Command{
+	Use:   "addresses <count> <offset>",
+	Short: "Lists the account's addresses with their balances and usage",
+	Args:  cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var count int
+		var offset int
+		switch len(args) {
+		case 0:
+			count = 50
+		case 1:
+			count = intArg(args[0], 50)
+		case 2:
+			count = intArg(args[0], 50)
+			offset = intArg(args[1], 0)
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.GetAddresses(accountID, addrsBranch, count, offset)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var accountAddressCmd = &cobra.Command{
+	Use:   "address <address>",
+	Short: "Checks whether an address belongs to the account and shows its usage",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.GetAddress(accountID, args[0])
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var accountFreezeCoinCmd = &cobra.Command{
+	Use:   "freeze-coin <hash/index>...",
+	Short: "Prevents coins from being selected to fund transactions",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outpoints, err := outpointArgs(args)
+		if err != nil {
+			return err
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		if err := client.FreezeCoins(accountID, outpoints); err != nil {
+			return err
+		}
+		fmt.Println("OK")
+		return nil
+	},
+}
+
+var accountUnfreezeCoinCmd = &cobra.Command{
+	Use:   "unfreeze-coin <hash/index>...",
+	Short: "Allows frozen coins to fund transactions again",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outpoints, err := outpointArgs(args)
+		if err != nil {
+			return err
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		if err := client.UnfreezeCoins(accountID, outpoints); err != nil {
+			return err
+		}
+		fmt.Println("OK")
+		return nil
+	},
+}
+
 var accountZapCmd = &cobra.Command{
 	Use:   "zap",
 	Short: "Zaps pending transactions",
@@ -386,7 +811,11 @@ var accountRescanCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		if err := client.Rescan(accountID, heightInt); err != nil {
+		rescan := client.Rescan
+		if discoverAddrs {
+			rescan = client.DiscoveryRescan
+		}
+		if err := rescan(accountID, heightInt); err != nil {
 			return err
 		}
 		fmt.Printf("Rescanning from block %d.\n", heightInt)
@@ -430,6 +859,40 @@ var accountSignMessageWithNameCmd = &cobra.Command{
 	},
 }
 
+var verifyMessageCmd = &cobra.Command{
+	Use:   "verify-message [address] [signature] [message]",
+	Short: "Verifies a message signed by an address",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.VerifyMessage(args[0], args[1], args[2])
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var verifyMessageWithNameCmd = &cobra.Command{
+	Use:   "verify-message-with-name [name] [signature] [message]",
+	Short: "Verifies a message signed by a name's current owner",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.VerifyMessageWithName(args[0], args[1], args[2])
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
 var accountUnspentBidsCmd = &cobra.Command{
 	Use:   "unspent-bids <count> <offset>",
 	Short: "Returns all bids that haven't been revealed yet",
@@ -491,9 +954,25 @@ var accountUnspentRevealsCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(accountInfoCmd)
 	rootCmd.AddCommand(accountTxsCmd)
+	accountTxsCmd.Flags().StringVar(&txsName, "name", "", "Only show transactions involving this name.")
+	accountTxsCmd.Flags().StringVar(&txsNamePrefix, "name-prefix", "", "Only show transactions involving names starting with this prefix.")
+	accountTxsCmd.Flags().StringVar(&txsCovenant, "covenant", "", "Only show transactions with an output of this covenant type, e.g. bid or register.")
+	accountTxsCmd.Flags().StringVar(&txsAddress, "address", "", "Only show transactions sending to or spending from this address.")
+	accountTxsCmd.Flags().StringVar(&txsDirection, "direction", "", "Only show incoming (in) or outgoing (out) transactions.")
+	accountTxsCmd.Flags().Float64Var(&txsMinValue, "min-value", 0, "Only show transactions that moved at least this many whole HNS.")
+	accountTxsCmd.Flags().Float64Var(&txsMaxValue, "max-value", 0, "Only show transactions that moved at most this many whole HNS.")
+	accountTxsCmd.Flags().IntVar(&txsMinHeight, "min-height", 0, "Only show transactions confirmed at or above this height.")
+	accountTxsCmd.Flags().IntVar(&txsMaxHeight, "max-height", 0, "Only show transactions confirmed at or below this height.")
+	accountTxsCmd.Flags().StringVar(&txsSince, "since", "", "Only show transactions from this time onward (YYYY-MM-DD, RFC 3339, or Unix time).")
+	accountTxsCmd.Flags().StringVar(&txsUntil, "until", "", "Only show transactions up to this time (YYYY-MM-DD, RFC 3339, or Unix time).")
+	accountTxsCmd.Flags().StringVar(&txsStatus, "status", "", "Only show transactions with this status: pending, confirmed, or dropped.")
+	accountTxsCmd.Flags().StringVar(&txsSort, "sort", "height", "Sort by height or time.")
+	accountTxsCmd.Flags().BoolVar(&txsAscending, "asc", false, "Sort oldest first.")
+	accountTxsCmd.Flags().StringVar(&txsCursor, "cursor", "", "Continue from the cursor printed by a previous page. Replaces the page argument.")
 	rootCmd.AddCommand(accountNamesCmd)
 	rootCmd.AddCommand(accountNameHistoryCmd)
 	rootCmd.AddCommand(accountSendCmd)
+	accountSendCmd.Flags().StringVar(&sendCSV, "csv", "", "Path to a CSV file of address,amount-whole-hns rows to pay in a single transaction.")
 	rootCmd.AddCommand(accountOpenCmd)
 	rootCmd.AddCommand(accountBidCmd)
 	rootCmd.AddCommand(accountRevealCmd)
@@ -501,10 +980,70 @@ func init() {
 	rootCmd.AddCommand(accountUpdateCmd)
 	rootCmd.AddCommand(accountTransferCmd)
 	rootCmd.AddCommand(accountFinalizeCmd)
+	rootCmd.AddCommand(accountBatchCmd)
+	rootCmd.AddCommand(accountBumpFeeCmd)
+	accountBumpFeeCmd.Flags().StringVar(&bumpMethod, "method", "rbf", "Fee bump method: rbf to replace the transaction, or cpfp to spend its change.")
+	rootCmd.AddCommand(accountAbandonCmd)
+	rootCmd.AddCommand(accountEventsCmd)
+	rootCmd.AddCommand(accountAutoRevealCmd)
+	rootCmd.AddCommand(accountAutoSweepCmd)
+	rootCmd.AddCommand(accountAutoRenewCmd)
+	rootCmd.AddCommand(accountCoinsCmd)
+	rootCmd.AddCommand(accountAddressesCmd)
+	accountAddressesCmd.Flags().StringVar(&addrsBranch, "branch", "", "Only show addresses on this branch: receive, change, or dutch_auction.")
+	rootCmd.AddCommand(accountAddressCmd)
+	rootCmd.AddCommand(accountFreezeCoinCmd)
+	rootCmd.AddCommand(accountUnfreezeCoinCmd)
 	rootCmd.AddCommand(accountZapCmd)
 	rootCmd.AddCommand(accountRescanCmd)
+	accountRescanCmd.Flags().BoolVar(&discoverAddrs, "discover", false, "Keeps rescanning with a wider lookahead while new address activity turns up.")
 	rootCmd.AddCommand(accountSignMessageCmd)
 	rootCmd.AddCommand(accountSignMessageWithNameCmd)
+	rootCmd.AddCommand(verifyMessageCmd)
+	rootCmd.AddCommand(verifyMessageWithNameCmd)
 	rootCmd.AddCommand(accountUnspentBidsCmd)
 	rootCmd.AddCommand(accountUnspentRevealsCmd)
+
+	for _, cmd := range []*cobra.Command{
+		accountSendCmd,
+		accountOpenCmd,
+		accountBidCmd,
+		accountRevealCmd,
+		accountRedeemCmd,
+		accountUpdateCmd,
+		accountTransferCmd,
+		accountFinalizeCmd,
+		accountBatchCmd,
+	} {
+		addCoinControlFlags(cmd)
+	}
+}
+
+func addCoinControlFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&useCoins, "coin", nil, "Fund the transaction using only this coin (hash/index). May be specified multiple times.")
+	cmd.Flags().StringSliceVar(&excludeCoins, "exclude-coin", nil, "Never fund the transaction with this coin (hash/index). May be specified multiple times.")
+	cmd.Flags().StringVar(&coinSelection, "coin-selection", "", "Coin selection strategy: branch_and_bound, largest_first, smallest_first, or oldest_first.")
+	cmd.Flags().BoolVar(&noRBF, "no-rbf", false, "Don't signal replace-by-fee, so the transaction can only be bumped with cpfp.")
+}
+
+func coinControlClient() (*api.Client, error) {
+	coins, err := outpointArgs(useCoins)
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := outpointArgs(excludeCoins)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := apiClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.WithCoinControl(api.CoinControl{
+		Coins:         coins,
+		ExcludeCoins:  excluded,
+		CoinSelection: coinSelection,
+		NoRBF:         noRBF,
+	}), nil
 }
@@ -9,6 +9,15 @@ import (
 	"syscall"
 )
 
+var (
+	multisigThreshold    int
+	multisigCosigners    []string
+	receiveGapLimit      uint32
+	changeGapLimit       uint32
+	dutchAuctionGapLimit uint32
+	usePassphrase        bool
+)
+
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Creates a wallet",
@@ -27,18 +36,29 @@ var createCmd = &cobra.Command{
 			return errors.Wrap(err, "error reading password")
 		}
 
+		passphrase, err := readPassphrase(true)
+		if err != nil {
+			return err
+		}
+
 		fmt.Println("Creating wallet...")
 
 		res, err := client.CreateAccount(&api.CreateAccountReq{
-			ID:       accountID,
-			Password: string(pwB),
+			ID:                   accountID,
+			Passphrase:           passphrase,
+			Password:             string(pwB),
+			Threshold:            multisigThreshold,
+			Cosigners:            multisigCosigners,
+			ReceiveGapLimit:      receiveGapLimit,
+			ChangeGapLimit:       changeGapLimit,
+			DutchAuctionGapLimit: dutchAuctionGapLimit,
 		})
 		if err != nil {
 			return errors.Wrap(err, "error creating wallet")
 		}
 
 		fmt.Println("Your wallet has been successfully created. Please take note of your seed phrase below.")
-		fmt.Println("STORE YOUR SEED PHRASE SECURELY. IT WILL NOT BE SHOWN AGAIN.")
+		fmt.Println("STORE YOUR SEED PHRASE SECURELY. You can show it again with `gohan mnemonic`.")
 		fmt.Println("")
 		fmt.Println(*res.Mnemonic)
 		return nil
@@ -47,4 +67,44 @@ var createCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(createCmd)
+	addMultisigFlags(createCmd)
+	addGapLimitFlags(createCmd)
+	addPassphraseFlag(createCmd)
+}
+
+func addMultisigFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&multisigThreshold, "threshold", 0, "Number of signatures required to spend from a multisig wallet.")
+	cmd.Flags().StringSliceVar(&multisigCosigners, "cosigner", nil, "A cosigner's account xpub. Specify once per cosigner to create a multisig wallet.")
+}
+
+func addGapLimitFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint32Var(&receiveGapLimit, "receive-gap-limit", 0, "Number of unused receive addresses to watch past the last used one. Defaults to 1000.")
+	cmd.Flags().Uint32Var(&changeGapLimit, "change-gap-limit", 0, "Number of unused change addresses to watch past the last used one. Defaults to 1000.")
+	cmd.Flags().Uint32Var(&dutchAuctionGapLimit, "dutch-auction-gap-limit", 0, "Number of unused Dutch auction addresses to watch past the last used one. Defaults to 10.")
+}
+
+func addPassphraseFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&usePassphrase, "passphrase", false, "Prompts for a BIP39 passphrase (the \"25th word\") to combine with the seed phrase.")
+}
+
+// readPassphrase prompts for a BIP39 passphrase if --passphrase was set.
+func readPassphrase(confirm bool) (string, error) {
+	if !usePassphrase {
+		return "", nil
+	}
+	passphrase, err := readSecret("Please enter your BIP39 passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if !confirm {
+		return passphrase, nil
+	}
+	again, err := readSecret("Please confirm your BIP39 passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != again {
+		return "", errors.New("passphrases do not match")
+	}
+	return passphrase, nil
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/kurumiimari/gohan/wallet"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports an account's transactions for bookkeeping",
+	Long: `Exports every confirmed and pending transaction, oldest first, with its
+net value, fee, counterparties, covenant actions, names, and the running
+balance. CSV values are in whole HNS; JSON values are in dollarydoos.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportFormat != "csv" && exportFormat != "json" {
+			return errors.New("format must be csv or json")
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		rows, err := client.Export(accountID)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if exportOutput != "" {
+			f, err := os.Create(exportOutput)
+			if err != nil {
+				return errors.Wrap(err, "error creating output file")
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if exportFormat == "json" {
+			return writeJSON(out, rows)
+		}
+		return wallet.WriteExportCSV(out, rows)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportFormat, "format", "csv", "Output format: csv or json.")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write the export to this file instead of stdout.")
+}
@@ -11,6 +11,8 @@ import (
 
 var (
 	importCmdWatchOnly bool
+	importCmdSigner    string
+	importCmdDiscover  bool
 )
 
 var importCmd = &cobra.Command{
@@ -46,12 +48,23 @@ func handleMnemonic(client *api.Client, password, name string) error {
 	if err != nil {
 		return errors.Wrap(err, "error reading mnemonic")
 	}
+	passphrase, err := readPassphrase(false)
+	if err != nil {
+		return err
+	}
 
 	fmt.Print("Creating wallet... ")
 	_, err = client.CreateAccount(&api.CreateAccountReq{
-		ID:       name,
-		Mnemonic: string(mnemonicB),
-		Password: password,
+		ID:                   name,
+		Mnemonic:             string(mnemonicB),
+		Passphrase:           passphrase,
+		Password:             password,
+		Threshold:            multisigThreshold,
+		Cosigners:            multisigCosigners,
+		ReceiveGapLimit:      receiveGapLimit,
+		ChangeGapLimit:       changeGapLimit,
+		DutchAuctionGapLimit: dutchAuctionGapLimit,
+		Discover:             importCmdDiscover,
 	})
 	if err != nil {
 		return errors.Wrap(err, "error creating wallet")
@@ -71,9 +84,16 @@ func handleWatchOnly(client *api.Client, password, name string) error {
 
 	fmt.Print("Creating wallet... ")
 	_, err = client.CreateAccount(&api.CreateAccountReq{
-		ID:       name,
-		XPub:     string(xPubB),
-		Password: password,
+		ID:                   name,
+		XPub:                 string(xPubB),
+		Password:             password,
+		Signer:               importCmdSigner,
+		Threshold:            multisigThreshold,
+		Cosigners:            multisigCosigners,
+		ReceiveGapLimit:      receiveGapLimit,
+		ChangeGapLimit:       changeGapLimit,
+		DutchAuctionGapLimit: dutchAuctionGapLimit,
+		Discover:             importCmdDiscover,
 	})
 	if err != nil {
 		return errors.Wrap(err, "error creating wallet")
@@ -86,4 +106,9 @@ func handleWatchOnly(client *api.Client, password, name string) error {
 func init() {
 	rootCmd.AddCommand(importCmd)
 	importCmd.PersistentFlags().BoolVar(&importCmdWatchOnly, "watch-only", false, "Whether this wallet is watch-only.")
+	importCmd.PersistentFlags().StringVar(&importCmdSigner, "signer", "", "Signs transactions for a watch-only wallet with one of the external signers the daemon was started with, by name.")
+	importCmd.PersistentFlags().BoolVar(&importCmdDiscover, "discover", false, "Keeps rescanning with a wider lookahead while new address activity turns up. Use when the wallet may have used addresses beyond the gap limit.")
+	addMultisigFlags(importCmd)
+	addGapLimitFlags(importCmd)
+	addPassphraseFlag(importCmd)
 }
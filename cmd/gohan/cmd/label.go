@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+var labelCmd = &cobra.Command{
+	Use:   "label",
+	Short: "Manage labels for addresses, transactions, and coins",
+}
+
+var setLabelCmd = &cobra.Command{
+	Use:   "set [address|transaction|coin] [target] [label]",
+	Short: "Labels one of the account's addresses, transactions (by hash), or coins (hash/index)",
+	Args:  cobra.MinimumNArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.SetLabel(accountID, args[0], args[1], strings.Join(args[2:], " "))
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var listLabelsCmd = &cobra.Command{
+	Use:   "list [address|transaction|coin]",
+	Short: "Lists labels, optionally only those of one type",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var targetType string
+		if len(args) == 1 {
+			targetType = args[0]
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.GetLabels(accountID, targetType)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var removeLabelCmd = &cobra.Command{
+	Use:   "remove [address|transaction|coin] [target]",
+	Short: "Removes a label",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		return client.RemoveLabel(accountID, args[0], args[1])
+	},
+}
+
+var addressBookCmd = &cobra.Command{
+	Use:   "address-book",
+	Short: "Manage named addresses you send to",
+}
+
+var addContactCmd = &cobra.Command{
+	Use:   "add [name] [address]",
+	Short: "Adds an address to the address book",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.AddContact(accountID, args[0], args[1])
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var listContactsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the address book",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.GetContacts(accountID)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var removeContactCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Removes an address from the address book",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		return client.RemoveContact(accountID, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(labelCmd)
+	labelCmd.AddCommand(setLabelCmd)
+	labelCmd.AddCommand(listLabelsCmd)
+	labelCmd.AddCommand(removeLabelCmd)
+	rootCmd.AddCommand(addressBookCmd)
+	addressBookCmd.AddCommand(addContactCmd)
+	addressBookCmd.AddCommand(listContactsCmd)
+	addressBookCmd.AddCommand(removeContactCmd)
+}
@@ -2,17 +2,22 @@ package cmd
 
 import (
 	"github.com/kurumiimari/gohan"
+	"github.com/kurumiimari/gohan/wallet"
 	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"gopkg.in/tomb.v2"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 var (
 	walletAPIKey string
 	nodeAPIKey   string
+	dropTimeout  time.Duration
+	signerDefs   []string
 )
 
 var statusCmd = &cobra.Command{
@@ -35,6 +40,14 @@ var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Starts the gohan daemon",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if dropTimeout <= 0 {
+			return errors.New("drop timeout must be positive")
+		}
+		signers, err := wallet.ParseExternalSigners(signerDefs)
+		if err != nil {
+			return err
+		}
+
 		tmb := new(tomb.Tomb)
 
 		go func() {
@@ -50,11 +63,22 @@ var startCmd = &cobra.Command{
 			}
 		}()
 
-		return api.Start(tmb, gohan.Config.Network, gohan.Config.Prefix, walletAPIKey, nodeAPIKey, nodeURL)
+		return api.Start(
+			tmb,
+			gohan.Config.Network,
+			gohan.Config.Prefix,
+			walletAPIKey,
+			nodeAPIKey,
+			nodeURL,
+			wallet.WithDropTimeout(dropTimeout),
+			wallet.WithExternalSigners(signers),
+		)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(startCmd)
+	startCmd.Flags().DurationVar(&dropTimeout, "drop-timeout", wallet.DefaultDropTimeout, "Sets how long a pending transaction can be missing from the mempool before it's dropped.")
+	startCmd.Flags().StringArrayVar(&signerDefs, "signer", nil, "Makes an external signer available to watch-only accounts, as <name>=exec:<path> or <name>=unix:<path>. May be specified multiple times.")
 }
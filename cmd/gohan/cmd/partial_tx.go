@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/kurumiimari/gohan/wallet"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var partialTxCmd = &cobra.Command{
+	Use:   "partial-tx",
+	Short: "Export, sign, and broadcast partially signed transactions",
+}
+
+var exportPartialTxCmd = &cobra.Command{
+	Use:   "export [tx-hash]",
+	Short: "Exports an unsigned transaction from a watch-only account",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.PartialTx(accountID, args[0])
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var signPartialTxCmd = &cobra.Command{
+	Use:   "sign [partial-tx-hex]",
+	Short: "Adds this account's signatures to a partially signed transaction",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ptx, err := partialTxArg(args[0])
+		if err != nil {
+			return err
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.SignPartialTx(accountID, ptx)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var finalizePartialTxCmd = &cobra.Command{
+	Use:   "finalize [partial-tx-hex]",
+	Short: "Broadcasts a fully signed partial transaction",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ptx, err := partialTxArg(args[0])
+		if err != nil {
+			return err
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.FinalizePartialTx(accountID, ptx)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+func partialTxArg(in string) (*wallet.PartialTx, error) {
+	raw, err := hex.DecodeString(in)
+	if err != nil {
+		return nil, errors.New("invalid partial transaction hex")
+	}
+	ptx := new(wallet.PartialTx)
+	if _, err := ptx.ReadFrom(bytes.NewReader(raw)); err != nil {
+		return nil, errors.Wrap(err, "invalid partial transaction")
+	}
+	return ptx, nil
+}
+
+func init() {
+	rootCmd.AddCommand(partialTxCmd)
+	partialTxCmd.AddCommand(exportPartialTxCmd)
+	partialTxCmd.AddCommand(signPartialTxCmd)
+	partialTxCmd.AddCommand(finalizePartialTxCmd)
+}
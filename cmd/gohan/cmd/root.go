@@ -16,6 +16,7 @@ var (
 	network   string
 	walletURL string
 	nodeURL   string
+	walletID  string
 )
 
 var cmdLogger = log.ModuleLogger("cmd")
@@ -50,6 +51,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&network, "network", "n", "main", "Set's gohan's network")
 	rootCmd.PersistentFlags().StringVarP(&walletURL, "wallet-url", "u", "", "Sets a custom node RPC server url")
 	rootCmd.PersistentFlags().StringVarP(&accountID, "account-id", "a", "default", "Sets the account ID")
+	rootCmd.PersistentFlags().StringVarP(&walletID, "wallet-id", "w", "", "Sets the wallet ID")
 	rootCmd.PersistentFlags().StringVar(&walletAPIKey, "api-key", "", "Sets the wallet's API key.")
 	rootCmd.PersistentFlags().StringVar(&nodeAPIKey, "node-api-key", "", "Sets the Handshake full node's API key.")
 	rootCmd.PersistentFlags().StringVar(&nodeURL, "node-url", "", "Sets an alternate URL to the Handshake full node.")
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/kurumiimari/gohan/wallet"
+	"github.com/kurumiimari/gohan/wallet/api"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
@@ -33,6 +35,53 @@ var unlockCmd = &cobra.Command{
 	},
 }
 
+var (
+	kdfTime    uint32
+	kdfMemory  uint32
+	kdfThreads uint8
+)
+
+var changePasswordCmd = &cobra.Command{
+	Use:   "change-password",
+	Short: "Re-encrypts an account's seed with a new password",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		req, err := readChangePasswordReq()
+		if err != nil {
+			return err
+		}
+		if err := client.ChangePassword(accountID, req); err != nil {
+			return err
+		}
+		fmt.Println("Password changed.")
+		return nil
+	},
+}
+
+var mnemonicCmd = &cobra.Command{
+	Use:   "mnemonic",
+	Short: "Shows the account's seed phrase",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		password, err := readSecret("Please enter your password: ")
+		if err != nil {
+			return err
+		}
+		mnemonic, err := client.GetMnemonic(accountID, password)
+		if err != nil {
+			return err
+		}
+		fmt.Println(mnemonic)
+		return nil
+	},
+}
+
 var lockCmd = &cobra.Command{
 	Use:   "lock",
 	Short: "Locks a wallet",
@@ -49,7 +98,44 @@ var lockCmd = &cobra.Command{
 	},
 }
 
+func readChangePasswordReq() (*api.ChangePasswordReq, error) {
+	oldPassword, err := readSecret("Please enter your current password: ")
+	if err != nil {
+		return nil, err
+	}
+	newPassword, err := readSecret("Please enter a new password: ")
+	if err != nil {
+		return nil, err
+	}
+	confirm, err := readSecret("Please confirm the new password: ")
+	if err != nil {
+		return nil, err
+	}
+	if newPassword != confirm {
+		return nil, errors.New("passwords do not match")
+	}
+
+	return &api.ChangePasswordReq{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+		KDF: &wallet.KDFParams{
+			Time:    kdfTime,
+			Memory:  kdfMemory * 1024,
+			Threads: kdfThreads,
+		},
+	}, nil
+}
+
+func addKDFFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint32Var(&kdfTime, "kdf-time", wallet.DefaultKDFParams.Time, "Number of Argon2 passes.")
+	cmd.Flags().Uint32Var(&kdfMemory, "kdf-memory", wallet.DefaultKDFParams.Memory/1024, "Argon2 memory in MiB.")
+	cmd.Flags().Uint8Var(&kdfThreads, "kdf-threads", wallet.DefaultKDFParams.Threads, "Number of Argon2 threads.")
+}
+
 func init() {
 	rootCmd.AddCommand(unlockCmd)
 	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(changePasswordCmd)
+	rootCmd.AddCommand(mnemonicCmd)
+	addKDFFlags(changePasswordCmd)
 }
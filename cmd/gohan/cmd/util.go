@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/kurumiimari/gohan"
+	"github.com/kurumiimari/gohan/chain"
 	"github.com/kurumiimari/gohan/wallet/api"
 	"github.com/pkg/errors"
+	"io"
+	"os"
 	"strconv"
 	"strings"
 )
@@ -28,6 +32,9 @@ func apiClient() (*api.Client, error) {
 		return nil, err
 	}
 
+	if walletID != "" {
+		client = client.InWallet(walletID)
+	}
 	return client, nil
 }
 
@@ -47,12 +54,39 @@ func float64Arg(in string, deflt float64) float64 {
 	return out
 }
 
+func outpointArgs(in []string) ([]*chain.Outpoint, error) {
+	var out []*chain.Outpoint
+	for _, arg := range in {
+		splits := strings.Split(arg, "/")
+		if len(splits) != 2 {
+			return nil, errors.Errorf("invalid coin %s, must be of the form hash/index", arg)
+		}
+		hash, err := hex.DecodeString(splits[0])
+		if err != nil || len(hash) != 32 {
+			return nil, errors.Errorf("invalid coin hash %s", splits[0])
+		}
+		index, err := strconv.ParseUint(splits[1], 10, 32)
+		if err != nil {
+			return nil, errors.Errorf("invalid coin index %s", splits[1])
+		}
+		out = append(out, &chain.Outpoint{
+			Hash:  hash,
+			Index: uint32(index),
+		})
+	}
+	return out, nil
+}
+
 func printJSON(in interface{}) error {
+	return writeJSON(os.Stdout, in)
+}
+
+func writeJSON(w io.Writer, in interface{}) error {
 	out, err := json.MarshalIndent(in, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(string(out))
-	return nil
+	_, err = fmt.Fprintln(w, string(out))
+	return err
 }
@@ -1,6 +1,18 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+	"syscall"
+)
+
+var (
+	walletAccountIndex int64
+	walletDiscover     bool
+)
 
 var walletsCmd = &cobra.Command{
 	Use:   "accounts",
@@ -18,6 +30,273 @@ var walletsCmd = &cobra.Command{
 	},
 }
 
+var walletCmd = &cobra.Command{
+	Use:   "wallet",
+	Short: "Manage wallets that derive several accounts from one seed",
+	Long: `Manage wallets that derive several accounts from one seed. A wallet has a
+single seed phrase and password, and each of its accounts is derived at its
+own BIP44 index. Select the wallet with --wallet-id.`,
+}
+
+var walletCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Creates a wallet with a new seed phrase",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createWallet(false)
+	},
+}
+
+var walletImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Creates a wallet from an existing seed phrase",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createWallet(true)
+	},
+}
+
+var walletListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists all wallets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.GetWallets()
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var walletInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Shows a wallet's accounts and combined balances",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireWalletID(); err != nil {
+			return err
+		}
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.GetWallet(walletID)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var walletUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Unlocks a wallet and all of its accounts",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireWalletID(); err != nil {
+			return err
+		}
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		password, err := readSecret("Please enter your password: ")
+		if err != nil {
+			return err
+		}
+		if err := client.UnlockWallet(walletID, password); err != nil {
+			return err
+		}
+		fmt.Println("Wallet unlocked.")
+		return nil
+	},
+}
+
+var walletLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Locks a wallet and all of its accounts",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireWalletID(); err != nil {
+			return err
+		}
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		if err := client.LockWallet(walletID); err != nil {
+			return err
+		}
+		fmt.Println("Wallet locked.")
+		return nil
+	},
+}
+
+var walletChangePasswordCmd = &cobra.Command{
+	Use:   "change-password",
+	Short: "Re-encrypts a wallet's seed and all of its accounts with a new password",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireWalletID(); err != nil {
+			return err
+		}
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		req, err := readChangePasswordReq()
+		if err != nil {
+			return err
+		}
+		if err := client.ChangeWalletPassword(walletID, req); err != nil {
+			return err
+		}
+		fmt.Println("Password changed.")
+		return nil
+	},
+}
+
+var walletMnemonicCmd = &cobra.Command{
+	Use:   "mnemonic",
+	Short: "Shows the wallet's seed phrase",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireWalletID(); err != nil {
+			return err
+		}
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		password, err := readSecret("Please enter your password: ")
+		if err != nil {
+			return err
+		}
+		mnemonic, err := client.GetWalletMnemonic(walletID, password)
+		if err != nil {
+			return err
+		}
+		fmt.Println(mnemonic)
+		return nil
+	},
+}
+
+var walletAddAccountCmd = &cobra.Command{
+	Use:   "add-account <account-id>",
+	Short: "Derives a new account from the wallet's seed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireWalletID(); err != nil {
+			return err
+		}
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		password, err := readSecret("Please enter your password: ")
+		if err != nil {
+			return err
+		}
+
+		req := &api.CreateWalletAccountReq{
+			ID:                   args[0],
+			Password:             password,
+			ReceiveGapLimit:      receiveGapLimit,
+			ChangeGapLimit:       changeGapLimit,
+			DutchAuctionGapLimit: dutchAuctionGapLimit,
+		}
+		if walletAccountIndex >= 0 {
+			index := uint32(walletAccountIndex)
+			req.Index = &index
+		}
+		res, err := client.CreateWalletAccount(walletID, req)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+func createWallet(importSeed bool) error {
+	if err := requireWalletID(); err != nil {
+		return err
+	}
+	client, err := apiClient()
+	if err != nil {
+		return err
+	}
+
+	password, err := readSecret("Please enter a password to encrypt your wallet: ")
+	if err != nil {
+		return err
+	}
+	req := &api.CreateWalletReq{
+		ID:                   walletID,
+		Password:             password,
+		ReceiveGapLimit:      receiveGapLimit,
+		ChangeGapLimit:       changeGapLimit,
+		DutchAuctionGapLimit: dutchAuctionGapLimit,
+	}
+	if importSeed {
+		req.Mnemonic, err = readSecret("Please paste in your mnemonic: ")
+		if err != nil {
+			return err
+		}
+		req.Discover = walletDiscover
+	}
+	req.Passphrase, err = readPassphrase(!importSeed)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Creating wallet...")
+	res, err := client.CreateWallet(req)
+	if err != nil {
+		return errors.Wrap(err, "error creating wallet")
+	}
+	fmt.Printf("Created wallet %s. Its first account is also named %s.\n", res.ID, res.ID)
+	if res.Mnemonic != nil {
+		fmt.Println("Please take note of your seed phrase below.")
+		fmt.Println("STORE YOUR SEED PHRASE SECURELY. You can show it again with `gohan wallet mnemonic`.")
+		fmt.Println("")
+		fmt.Println(*res.Mnemonic)
+	}
+	return nil
+}
+
+func requireWalletID() error {
+	if walletID == "" {
+		return errors.New("a wallet ID is required, set it with --wallet-id")
+	}
+	return nil
+}
+
+func readSecret(prompt string) (string, error) {
+	fmt.Print(prompt)
+	// need the cast below for it to compile on windows
+	secret, err := terminal.ReadPassword(int(syscall.Stdin))
+	fmt.Println("")
+	if err != nil {
+		return "", errors.Wrap(err, "error reading input")
+	}
+	return string(secret), nil
+}
+
 func init() {
 	rootCmd.AddCommand(walletsCmd)
+	rootCmd.AddCommand(walletCmd)
+	walletCmd.AddCommand(walletCreateCmd)
+	addGapLimitFlags(walletCreateCmd)
+	addPassphraseFlag(walletCreateCmd)
+	walletCmd.AddCommand(walletImportCmd)
+	addGapLimitFlags(walletImportCmd)
+	addPassphraseFlag(walletImportCmd)
+	walletImportCmd.Flags().BoolVar(&walletDiscover, "discover", false, "Keeps rescanning with a wider lookahead while new address activity turns up.")
+	walletCmd.AddCommand(walletListCmd)
+	walletCmd.AddCommand(walletInfoCmd)
+	walletCmd.AddCommand(walletUnlockCmd)
+	walletCmd.AddCommand(walletLockCmd)
+	walletCmd.AddCommand(walletChangePasswordCmd)
+	walletCmd.AddCommand(walletMnemonicCmd)
+	addKDFFlags(walletChangePasswordCmd)
+	walletCmd.AddCommand(walletAddAccountCmd)
+	walletAddAccountCmd.Flags().Int64Var(&walletAccountIndex, "index", -1, "BIP44 account index to derive. Defaults to the next unused index.")
+	addGapLimitFlags(walletAddAccountCmd)
 }
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Manage URLs notified of account events",
+}
+
+var addWebhookCmd = &cobra.Command{
+	Use:   "add [url]",
+	Short: "Registers a URL to receive signed event notifications",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.AddWebhook(accountID, args[0])
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var listWebhooksCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists registered webhooks and their delivery status",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		res, err := client.GetWebhooks(accountID)
+		if err != nil {
+			return err
+		}
+		return printJSON(res)
+	},
+}
+
+var removeWebhookCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Removes a webhook and discards its undelivered events",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return errors.New("invalid webhook ID")
+		}
+
+		client, err := apiClient()
+		if err != nil {
+			return err
+		}
+		return client.RemoveWebhook(accountID, id)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+	webhookCmd.AddCommand(addWebhookCmd)
+	webhookCmd.AddCommand(listWebhooksCmd)
+	webhookCmd.AddCommand(removeWebhookCmd)
+}
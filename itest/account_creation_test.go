@@ -1,10 +1,14 @@
 package itest
 
 import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/wallet"
 	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/kurumiimari/gohan/walletdb"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 type AccountCreationSuite struct {
@@ -85,6 +89,147 @@ func (s *AccountCreationSuite) TestUnlock() {
 	require.Error(t, client.Unlock("testwallet", "badpassword"))
 }
 
+func (s *AccountCreationSuite) TestPassphrase() {
+	t := s.T()
+	client, cleanup := startDaemon(t)
+	defer cleanup()
+
+	_, err := client.CreateAccount(&api.CreateAccountReq{
+		ID:         "testwallet",
+		Mnemonic:   Mnemonic,
+		Passphrase: "hunter2",
+		Password:   "password",
+	})
+	require.NoError(t, err)
+	_, err = client.CreateAccount(&api.CreateAccountReq{
+		ID:         "watchonly",
+		XPub:       "xpub6CMpnZHN1Zaqx2ctpHmqamD8NwEoEWpWia2pfojKZMmj5JfqKa1GNz4CZfZHr3LosxjFy98wV39XRX1BdkXxLwzyEYwyJ9eCFwyNtA5gniA",
+		Passphrase: "hunter2",
+	})
+	require.Error(t, err)
+
+	mk := chain.NewMasterExtendedKeyFromMnemonic(Mnemonic, "hunter2", chain.NetworkRegtest)
+	accountKey := chain.DeriveExtendedKey(
+		mk,
+		chain.HardenNode(chain.CoinPurpose),
+		chain.HardenNode(chain.NetworkRegtest.KeyPrefix.CoinType),
+		chain.HardenNode(0),
+	)
+	info, err := client.GetAccount("testwallet")
+	require.NoError(t, err)
+	require.Equal(t, accountKey.PublicString(), info.XPub)
+
+	_, err = client.GetMnemonic("testwallet", "badpassword")
+	require.Error(t, err)
+	mnemonic, err := client.GetMnemonic("testwallet", "password")
+	require.NoError(t, err)
+	require.Equal(t, Mnemonic, mnemonic)
+
+	require.NoError(t, client.ChangePassword("testwallet", &api.ChangePasswordReq{
+		OldPassword: "password",
+		NewPassword: "new password",
+	}))
+	_, err = client.GetMnemonic("testwallet", "password")
+	require.Error(t, err)
+	mnemonic, err = client.GetMnemonic("testwallet", "new password")
+	require.NoError(t, err)
+	require.Equal(t, Mnemonic, mnemonic)
+}
+
+func (s *AccountCreationSuite) TestBrandNewMnemonicExport() {
+	t := s.T()
+	client, cleanup := startDaemon(t)
+	defer cleanup()
+
+	res, err := client.CreateAccount(&api.CreateAccountReq{
+		ID:       "testwallet",
+		Password: "password",
+	})
+	require.NoError(t, err)
+	mnemonic, err := client.GetMnemonic("testwallet", "password")
+	require.NoError(t, err)
+	require.Equal(t, *res.Mnemonic, mnemonic)
+
+	_, err = client.CreateAccount(&api.CreateAccountReq{
+		ID:   "watchonly",
+		XPub: "xpub6CMpnZHN1Zaqx2ctpHmqamD8NwEoEWpWia2pfojKZMmj5JfqKa1GNz4CZfZHr3LosxjFy98wV39XRX1BdkXxLwzyEYwyJ9eCFwyNtA5gniA",
+	})
+	require.NoError(t, err)
+	_, err = client.GetMnemonic("watchonly", "")
+	require.Error(t, err)
+}
+
+func (s *AccountCreationSuite) TestImportDiscovery() {
+	t := s.T()
+	client, cleanup := startDaemon(t)
+	defer cleanup()
+
+	mk := chain.NewMasterExtendedKeyFromMnemonic(Mnemonic, "", chain.NetworkRegtest)
+	accountKey := chain.DeriveExtendedKey(
+		mk,
+		chain.HardenNode(chain.CoinPurpose),
+		chain.HardenNode(chain.NetworkRegtest.KeyPrefix.CoinType),
+		chain.HardenNode(0),
+	)
+	ring := wallet.NewAccountKeyring(nil, accountKey, chain.NetworkRegtest)
+	// both addresses are beyond the gap limit of the last used address
+	// before them
+	near := ring.Address(chain.ReceiveBranch, 8).String()
+	far := ring.Address(chain.ReceiveBranch, 16).String()
+	mineTo(t, s.hsd.Client, client, 1, near)
+	mineTo(t, s.hsd.Client, client, 1, far)
+	status, err := client.Status()
+	require.NoError(t, err)
+
+	_, err = client.CreateAccount(&api.CreateAccountReq{
+		ID:              "testwallet",
+		Mnemonic:        Mnemonic,
+		Password:        "password",
+		ReceiveGapLimit: 5,
+	})
+	require.NoError(t, err)
+	awaitHeight(t, client, "testwallet", status.Height)
+
+	info, err := client.GetAccount("testwallet")
+	require.NoError(t, err)
+	require.Equal(t, &api.AccountGapLimit{
+		Receive:      5,
+		Change:       wallet.AddrLookahead,
+		DutchAuction: wallet.DutchAuctionLookahead,
+	}, info.GapLimit)
+	nearInfo, err := client.GetAddress("testwallet", near)
+	require.NoError(t, err)
+	require.False(t, nearInfo.Received)
+
+	require.NoError(t, client.DiscoveryRescan("testwallet", 0))
+	var farInfo *walletdb.AddressInfo
+	for i := 0; i < 50; i++ {
+		farInfo, err = client.GetAddress("testwallet", far)
+		require.NoError(t, err)
+		if farInfo.Received {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	require.True(t, farInfo.Received)
+	nearInfo, err = client.GetAddress("testwallet", near)
+	require.NoError(t, err)
+	require.True(t, nearInfo.Received)
+
+	_, err = client.CreateAccount(&api.CreateAccountReq{
+		ID:       "brandnew",
+		Password: "password",
+		Discover: true,
+	})
+	require.Error(t, err)
+	_, err = client.CreateAccount(&api.CreateAccountReq{
+		ID:              "brandnew",
+		Password:        "password",
+		ReceiveGapLimit: wallet.MaxGapLimit + 1,
+	})
+	require.Error(t, err)
+}
+
 func TestWalletSuite(t *testing.T) {
 	suite.Run(t, new(AccountCreationSuite))
 }
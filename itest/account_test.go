@@ -1,6 +1,7 @@
 package itest
 
 import (
+	"github.com/kurumiimari/gohan/wallet"
 	"github.com/kurumiimari/gohan/wallet/api"
 	"github.com/kurumiimari/gohan/walletdb"
 	"github.com/stretchr/testify/require"
@@ -105,6 +106,27 @@ func (s *AccountSuite) TestGenerateChangeAddress() {
 	require.Equal(t, info.ChangeAddress, "rs1qn8s8ua95pve9f86pu8e9ksf2elxrv4cdvp0qdq")
 }
 
+func (s *AccountSuite) TestChangePassword() {
+	t := s.T()
+
+	require.Error(t, s.client.ChangePassword("alice", &api.ChangePasswordReq{
+		OldPassword: "wrong",
+		NewPassword: "new password",
+	}))
+	require.Error(t, s.client.ChangePassword("alice", &api.ChangePasswordReq{
+		OldPassword: "password",
+		NewPassword: "new password",
+		KDF:         &wallet.KDFParams{Time: 1, Memory: 1, Threads: 1},
+	}))
+	require.NoError(t, s.client.ChangePassword("alice", &api.ChangePasswordReq{
+		OldPassword: "password",
+		NewPassword: "new password",
+		KDF:         &wallet.KDFParams{Time: 2, Memory: 32 * 1024, Threads: 2},
+	}))
+	require.Error(t, s.client.Unlock("alice", "password"))
+	require.NoError(t, s.client.Unlock("alice", "new password"))
+}
+
 func TestAccountSuite(t *testing.T) {
 	suite.Run(t, new(AccountSuite))
 }
@@ -1,11 +1,15 @@
 package itest
 
 import (
+	"fmt"
 	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/wallet"
 	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/kurumiimari/gohan/walletdb"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 type AccountAuctionSuite struct {
@@ -116,6 +120,39 @@ func (s *AccountAuctionSuite) TestOpenNameOK() {
 	require.Equal(t, "OPENING", info.Info.State)
 }
 
+func (s *AccountAuctionSuite) TestAbandonOpen() {
+	name := "awilauh"
+	t := s.T()
+	before, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+
+	tx, err := s.client.Open("alice", name, 100, false)
+	require.NoError(t, err)
+	names, err := s.client.GetNames("alice")
+	require.NoError(t, err)
+	require.Len(t, names.Names, 1)
+
+	require.NoError(t, s.client.AbandonTx("alice", tx.IDHex()))
+	names, err = s.client.GetNames("alice")
+	require.NoError(t, err)
+	require.Len(t, names.Names, 0)
+	after, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	require.Equal(t, before.Balances.Available, after.Balances.Available)
+
+	err = s.client.AbandonTx("alice", tx.IDHex())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "transaction not found")
+
+	// the transaction is still in the mempool, so it's picked back up
+	// once it confirms
+	mineTo(t, s.hsd.Client, s.client, 1, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 5)
+	err = s.client.AbandonTx("alice", tx.IDHex())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already confirmed")
+}
+
 func (s *AccountAuctionSuite) TestBidNameBlacklisted() {
 	t := s.T()
 	_, err := s.client.Bid("alice", "localhost", 100, 1000000, 2000000, false)
@@ -319,6 +356,281 @@ func (s *AccountAuctionSuite) TestRegisterWithRecordsOK() {
 	require.EqualValues(t, nameInfo.Info.Owner.Index, tx.Inputs[0].Prevout.Index)
 }
 
+func (s *AccountAuctionSuite) TestBatchOK() {
+	t := s.T()
+	names := []string{"batch26", "batch48", "batch63"}
+
+	var opens []*wallet.BatchAction
+	for _, name := range names {
+		opens = append(opens, &wallet.BatchAction{
+			Type: wallet.BatchActionOpen,
+			Name: name,
+		})
+	}
+	tx, err := s.client.Batch("alice", opens, 100, false)
+	require.NoError(t, err)
+	require.Equal(t, len(names), chain.CountOpens(tx.Outputs))
+
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.TreeInterval+2, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 11)
+
+	for _, name := range names {
+		info, err := s.hsd.Client.GetNameInfo(name)
+		require.NoError(t, err)
+		require.Equal(t, "BIDDING", info.Info.State)
+	}
+
+	var bids []*wallet.BatchAction
+	for _, name := range names {
+		bids = append(bids, &wallet.BatchAction{
+			Type:   wallet.BatchActionBid,
+			Name:   name,
+			Value:  1000000,
+			Lockup: 2000000,
+		})
+	}
+	bids = append(bids, &wallet.BatchAction{
+		Type:   wallet.BatchActionBid,
+		Name:   names[0],
+		Value:  2000000,
+		Lockup: 4000000,
+	})
+	_, err = s.client.Batch("alice", bids, 100, false)
+	require.NoError(t, err)
+
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.BiddingPeriod, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 16)
+
+	var reveals []*wallet.BatchAction
+	for _, name := range names {
+		reveals = append(reveals, &wallet.BatchAction{
+			Type: wallet.BatchActionReveal,
+			Name: name,
+		})
+	}
+	tx, err = s.client.Batch("alice", reveals, 100, false)
+	require.NoError(t, err)
+	var revealed int
+	for _, out := range tx.Outputs {
+		if out.Covenant.Type == chain.CovenantReveal {
+			revealed++
+		}
+	}
+	require.Equal(t, len(bids), revealed)
+}
+
+func (s *AccountAuctionSuite) TestBatchMixedOK() {
+	t := s.T()
+	redeemName := "awilauh"
+	revealName := "batch48"
+	openName := "batch63"
+
+	_, err := s.client.Open("alice", redeemName, 100, false)
+	require.NoError(t, err)
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.TreeInterval+2, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 11)
+
+	_, err = s.client.Bid("alice", redeemName, 100, 1000000, 2000000, false)
+	require.NoError(t, err)
+	_, err = s.client.Bid("alice", redeemName, 100, 2000000, 4000000, false)
+	require.NoError(t, err)
+	_, err = s.client.Open("alice", revealName, 100, false)
+	require.NoError(t, err)
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.BiddingPeriod, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 16)
+
+	_, err = s.client.Reveal("alice", redeemName, 100, false)
+	require.NoError(t, err)
+	mineTo(t, s.hsd.Client, s.client, 2, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 18)
+	info, err := s.hsd.Client.GetNameInfo(revealName)
+	require.NoError(t, err)
+	require.Equal(t, "BIDDING", info.Info.State)
+	_, err = s.client.Bid("alice", revealName, 100, 1000000, 2000000, false)
+	require.NoError(t, err)
+
+	mineTo(t, s.hsd.Client, s.client, 8, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 26)
+	info, err = s.hsd.Client.GetNameInfo(redeemName)
+	require.NoError(t, err)
+	require.Equal(t, "CLOSED", info.Info.State)
+	info, err = s.hsd.Client.GetNameInfo(revealName)
+	require.NoError(t, err)
+	require.Equal(t, "REVEAL", info.Info.State)
+
+	// the open comes first to check that linked covenants are still placed
+	// at their inputs' indices
+	tx, err := s.client.Batch("alice", []*wallet.BatchAction{
+		{
+			Type: wallet.BatchActionOpen,
+			Name: openName,
+		},
+		{
+			Type: wallet.BatchActionReveal,
+			Name: revealName,
+		},
+		{
+			Type: wallet.BatchActionRedeem,
+			Name: redeemName,
+		},
+	}, 100, false)
+	require.NoError(t, err)
+	require.Equal(t, chain.CovenantReveal, tx.Outputs[0].Covenant.Type)
+	require.Equal(t, chain.CovenantRedeem, tx.Outputs[1].Covenant.Type)
+	require.Equal(t, chain.CovenantOpen, tx.Outputs[2].Covenant.Type)
+
+	mineTo(t, s.hsd.Client, s.client, 1, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 27)
+	pending, err := s.hsd.Client.GetRawMempool()
+	require.NoError(t, err)
+	require.NotContains(t, pending, tx.IDHex())
+	info, err = s.hsd.Client.GetNameInfo(openName)
+	require.NoError(t, err)
+	require.NotNil(t, info.Info)
+
+	history, err := s.client.GetName("alice", revealName)
+	require.NoError(t, err)
+	reveal := history.History[0]
+	require.Equal(t, walletdb.NameActionReveal, reveal.Type)
+	require.Equal(t, tx.IDHex(), reveal.Transaction.Hash.String())
+	require.Equal(t, 0, reveal.OutIdx)
+	require.Equal(t, walletdb.NameActionBid, history.History[1].Type)
+	require.Equal(t, history.History[1].Transaction.Hash.String(), *reveal.ParentTxHash)
+}
+
+func (s *AccountAuctionSuite) TestBatchDuplicateName() {
+	t := s.T()
+	_, err := s.client.Batch("alice", []*wallet.BatchAction{
+		{
+			Type: wallet.BatchActionOpen,
+			Name: "batch26",
+		},
+		{
+			Type: wallet.BatchActionOpen,
+			Name: "batch26",
+		},
+	}, 100, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "appears more than once")
+}
+
+func (s *AccountAuctionSuite) TestBatchTooManyOpens() {
+	t := s.T()
+	var opens []*wallet.BatchAction
+	for i := 0; i <= chain.MaxTxOpens; i++ {
+		opens = append(opens, &wallet.BatchAction{
+			Type: wallet.BatchActionOpen,
+			Name: fmt.Sprintf("gohanbatch%d", i),
+		})
+	}
+	_, err := s.client.Batch("alice", opens, 100, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "limit of 150 opens per transaction")
+}
+
+func (s *AccountAuctionSuite) TestBatchInvalidAction() {
+	t := s.T()
+	_, err := s.client.Batch("alice", []*wallet.BatchAction{
+		{
+			Type: wallet.BatchActionOpen,
+			Name: "batch26",
+		},
+		{
+			Type: wallet.BatchActionOpen,
+			Name: "-notvalid-",
+		},
+	}, 100, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid name")
+
+	info, err := s.hsd.Client.GetNameInfo("batch26")
+	require.NoError(t, err)
+	require.Nil(t, info.Info)
+}
+
+func (s *AccountAuctionSuite) TestAutoReveal() {
+	name := "awilauh"
+	t := s.T()
+	require.NoError(t, s.client.SetAutoReveal("alice", true))
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	require.True(t, info.AutoReveal)
+
+	_, err = s.client.Open("alice", name, 100, false)
+	require.NoError(t, err)
+
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.TreeInterval+2, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 11)
+
+	_, err = s.client.Bid("alice", name, 100, 1000000, 2000000, false)
+	require.NoError(t, err)
+	_, err = s.client.Bid("alice", name, 100, 2000000, 4000000, false)
+	require.NoError(t, err)
+
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.BiddingPeriod, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 16)
+
+	// the revealer runs in the background once the account has indexed
+	// the reveal period's first block
+	var reveals *api.UnspentRevealsRes
+	for i := 0; i < 30; i++ {
+		require.NoError(t, s.client.PollBlock())
+		reveals, err = s.client.UnspentReveals("alice", 10, 0)
+		require.NoError(t, err)
+		if len(reveals.UnspentReveals) == 2 {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	require.Len(t, reveals.UnspentReveals, 2)
+}
+
+func (s *AccountAuctionSuite) TestAutoRevealDisabled() {
+	t := s.T()
+	require.NoError(t, s.client.SetAutoReveal("alice", true))
+	require.NoError(t, s.client.SetAutoReveal("alice", false))
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	require.False(t, info.AutoReveal)
+}
+
+func (s *AccountAuctionSuite) TestAutoSweep() {
+	t := s.T()
+	name := "awilauh"
+	resource := &chain.Resource{
+		Records: []chain.Record{
+			&chain.TXTRecord{
+				Entries: []string{"swept"},
+			},
+		},
+	}
+	require.NoError(t, s.client.SetAutoSweep("alice", true, resource))
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	require.True(t, info.AutoSweep)
+	require.NotNil(t, info.AutoRegister)
+
+	s.doBids()
+
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.RevealPeriod, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 16+chain.NetworkRegtest.RevealPeriod)
+
+	automated := make(map[walletdb.NameHistoryType]bool)
+	for i := 0; i < 30 && len(automated) < 2; i++ {
+		require.NoError(t, s.client.PollBlock())
+		res, err := s.client.GetName("alice", name)
+		require.NoError(t, err)
+		for _, entry := range res.History {
+			if entry.Automated {
+				automated[entry.Type] = true
+			}
+		}
+		time.Sleep(time.Second)
+	}
+	require.True(t, automated[walletdb.NameActionRedeem])
+	require.True(t, automated[walletdb.NameActionRegister])
+}
+
 func TestAccountAuction(t *testing.T) {
 	suite.Run(t, new(AccountAuctionSuite))
 }
@@ -0,0 +1,128 @@
+package itest
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/wallet"
+	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type BumpFeeSuite struct {
+	suite.Suite
+	hsd     *HSD
+	client  *api.Client
+	cleanup func()
+}
+
+func (s *BumpFeeSuite) SetupTest() {
+	t := s.T()
+	s.hsd = startHSD()
+	s.client, s.cleanup = startDaemon(t)
+
+	_, err := s.client.CreateAccount(&api.CreateAccountReq{
+		ID:       "alice",
+		Password: "password",
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.client.Unlock("alice", "password"))
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	mineTo(t, s.hsd.Client, s.client, 1, info.ReceiveAddress)
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.CoinbaseMaturity, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 1+chain.NetworkRegtest.CoinbaseMaturity)
+}
+
+func (s *BumpFeeSuite) TearDownTest() {
+	s.cleanup()
+	s.hsd.Stop()
+}
+
+func (s *BumpFeeSuite) TestReplaceByFee() {
+	t := s.T()
+
+	orig, err := s.client.Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.NoError(t, err)
+	require.EqualValues(t, chain.ReplaceableSequence, orig.Inputs[0].Sequence)
+
+	_, err = s.client.BumpFee("alice", orig.IDHex(), wallet.BumpMethodRBF, 100)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "fee rate must exceed")
+
+	replacement, err := s.client.BumpFee("alice", orig.IDHex(), wallet.BumpMethodRBF, 200)
+	require.NoError(t, err)
+	require.NotEqual(t, orig.IDHex(), replacement.IDHex())
+	require.True(t, orig.Inputs[0].Prevout.Equal(replacement.Inputs[0].Prevout))
+	require.Equal(t, orig.Outputs[0], replacement.Outputs[0])
+	require.Less(t, replacement.Outputs[1].Value, orig.Outputs[1].Value)
+
+	txs, err := s.client.GetAccountTransactions("alice", 50, 0)
+	require.NoError(t, err)
+	var hashes []string
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash.String())
+	}
+	require.Contains(t, hashes, replacement.IDHex())
+	require.NotContains(t, hashes, orig.IDHex())
+
+	mineTo(t, s.hsd.Client, s.client, 1, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 2+chain.NetworkRegtest.CoinbaseMaturity)
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	require.EqualValues(t, replacement.Outputs[1].Value, info.Balances.Available)
+}
+
+func (s *BumpFeeSuite) TestReplaceByFeeOptOut() {
+	t := s.T()
+
+	client := s.client.WithCoinControl(api.CoinControl{NoRBF: true})
+	orig, err := client.Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.NoError(t, err)
+	require.EqualValues(t, chain.DefaultSequence, orig.Inputs[0].Sequence)
+
+	_, err = s.client.BumpFee("alice", orig.IDHex(), wallet.BumpMethodRBF, 200)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not signal replaceability")
+
+	_, err = s.client.BumpFee("alice", orig.IDHex(), wallet.BumpMethodCPFP, 200)
+	require.NoError(t, err)
+}
+
+func (s *BumpFeeSuite) TestChildPaysForParent() {
+	t := s.T()
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+
+	parent, err := s.client.Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.NoError(t, err)
+
+	child, err := s.client.BumpFee("alice", parent.IDHex(), wallet.BumpMethodCPFP, 300)
+	require.NoError(t, err)
+	require.Len(t, child.Inputs, 1)
+	require.Equal(t, parent.ID(), child.Inputs[0].Prevout.Hash)
+	require.EqualValues(t, 1, child.Inputs[0].Prevout.Index)
+
+	parentFee := info.Balances.Available - parent.Outputs[0].Value - parent.Outputs[1].Value
+	childFee := parent.Outputs[1].Value - child.Outputs[0].Value
+	packageSize := uint64(parent.VirtualSize() + child.VirtualSize())
+	require.GreaterOrEqual(t, parentFee+childFee, 300*packageSize)
+
+	_, err = s.client.BumpFee("alice", parent.IDHex(), wallet.BumpMethodRBF, 400)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "descendants")
+
+	mineTo(t, s.hsd.Client, s.client, 1, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 2+chain.NetworkRegtest.CoinbaseMaturity)
+
+	_, err = s.client.BumpFee("alice", child.IDHex(), wallet.BumpMethodCPFP, 400)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already confirmed")
+}
+
+func TestBumpFee(t *testing.T) {
+	suite.Run(t, new(BumpFeeSuite))
+}
@@ -236,6 +236,82 @@ func (s *DutchAuctionSuite) TestFinalizeDutchAuctionFill() {
 	require.Equal(t, walletdb.NameStatusOwned, names.Names[0].Status)
 }
 
+func (s *DutchAuctionSuite) TestFinalizeWatchOnlyDutchAuctionFill() {
+	t := s.T()
+
+	_, err := s.client.CreateAccount(&api.CreateAccountReq{
+		ID:   "carol",
+		XPub: accountXPub(CosignerMnemonic),
+	})
+	require.NoError(t, err)
+	_, err = s.client.CreateAccount(&api.CreateAccountReq{
+		ID:       "carolkeys",
+		Mnemonic: CosignerMnemonic,
+		Password: "password",
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.client.Unlock("carolkeys", "password"))
+	carolInfo, err := s.client.GetAccount("carol")
+	require.NoError(t, err)
+
+	// confirmed by the block mined in transferListing
+	_, err = s.client.Send("bob", 10000000, 100, carolInfo.ReceiveAddress, false)
+	require.NoError(t, err)
+
+	s.transferListing()
+	s.finalizeListing()
+	s.generate()
+
+	fill, err := s.client.TransferDutchAuctionFill("carol", &api.TransferDutchAuctionFillReq{
+		Name:             s.name,
+		LockScriptTxHash: s.auction.LockingOutpoint.Hash,
+		LockScriptOutIdx: s.auction.LockingOutpoint.Index,
+		PaymentAddress:   s.auction.PaymentAddress,
+		FeeAddress:       s.auction.FeeAddress,
+		PublicKey:        s.auction.PublicKey,
+		Signature:        s.auction.Bids[0].Signature,
+		LockTime:         s.auction.Bids[0].LockTime,
+		Bid:              s.auction.Bids[0].Value,
+		AuctionFee:       s.auction.Bids[0].Fee,
+		FeeRate:          100,
+	})
+	require.NoError(t, err)
+	s.signWatchOnly(fill)
+
+	height := 19 + chain.NetworkRegtest.RevealPeriod + chain.NetworkRegtest.TransferLockup*2
+	mineTo(t, s.hsd.Client, s.client, 1+chain.NetworkRegtest.TransferLockup, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "carol", height)
+
+	finalize, err := s.client.FinalizeDutchAuctionFill("carol", s.name, 100)
+	require.NoError(t, err)
+	s.signWatchOnly(finalize)
+
+	mineTo(t, s.hsd.Client, s.client, 1, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "carol", height+1)
+
+	hist, err := s.client.GetName("carol", s.name)
+	require.NoError(t, err)
+	require.Equal(t, walletdb.NameActionFinalizeFillDutchAuction, hist.History[0].Type)
+	names, err := s.client.GetNames("carol")
+	require.NoError(t, err)
+	require.Equal(t, walletdb.NameStatusOwned, names.Names[0].Status)
+}
+
+func (s *DutchAuctionSuite) signWatchOnly(tx *chain.Transaction) {
+	t := s.T()
+
+	res, err := s.client.PartialTx("carol", tx.IDHex())
+	require.NoError(t, err)
+	require.False(t, res.Complete)
+
+	signed, err := s.client.SignPartialTx("carolkeys", res.PartialTx)
+	require.NoError(t, err)
+	require.True(t, signed.Complete)
+
+	_, err = s.client.FinalizePartialTx("carol", signed.PartialTx)
+	require.NoError(t, err)
+}
+
 func (s *DutchAuctionSuite) TestTransferCancelDutchAuction() {
 	t := s.T()
 
@@ -0,0 +1,178 @@
+package itest
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type HDWalletSuite struct {
+	suite.Suite
+	hsd     *HSD
+	client  *api.Client
+	cleanup func()
+}
+
+func (s *HDWalletSuite) SetupTest() {
+	t := s.T()
+	s.hsd = startHSD()
+	s.client, s.cleanup = startDaemon(t)
+
+	res, err := s.client.CreateWallet(&api.CreateWalletReq{
+		ID:       "main",
+		Mnemonic: Mnemonic,
+		Password: "password",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &api.CreateWalletRes{ID: "main"}, res)
+}
+
+func (s *HDWalletSuite) TearDownTest() {
+	s.cleanup()
+	s.hsd.Stop()
+}
+
+func (s *HDWalletSuite) TestAccounts() {
+	t := s.T()
+
+	_, err := s.client.CreateWalletAccount("main", &api.CreateWalletAccountReq{
+		ID:       "auctions",
+		Password: "wrong",
+	})
+	require.Error(t, err)
+	acc, err := s.client.CreateWalletAccount("main", &api.CreateWalletAccountReq{
+		ID:       "auctions",
+		Password: "password",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &api.WalletAccount{ID: "auctions", Index: 1, Locked: true}, acc)
+
+	index := uint32(1)
+	_, err = s.client.CreateWalletAccount("main", &api.CreateWalletAccountReq{
+		ID:       "duplicate",
+		Password: "password",
+		Index:    &index,
+	})
+	require.Error(t, err)
+
+	wallets, err := s.client.GetWallets()
+	require.NoError(t, err)
+	require.Equal(t, []string{"main"}, wallets.Wallets)
+	accounts, err := s.client.GetWalletAccounts("main")
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, "main", accounts[0].ID)
+	require.Equal(t, "auctions", accounts[1].ID)
+
+	scoped := s.client.InWallet("main")
+	mainInfo, err := scoped.GetAccount("main")
+	require.NoError(t, err)
+	auctionsInfo, err := scoped.GetAccount("auctions")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, auctionsInfo.Index)
+	require.NotEqual(t, mainInfo.ReceiveAddress, auctionsInfo.ReceiveAddress)
+	_, err = s.client.InWallet("other").GetAccount("auctions")
+	require.Error(t, err)
+
+	require.Error(t, s.client.UnlockWallet("main", "wrong"))
+	require.NoError(t, s.client.UnlockWallet("main", "password"))
+	info, err := s.client.GetWallet("main")
+	require.NoError(t, err)
+	require.False(t, info.Locked)
+	for _, acc := range info.Accounts {
+		require.False(t, acc.Locked)
+	}
+	require.NoError(t, s.client.LockWallet("main"))
+	info, err = s.client.GetWallet("main")
+	require.NoError(t, err)
+	require.True(t, info.Locked)
+	for _, acc := range info.Accounts {
+		require.True(t, acc.Locked)
+	}
+}
+
+func (s *HDWalletSuite) TestBalances() {
+	t := s.T()
+
+	_, err := s.client.CreateWalletAccount("main", &api.CreateWalletAccountReq{
+		ID:       "auctions",
+		Password: "password",
+	})
+	require.NoError(t, err)
+	mainInfo, err := s.client.GetAccount("main")
+	require.NoError(t, err)
+	auctionsInfo, err := s.client.GetAccount("auctions")
+	require.NoError(t, err)
+
+	mineTo(t, s.hsd.Client, s.client, 1, mainInfo.ReceiveAddress)
+	mineTo(t, s.hsd.Client, s.client, 1, auctionsInfo.ReceiveAddress)
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.CoinbaseMaturity, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "auctions", 2+chain.NetworkRegtest.CoinbaseMaturity)
+
+	mainBals, err := s.client.GetAccount("main")
+	require.NoError(t, err)
+	auctionsBals, err := s.client.GetAccount("auctions")
+	require.NoError(t, err)
+	info, err := s.client.GetWallet("main")
+	require.NoError(t, err)
+	require.Equal(t, mainBals.Balances.Available+auctionsBals.Balances.Available, info.Balances.Available)
+	require.NotZero(t, info.Balances.Available)
+}
+
+func (s *HDWalletSuite) TestChangePassword() {
+	t := s.T()
+
+	_, err := s.client.CreateWalletAccount("main", &api.CreateWalletAccountReq{
+		ID:       "auctions",
+		Password: "password",
+	})
+	require.NoError(t, err)
+
+	req := &api.ChangePasswordReq{
+		OldPassword: "password",
+		NewPassword: "new password",
+	}
+	require.Error(t, s.client.ChangePassword("auctions", req))
+	require.NoError(t, s.client.ChangeWalletPassword("main", req))
+	require.Error(t, s.client.ChangeWalletPassword("main", req))
+
+	require.Error(t, s.client.UnlockWallet("main", "password"))
+	require.Error(t, s.client.Unlock("auctions", "password"))
+	require.NoError(t, s.client.Unlock("auctions", "new password"))
+	require.NoError(t, s.client.UnlockWallet("main", "new password"))
+
+	_, err = s.client.CreateWalletAccount("main", &api.CreateWalletAccountReq{
+		ID:       "savings",
+		Password: "new password",
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.client.Unlock("savings", "new password"))
+}
+
+func (s *HDWalletSuite) TestMnemonic() {
+	t := s.T()
+
+	_, err := s.client.GetWalletMnemonic("main", "wrong")
+	require.Error(t, err)
+	mnemonic, err := s.client.GetWalletMnemonic("main", "password")
+	require.NoError(t, err)
+	require.Equal(t, Mnemonic, mnemonic)
+	_, err = s.client.GetMnemonic("main", "password")
+	require.Error(t, err)
+
+	res, err := s.client.CreateWallet(&api.CreateWalletReq{
+		ID:         "secret",
+		Password:   "password",
+		Passphrase: "hunter2",
+	})
+	require.NoError(t, err)
+	mnemonic, err = s.client.GetWalletMnemonic("secret", "password")
+	require.NoError(t, err)
+	require.Equal(t, *res.Mnemonic, mnemonic)
+}
+
+func TestHDWalletSuite(t *testing.T) {
+	suite.Run(t, new(HDWalletSuite))
+}
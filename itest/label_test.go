@@ -0,0 +1,102 @@
+package itest
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type LabelSuite struct {
+	suite.Suite
+	hsd     *HSD
+	client  *api.Client
+	cleanup func()
+}
+
+func (s *LabelSuite) SetupTest() {
+	t := s.T()
+	s.hsd = startHSD()
+	s.client, s.cleanup = startDaemon(t)
+
+	_, err := s.client.CreateAccount(&api.CreateAccountReq{
+		ID:       "alice",
+		Password: "password",
+	})
+	require.NoError(t, err)
+}
+
+func (s *LabelSuite) TearDownTest() {
+	s.cleanup()
+	s.hsd.Stop()
+}
+
+func (s *LabelSuite) TestLabels() {
+	t := s.T()
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	require.NoError(t, s.client.Unlock("alice", "password"))
+
+	_, err = s.client.SetLabel("alice", walletdb.LabelTypeAddress, info.ReceiveAddress, "invoice #123")
+	require.NoError(t, err)
+	_, err = s.client.SetLabel("alice", walletdb.LabelTypeAddress, ZeroRegtestAddr, "someone else")
+	require.Error(t, err)
+	_, err = s.client.AddContact("alice", "zero", ZeroRegtestAddr)
+	require.NoError(t, err)
+	_, err = s.client.AddContact("alice", "zero again", ZeroRegtestAddr)
+	require.Error(t, err)
+
+	mineTo(t, s.hsd.Client, s.client, 1, info.ReceiveAddress)
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.CoinbaseMaturity, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 3)
+
+	coins, err := s.client.Coins("alice")
+	require.NoError(t, err)
+	require.Len(t, coins.Coins, 1)
+	coin := coins.Coins[0]
+	require.Equal(t, "invoice #123", coin.Label)
+
+	_, err = s.client.SetLabel("alice", walletdb.LabelTypeCoin, coin.Prevout.String(), "mining reward")
+	require.NoError(t, err)
+	coins, err = s.client.Coins("alice")
+	require.NoError(t, err)
+	require.Equal(t, "mining reward", coins.Coins[0].Label)
+
+	tx, err := s.client.Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.NoError(t, err)
+	_, err = s.client.SetLabel("alice", walletdb.LabelTypeTransaction, tx.IDHex(), "rent")
+	require.NoError(t, err)
+
+	txs, _, err := s.client.SearchTransactions("alice", &walletdb.TransactionFilter{
+		Status: walletdb.TxStatusPending,
+		Count:  1,
+	})
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, tx.IDHex(), txs[0].Hash.String())
+	require.Equal(t, "rent", txs[0].Label)
+	require.Equal(t, "invoice #123", txs[0].Inputs[0].Coin.Address.Label)
+	var sawContact bool
+	for _, out := range txs[0].Outputs {
+		sawContact = sawContact || out.Address.Label == "zero"
+	}
+	require.True(t, sawContact)
+
+	labels, err := s.client.GetLabels("alice", "")
+	require.NoError(t, err)
+	require.Len(t, labels, 3)
+
+	require.NoError(t, s.client.RemoveLabel("alice", walletdb.LabelTypeTransaction, tx.IDHex()))
+	require.Error(t, s.client.RemoveLabel("alice", walletdb.LabelTypeTransaction, tx.IDHex()))
+	require.NoError(t, s.client.RemoveContact("alice", "zero"))
+	contacts, err := s.client.GetContacts("alice")
+	require.NoError(t, err)
+	require.Empty(t, contacts)
+}
+
+func TestLabelSuite(t *testing.T) {
+	suite.Run(t, new(LabelSuite))
+}
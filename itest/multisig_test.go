@@ -0,0 +1,241 @@
+package itest
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/wallet"
+	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+const CosignerMnemonic = "few derive language prison worth heavy prosper seven bone discover journey lonely sketch success marine robust crew egg fork misery certain drill seminar warrior"
+
+// OfflineCosignerMnemonic backs the third key of the 2-of-3 accounts. It is
+// never imported, so every transaction is signed by ms1 and ms2 alone.
+const OfflineCosignerMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+type MultisigSuite struct {
+	suite.Suite
+	hsd     *HSD
+	client  *api.Client
+	cleanup func()
+	name    string
+	height  int
+	msInfo  *api.AccountGetRes
+	bobInfo *api.AccountGetRes
+}
+
+func (s *MultisigSuite) SetupTest() {
+	t := s.T()
+	s.name = "msigname"
+	s.height = 0
+	s.hsd = startHSD()
+	s.client, s.cleanup = startDaemon(t)
+
+	xPub1 := accountXPub(Mnemonic)
+	xPub2 := accountXPub(CosignerMnemonic)
+	xPub3 := accountXPub(OfflineCosignerMnemonic)
+
+	_, err := s.client.CreateAccount(&api.CreateAccountReq{
+		ID:        "ms1",
+		Mnemonic:  Mnemonic,
+		Password:  "password",
+		Threshold: 2,
+		Cosigners: []string{xPub2, xPub3},
+	})
+	require.NoError(t, err)
+	_, err = s.client.CreateAccount(&api.CreateAccountReq{
+		ID:        "ms2",
+		Mnemonic:  CosignerMnemonic,
+		Password:  "password",
+		Threshold: 2,
+		Cosigners: []string{xPub1, xPub3},
+	})
+	require.NoError(t, err)
+	_, err = s.client.CreateAccount(&api.CreateAccountReq{
+		ID:       "bob",
+		Password: "password",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.client.Unlock("ms1", "password"))
+	require.NoError(t, s.client.Unlock("ms2", "password"))
+
+	s.msInfo, err = s.client.GetAccount("ms1")
+	require.NoError(t, err)
+	ms2Info, err := s.client.GetAccount("ms2")
+	require.NoError(t, err)
+	s.bobInfo, err = s.client.GetAccount("bob")
+	require.NoError(t, err)
+
+	require.Equal(t, s.msInfo.ReceiveAddress, ms2Info.ReceiveAddress)
+	require.Equal(t, 2, s.msInfo.Multisig.Threshold)
+	require.Equal(t, []string{xPub2, xPub3}, s.msInfo.Multisig.Cosigners)
+
+	s.mine(1, s.msInfo.ReceiveAddress)
+	s.mine(chain.NetworkRegtest.CoinbaseMaturity, ZeroRegtestAddr)
+}
+
+func (s *MultisigSuite) TearDownTest() {
+	s.cleanup()
+	s.hsd.Stop()
+}
+
+func (s *MultisigSuite) TestNameLifecycle() {
+	t := s.T()
+
+	tx, err := s.client.Open("ms1", s.name, 100, false)
+	require.NoError(t, err)
+	s.cosign(tx)
+	s.mine(chain.NetworkRegtest.TreeInterval+2, ZeroRegtestAddr)
+
+	tx, err = s.client.Bid("ms1", s.name, 100, 1000000, 2000000, false)
+	require.NoError(t, err)
+	s.cosign(tx)
+	s.mine(chain.NetworkRegtest.BiddingPeriod, ZeroRegtestAddr)
+
+	tx, err = s.client.Reveal("ms1", s.name, 100, false)
+	require.NoError(t, err)
+	s.cosign(tx)
+	s.mine(chain.NetworkRegtest.RevealPeriod, ZeroRegtestAddr)
+
+	tx, err = s.client.Update("ms1", s.name, nil, 100, false)
+	require.NoError(t, err)
+	s.cosign(tx)
+	s.mine(1, ZeroRegtestAddr)
+
+	for _, id := range []string{"ms1", "ms2"} {
+		names, err := s.client.GetNames(id)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(names.Names))
+		require.Equal(t, walletdb.NameStatusOwned, names.Names[0].Status)
+	}
+
+	s.mine(chain.NetworkRegtest.TreeInterval, ZeroRegtestAddr)
+
+	tx, err = s.client.Renew("ms1", s.name, 100, false)
+	require.NoError(t, err)
+	s.cosign(tx)
+	s.mine(1, ZeroRegtestAddr)
+
+	for _, id := range []string{"ms1", "ms2"} {
+		res, err := s.client.GetName(id, s.name)
+		require.NoError(t, err)
+		require.Equal(t, walletdb.NameActionRenew, res.History[0].Type)
+		require.Equal(t, tx.IDHex(), res.History[0].Transaction.Hash.String())
+	}
+
+	tx, err = s.client.Transfer("ms1", s.name, s.bobInfo.ReceiveAddress, 100, false)
+	require.NoError(t, err)
+	s.cosign(tx)
+	s.mine(chain.NetworkRegtest.TransferLockup+1, ZeroRegtestAddr)
+
+	tx, err = s.client.Finalize("ms1", s.name, 100, false)
+	require.NoError(t, err)
+	s.cosign(tx)
+	s.mine(1, ZeroRegtestAddr)
+
+	msNames, err := s.client.GetNames("ms2")
+	require.NoError(t, err)
+	bobNames, err := s.client.GetNames("bob")
+	require.NoError(t, err)
+	require.Equal(t, walletdb.NameStatusTransferred, msNames.Names[0].Status)
+	require.Equal(t, 1, len(bobNames.Names))
+	require.Equal(t, walletdb.NameStatusOwned, bobNames.Names[0].Status)
+}
+
+func (s *MultisigSuite) TestMissingSignature() {
+	t := s.T()
+
+	tx, err := s.client.Open("ms1", s.name, 100, false)
+	require.NoError(t, err)
+
+	res, err := s.client.PartialTx("ms1", tx.IDHex())
+	require.NoError(t, err)
+	require.False(t, res.Complete)
+
+	_, err = s.client.FinalizePartialTx("ms1", res.PartialTx)
+	require.Error(t, err)
+}
+
+func (s *MultisigSuite) TestReplaceByFee() {
+	t := s.T()
+
+	orig, err := s.client.Send("ms1", 1000000, 100, s.bobInfo.ReceiveAddress, false)
+	require.NoError(t, err)
+	s.cosign(orig)
+
+	replacement, err := s.client.BumpFee("ms1", orig.IDHex(), wallet.BumpMethodRBF, 300)
+	require.NoError(t, err)
+	require.False(t, wallet.IsTxComplete(replacement))
+
+	// the original stays in the wallet until the replacement is signed
+	hashes := s.txHashes("ms1")
+	require.Contains(t, hashes, orig.IDHex())
+	require.NotContains(t, hashes, replacement.IDHex())
+
+	s.cosign(replacement)
+	hashes = s.txHashes("ms1")
+	require.Contains(t, hashes, replacement.IDHex())
+	require.NotContains(t, hashes, orig.IDHex())
+
+	s.mine(1, ZeroRegtestAddr)
+	txs, err := s.client.GetAccountTransactions("ms1", 50, 0)
+	require.NoError(t, err)
+	for _, tx := range txs {
+		if tx.Hash.String() == replacement.IDHex() {
+			require.Equal(t, walletdb.TxStatusConfirmed, tx.Status)
+		}
+	}
+}
+
+func (s *MultisigSuite) txHashes(id string) []string {
+	txs, err := s.client.GetAccountTransactions(id, 50, 0)
+	require.NoError(s.T(), err)
+	var hashes []string
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash.String())
+	}
+	return hashes
+}
+
+func (s *MultisigSuite) cosign(tx *chain.Transaction) {
+	t := s.T()
+
+	res, err := s.client.PartialTx("ms1", tx.IDHex())
+	require.NoError(t, err)
+	require.False(t, res.Complete)
+
+	signed, err := s.client.SignPartialTx("ms2", res.PartialTx)
+	require.NoError(t, err)
+	require.Equal(t, len(tx.Inputs), signed.Signed)
+	require.True(t, signed.Complete)
+
+	_, err = s.client.FinalizePartialTx("ms1", signed.PartialTx)
+	require.NoError(t, err)
+}
+
+func (s *MultisigSuite) mine(count int, address string) {
+	t := s.T()
+	mineTo(t, s.hsd.Client, s.client, count, address)
+	s.height += count
+	for _, id := range []string{"ms1", "ms2", "bob"} {
+		awaitHeight(t, s.client, id, s.height)
+	}
+}
+
+func accountXPub(mnemonic string) string {
+	mk := chain.NewMasterExtendedKeyFromMnemonic(mnemonic, "", chain.NetworkRegtest)
+	return chain.DeriveExtendedKey(
+		mk,
+		chain.HardenNode(chain.CoinPurpose),
+		chain.HardenNode(chain.NetworkRegtest.KeyPrefix.CoinType),
+		chain.HardenNode(0),
+	).Neuter().PublicString()
+}
+
+func TestMultisig(t *testing.T) {
+	suite.Run(t, new(MultisigSuite))
+}
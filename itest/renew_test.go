@@ -3,9 +3,11 @@ package itest
 import (
 	"github.com/kurumiimari/gohan/chain"
 	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/kurumiimari/gohan/walletdb"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 )
 
 type RenewSuite struct {
@@ -77,6 +79,59 @@ func (s *RenewSuite) TestRenewOK() {
 	require.NoError(t, err)
 }
 
+func (s *RenewSuite) TestNameExpiry() {
+	t := s.T()
+	registerHeight := 16 + chain.NetworkRegtest.RevealPeriod
+
+	res, err := s.client.GetName("alice", s.name)
+	require.NoError(t, err)
+	require.Equal(t, registerHeight, res.Name.RenewalHeight)
+	require.Equal(t, registerHeight+chain.NetworkRegtest.RenewalWindow, res.Name.ExpiryHeight)
+	require.Greater(t, res.Name.ExpiresAt, time.Now().Unix())
+
+	_, err = s.client.Renew("alice", s.name, 100, false)
+	require.NoError(t, err)
+	mineTo(t, s.hsd.Client, s.client, 1, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 22+chain.NetworkRegtest.RevealPeriod)
+
+	names, err := s.client.GetNames("alice")
+	require.NoError(t, err)
+	require.Len(t, names.Names, 1)
+	require.Equal(t, 22+chain.NetworkRegtest.RevealPeriod, names.Names[0].RenewalHeight)
+}
+
+func (s *RenewSuite) TestAutoRenew() {
+	t := s.T()
+	blocks := chain.NetworkRegtest.RenewalWindow - chain.NetworkRegtest.RenewalMaturity - 1
+	require.Error(t, s.client.SetAutoRenew("alice", blocks+1))
+	require.NoError(t, s.client.SetAutoRenew("alice", blocks))
+
+	registerHeight := 16 + chain.NetworkRegtest.RevealPeriod
+	dueHeight := registerHeight + chain.NetworkRegtest.RenewalWindow - blocks
+	mineTo(t, s.hsd.Client, s.client, dueHeight-21-chain.NetworkRegtest.RevealPeriod, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", dueHeight)
+
+	var renewed bool
+	for i := 0; i < 30 && !renewed; i++ {
+		require.NoError(t, s.client.PollBlock())
+		res, err := s.client.GetName("alice", s.name)
+		require.NoError(t, err)
+		for _, entry := range res.History {
+			if entry.Type == walletdb.NameActionRenew && entry.Automated {
+				renewed = true
+			}
+		}
+		time.Sleep(time.Second)
+	}
+	require.True(t, renewed)
+
+	mineTo(t, s.hsd.Client, s.client, 1, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", dueHeight+1)
+	res, err := s.client.GetName("alice", s.name)
+	require.NoError(t, err)
+	require.Equal(t, dueHeight+1, res.Name.RenewalHeight)
+}
+
 func TestRenewSuite(t *testing.T) {
 	suite.Run(t, new(RenewSuite))
 }
@@ -1,8 +1,10 @@
 package itest
 
 import (
+	"github.com/kurumiimari/gohan/chain"
 	"github.com/kurumiimari/gohan/testutil"
 	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/kurumiimari/gohan/walletdb"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"testing"
@@ -67,6 +69,8 @@ func (s *RescanSuite) TestNameHistoryTransferredIn() {
 	t := s.T()
 	history, err := s.client.GetName("alice", "rhtnrfaemi")
 	require.NoError(t, err)
+	// depends on the current time
+	history.Name.ExpiresAt = 0
 	testutil.RequireEqualJSONFile(t, "alice-history-transferred-in.json", history)
 }
 
@@ -74,8 +78,11 @@ func (s *RescanSuite) TestNames() {
 	t := s.T()
 	names, err := s.client.GetNames("alice")
 	require.NoError(t, err)
+	clearExpiresAt(names.Names)
 	testutil.RequireEqualJSONFile(t, "alice-names.json", names)
 	names, err = s.client.GetNames("bob")
+	require.NoError(t, err)
+	clearExpiresAt(names.Names)
 	testutil.RequireEqualJSONFile(t, "bob-names.json", names)
 }
 
@@ -86,6 +93,188 @@ func (s *RescanSuite) TestTransactions() {
 	testutil.RequireEqualJSONFile(t, "alice-txs.json", txs)
 }
 
+func (s *RescanSuite) TestSearchTransactions() {
+	t := s.T()
+	all, err := s.client.GetAccountTransactions("alice", 1000, 0)
+	require.NoError(t, err)
+
+	var paged []*walletdb.RichTransaction
+	filter := &walletdb.TransactionFilter{Count: 7}
+	for {
+		txs, next, err := s.client.SearchTransactions("alice", filter)
+		require.NoError(t, err)
+		paged = append(paged, txs...)
+		if next == "" {
+			break
+		}
+		filter.Cursor, err = walletdb.ParseTransactionCursor(next)
+		require.NoError(t, err)
+	}
+	require.Equal(t, all, paged)
+
+	finalize := chain.CovenantFinalize
+	txs, _, err := s.client.SearchTransactions("alice", &walletdb.TransactionFilter{
+		Covenant: &finalize,
+		Count:    1000,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, txs)
+	for _, tx := range txs {
+		var found bool
+		for _, out := range tx.Outputs {
+			found = found || out.Covenant.Type == chain.CovenantFinalize
+		}
+		require.True(t, found)
+	}
+
+	minHeight := 30
+	maxHeight := 43
+	txs, _, err = s.client.SearchTransactions("alice", &walletdb.TransactionFilter{
+		Direction: walletdb.TxDirectionIn,
+		MinHeight: &minHeight,
+		MaxHeight: &maxHeight,
+		Ascending: true,
+		Count:     1000,
+	})
+	require.NoError(t, err)
+	var expected []*walletdb.RichTransaction
+	for i := len(all) - 1; i >= 0; i-- {
+		tx := all[i]
+		if tx.Direction == walletdb.TxDirectionIn && tx.Height >= minHeight && tx.Height <= maxHeight {
+			expected = append(expected, tx)
+		}
+	}
+	require.NotEmpty(t, expected)
+	require.Equal(t, expected, txs)
+
+	txs, _, err = s.client.SearchTransactions("alice", &walletdb.TransactionFilter{
+		Name:  "whncsjjgtc",
+		Count: 1000,
+	})
+	require.NoError(t, err)
+	history, err := s.client.GetName("alice", "whncsjjgtc")
+	require.NoError(t, err)
+	hashes := make(map[string]bool)
+	for _, entry := range history.History {
+		hashes[entry.Transaction.Hash.String()] = true
+	}
+	require.Equal(t, len(hashes), len(txs))
+
+	_, _, err = s.client.SearchTransactions("alice", &walletdb.TransactionFilter{
+		Direction: "sideways",
+		Count:     10,
+	})
+	require.Error(t, err)
+}
+
+func (s *RescanSuite) TestExport() {
+	t := s.T()
+	rows, err := s.client.Export("alice")
+	require.NoError(t, err)
+	txs, err := s.client.GetAccountTransactions("alice", 1000, 0)
+	require.NoError(t, err)
+	require.Equal(t, len(txs), len(rows))
+
+	for i := 1; i < len(rows); i++ {
+		require.GreaterOrEqual(t, rows[i].Height, rows[i-1].Height)
+		require.Equal(t, rows[i-1].Balance+rows[i].NetValue, rows[i].Balance)
+	}
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	bals := info.Balances
+	total := bals.Available + bals.Immature + bals.BidLocked + bals.RevealLocked + bals.NameLocked
+	require.EqualValues(t, total, rows[len(rows)-1].Balance)
+
+	var foundName bool
+	for _, row := range rows {
+		for _, name := range row.Names {
+			foundName = foundName || name == "whncsjjgtc"
+		}
+	}
+	require.True(t, foundName)
+}
+
+func (s *RescanSuite) TestVerifyMessage() {
+	t := s.T()
+	require.NoError(t, s.client.Unlock("alice", "password"))
+
+	sig, err := s.client.SignMessageWithName("alice", "rhtnrfaemi", "log me in")
+	require.NoError(t, err)
+	res, err := s.client.VerifyMessageWithName("rhtnrfaemi", sig, "log me in")
+	require.NoError(t, err)
+	require.True(t, res.Valid)
+	res, err = s.client.VerifyMessageWithName("rhtnrfaemi", sig, "log someone else in")
+	require.NoError(t, err)
+	require.False(t, res.Valid)
+	// owned by bob
+	res, err = s.client.VerifyMessageWithName("whncsjjgtc", sig, "log me in")
+	require.NoError(t, err)
+	require.False(t, res.Valid)
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	sig, err = s.client.SignMessage("alice", info.ReceiveAddress, "hello")
+	require.NoError(t, err)
+	res, err = s.client.VerifyMessage(info.ReceiveAddress, sig, "hello")
+	require.NoError(t, err)
+	require.True(t, res.Valid)
+	res, err = s.client.VerifyMessage(ZeroRegtestAddr, sig, "hello")
+	require.NoError(t, err)
+	require.False(t, res.Valid)
+	_, err = s.client.VerifyMessage(info.ReceiveAddress, "not base64!", "hello")
+	require.Error(t, err)
+}
+
+func (s *RescanSuite) TestAddresses() {
+	t := s.T()
+
+	addrs, err := s.client.GetAddresses("alice", "receive", 50, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, addrs)
+	first := addrs[0]
+	require.True(t, first.Own)
+	require.Equal(t, chain.Derivation{chain.ReceiveBranch, 0}, first.Derivation)
+	require.True(t, first.Received)
+	require.NotNil(t, first.FirstSeenHeight)
+	require.NotNil(t, first.LastSeenHeight)
+	require.LessOrEqual(t, *first.FirstSeenHeight, *first.LastSeenHeight)
+	for _, addr := range addrs {
+		require.EqualValues(t, chain.ReceiveBranch, addr.Derivation[0])
+	}
+
+	var total uint64
+	all, err := s.client.GetAddresses("alice", "", 1000, 0)
+	require.NoError(t, err)
+	require.Greater(t, len(all), len(addrs))
+	for _, addr := range all {
+		total += addr.Balance
+	}
+	require.NotZero(t, total)
+
+	info, err := s.client.GetAddress("alice", first.Address.String())
+	require.NoError(t, err)
+	require.Equal(t, first, info)
+
+	info, err = s.client.GetAddress("alice", ZeroRegtestAddr)
+	require.NoError(t, err)
+	require.False(t, info.Own)
+	require.False(t, info.Received)
+
+	_, err = s.client.GetAddresses("alice", "savings", 50, 0)
+	require.Error(t, err)
+	_, err = s.client.GetAddress("alice", "not an address")
+	require.Error(t, err)
+}
+
+// clearExpiresAt zeroes estimated expiry times, which depend on the current
+// time.
+func clearExpiresAt(names []*walletdb.Name) {
+	for _, name := range names {
+		name.ExpiresAt = 0
+	}
+}
+
 func TestRescanSuite(t *testing.T) {
 	suite.Run(t, new(RescanSuite))
 }
@@ -1,11 +1,17 @@
 package itest
 
 import (
+	"context"
+	"encoding/json"
 	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/wallet"
 	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/kurumiimari/gohan/walletdb"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"strings"
 	"testing"
+	"time"
 )
 
 type AccountSendSuite struct {
@@ -83,14 +89,179 @@ func (s *AccountSendSuite) TestSendBalanceDecreases() {
 
 	info, err = s.client.GetAccount("alice")
 	require.NoError(t, err)
-	require.EqualValues(t, 1998982000, info.Balances.Available)
+	require.EqualValues(t, 1998986000, info.Balances.Available)
 
 	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.CoinbaseMaturity, ZeroRegtestAddr)
 	awaitHeight(t, s.client, "alice", 3)
 
 	info, err = s.client.GetAccount("alice")
 	require.NoError(t, err)
-	require.EqualValues(t, 1998982000, info.Balances.Available)
+	require.EqualValues(t, 1998986000, info.Balances.Available)
+}
+
+func (s *AccountSendSuite) TestSendCoinControl() {
+	t := s.T()
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+
+	err = s.client.Unlock("alice", "password")
+	require.NoError(t, err)
+
+	mineTo(t, s.hsd.Client, s.client, 2, info.ReceiveAddress)
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.CoinbaseMaturity, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 2+chain.NetworkRegtest.CoinbaseMaturity)
+
+	coinsRes, err := s.client.Coins("alice")
+	require.NoError(t, err)
+	require.Len(t, coinsRes.Coins, 2)
+	first := coinsRes.Coins[0].Prevout
+	second := coinsRes.Coins[1].Prevout
+
+	require.NoError(t, s.client.FreezeCoins("alice", []*chain.Outpoint{first, second}))
+	_, err = s.client.Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "insufficient funds")
+
+	_, err = s.client.WithCoinControl(api.CoinControl{
+		Coins: []*chain.Outpoint{first},
+	}).Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "frozen")
+
+	require.NoError(t, s.client.UnfreezeCoins("alice", []*chain.Outpoint{second}))
+	tx, err := s.client.Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.NoError(t, err)
+	require.Len(t, tx.Inputs, 1)
+	require.True(t, tx.Inputs[0].Prevout.Equal(second))
+
+	require.NoError(t, s.client.UnfreezeCoins("alice", []*chain.Outpoint{first}))
+	tx, err = s.client.WithCoinControl(api.CoinControl{
+		Coins: []*chain.Outpoint{first},
+	}).Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.NoError(t, err)
+	require.Len(t, tx.Inputs, 1)
+	require.True(t, tx.Inputs[0].Prevout.Equal(first))
+}
+
+func (s *AccountSendSuite) TestSendMany() {
+	t := s.T()
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+
+	err = s.client.Unlock("alice", "password")
+	require.NoError(t, err)
+
+	mineTo(t, s.hsd.Client, s.client, 1, info.ReceiveAddress)
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.CoinbaseMaturity, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 1+chain.NetworkRegtest.CoinbaseMaturity)
+
+	csv := strings.NewReader("address,amount\n" +
+		ZeroRegtestAddr + ",1.5\n" +
+		ZeroRegtestAddr + ",2\n" +
+		ZeroRegtestAddr + ",0.25\n")
+	tx, err := s.client.SendCSV("alice", csv, 100, false)
+	require.NoError(t, err)
+	require.Len(t, tx.Inputs, 1)
+	require.Len(t, tx.Outputs, 4)
+	require.EqualValues(t, 1500000, tx.Outputs[0].Value)
+	require.EqualValues(t, 2000000, tx.Outputs[1].Value)
+	require.EqualValues(t, 250000, tx.Outputs[2].Value)
+
+	_, err = s.client.SendMany("alice", nil, 100, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "at least one recipient")
+}
+
+func (s *AccountSendSuite) TestSendTransactionStatus() {
+	t := s.T()
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+
+	err = s.client.Unlock("alice", "password")
+	require.NoError(t, err)
+
+	mineTo(t, s.hsd.Client, s.client, 1, info.ReceiveAddress)
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.CoinbaseMaturity, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 1+chain.NetworkRegtest.CoinbaseMaturity)
+
+	tx, err := s.client.Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.NoError(t, err)
+
+	statuses := func() map[string]string {
+		txs, err := s.client.GetAccountTransactions("alice", 50, 0)
+		require.NoError(t, err)
+		out := make(map[string]string)
+		for _, tx := range txs {
+			out[tx.Hash.String()] = tx.Status
+		}
+		return out
+	}
+	require.Equal(t, walletdb.TxStatusPending, statuses()[tx.IDHex()])
+
+	mineTo(t, s.hsd.Client, s.client, 1, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 2+chain.NetworkRegtest.CoinbaseMaturity)
+	require.Equal(t, walletdb.TxStatusConfirmed, statuses()[tx.IDHex()])
+}
+
+func (s *AccountSendSuite) TestSendEvents() {
+	t := s.T()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := s.client.SubscribeEvents(ctx, "alice")
+	require.NoError(t, err)
+	awaitEvent := func(evtType wallet.EventType, data interface{}) {
+		timeout := time.After(30 * time.Second)
+		for {
+			select {
+			case evt, ok := <-events:
+				require.True(t, ok)
+				if evt.Type != evtType {
+					continue
+				}
+				require.Equal(t, "alice", evt.AccountID)
+				require.NoError(t, json.Unmarshal(evt.Data, data))
+				return
+			case <-timeout:
+				t.Fatalf("timed out waiting for %s event", evtType)
+			}
+		}
+	}
+
+	require.NoError(t, s.client.Unlock("alice", "password"))
+	lockEvt := new(wallet.LockEvent)
+	awaitEvent(wallet.EventLock, lockEvt)
+	require.False(t, lockEvt.Locked)
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	mineTo(t, s.hsd.Client, s.client, 1, info.ReceiveAddress)
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.CoinbaseMaturity, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 1+chain.NetworkRegtest.CoinbaseMaturity)
+
+	txEvt := new(wallet.TransactionEvent)
+	awaitEvent(wallet.EventTransaction, txEvt)
+	require.Equal(t, 1, txEvt.Height)
+	balEvt := new(wallet.BalanceEvent)
+	awaitEvent(wallet.EventBalance, balEvt)
+
+	tx, err := s.client.Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.NoError(t, err)
+	awaitEvent(wallet.EventTransaction, txEvt)
+	require.Equal(t, tx.IDHex(), txEvt.Hash)
+	require.Equal(t, -1, txEvt.Height)
+
+	mineTo(t, s.hsd.Client, s.client, 1, ZeroRegtestAddr)
+	awaitEvent(wallet.EventConfirmation, txEvt)
+	require.Equal(t, tx.IDHex(), txEvt.Hash)
+	require.Equal(t, 2+chain.NetworkRegtest.CoinbaseMaturity, txEvt.Height)
+
+	require.NoError(t, s.client.Lock("alice"))
+	awaitEvent(wallet.EventLock, lockEvt)
+	require.True(t, lockEvt.Locked)
 }
 
 func TestAccountSend(t *testing.T) {
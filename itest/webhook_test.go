@@ -0,0 +1,120 @@
+package itest
+
+import (
+	"encoding/json"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/wallet"
+	"github.com/kurumiimari/gohan/wallet/api"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type WebhookSuite struct {
+	suite.Suite
+	hsd     *HSD
+	client  *api.Client
+	cleanup func()
+}
+
+func (s *WebhookSuite) SetupTest() {
+	t := s.T()
+	s.hsd = startHSD()
+	s.client, s.cleanup = startDaemon(t)
+
+	_, err := s.client.CreateAccount(&api.CreateAccountReq{
+		ID:       "alice",
+		Password: "password",
+	})
+	require.NoError(t, err)
+}
+
+func (s *WebhookSuite) TearDownTest() {
+	s.cleanup()
+	s.hsd.Stop()
+}
+
+func (s *WebhookSuite) TestDeliveries() {
+	t := s.T()
+
+	var secret string
+	payloads := make(chan *wallet.WebhookPayload, 16)
+	failures := 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, wallet.SignWebhookPayload(secret, body), r.Header.Get(wallet.WebhookSignatureHeader))
+
+		// fail the first delivery to exercise the retry queue
+		if failures > 0 {
+			failures--
+			w.WriteHeader(500)
+			return
+		}
+
+		payload := new(wallet.WebhookPayload)
+		require.NoError(t, json.Unmarshal(body, payload))
+		payloads <- payload
+		w.WriteHeader(204)
+	}))
+	defer srv.Close()
+
+	hook, err := s.client.AddWebhook("alice", srv.URL)
+	require.NoError(t, err)
+	require.NotEmpty(t, hook.Secret)
+	secret = hook.Secret
+
+	awaitPayload := func(evtType wallet.WebhookEventType) map[string]interface{} {
+		timeout := time.After(60 * time.Second)
+		for {
+			select {
+			case payload := <-payloads:
+				if payload.Type != evtType {
+					continue
+				}
+				require.Equal(t, "alice", payload.AccountID)
+				return payload.Data.(map[string]interface{})
+			case <-timeout:
+				t.Fatalf("timed out waiting for %s webhook", evtType)
+			}
+		}
+	}
+
+	info, err := s.client.GetAccount("alice")
+	require.NoError(t, err)
+	mineTo(t, s.hsd.Client, s.client, 1, info.ReceiveAddress)
+	awaitHeight(t, s.client, "alice", 1)
+
+	data := awaitPayload(wallet.WebhookPaymentReceived)
+	require.EqualValues(t, 1, data["height"])
+	require.EqualValues(t, 2000000000, data["value"])
+
+	mineTo(t, s.hsd.Client, s.client, chain.NetworkRegtest.CoinbaseMaturity, ZeroRegtestAddr)
+	awaitHeight(t, s.client, "alice", 1+chain.NetworkRegtest.CoinbaseMaturity)
+	require.NoError(t, s.client.Unlock("alice", "password"))
+	tx, err := s.client.Send("alice", 1000000, 100, ZeroRegtestAddr, false)
+	require.NoError(t, err)
+	mineTo(t, s.hsd.Client, s.client, 1, ZeroRegtestAddr)
+
+	data = awaitPayload(wallet.WebhookSendConfirmed)
+	require.Equal(t, tx.IDHex(), data["hash"])
+
+	hooks, err := s.client.GetWebhooks("alice")
+	require.NoError(t, err)
+	require.Len(t, hooks, 1)
+	require.Empty(t, hooks[0].Secret)
+	require.Equal(t, 0, hooks[0].FailedDeliveries)
+
+	require.NoError(t, s.client.RemoveWebhook("alice", hook.ID))
+	hooks, err = s.client.GetWebhooks("alice")
+	require.NoError(t, err)
+	require.Len(t, hooks, 0)
+}
+
+func TestWebhookSuite(t *testing.T) {
+	suite.Run(t, new(WebhookSuite))
+}
@@ -2400,7 +2400,7 @@ func opcodeCheckMultiSig(op *parsedOpcode, vm *Engine) error {
 			}
 
 			// Parse the signature.
-			parsedSig, err = btcec.ParseSignature(signature, btcec.S256())
+			parsedSig, err = chain.DeserializeSignature(signature)
 			sigInfo.parsed = true
 			if err != nil {
 				continue
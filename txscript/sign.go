@@ -97,3 +97,7 @@ func HIP1CancelWitnessSignature(tx *chain.Transaction, idx int, amt uint64, priv
 		},
 	}, nil
 }
+
+func MultisigSignature(tx *chain.Transaction, idx int, amt uint64, script []byte, privKey *btcec.PrivateKey) ([]byte, error) {
+	return RawTxInWitnessSignature(tx, NewTxSigHashes(tx), idx, amt, script, SigHashAll, privKey)
+}
@@ -2,6 +2,7 @@ package txscript
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/kurumiimari/gohan/chain"
 )
 
@@ -35,6 +36,9 @@ func NewHIP1LockingScript(pubKey []byte) ([]byte, error) {
 }
 
 func IsHIP1LockingScript(witness *chain.Witness) bool {
+	if len(witness.Items) == 0 {
+		return false
+	}
 	script := witness.Items[len(witness.Items)-1]
 	if len(script) != 44 {
 		return false
@@ -43,3 +47,63 @@ func IsHIP1LockingScript(witness *chain.Witness) bool {
 	genScript, err := NewHIP1LockingScript(pub)
 	return err == nil && bytes.Equal(script, genScript)
 }
+
+// NewMultisigScript creates an m-of-n CHECKMULTISIG script. Public keys
+// are pushed in the order they are provided.
+func NewMultisigScript(threshold int, pubKeys [][]byte) ([]byte, error) {
+	if threshold < 1 || threshold > len(pubKeys) {
+		return nil, fmt.Errorf("invalid multisig threshold %d of %d", threshold, len(pubKeys))
+	}
+	if len(pubKeys) > MaxPubKeysPerMultiSig {
+		return nil, fmt.Errorf("too many multisig public keys: %d", len(pubKeys))
+	}
+
+	builder := NewScriptBuilder().AddInt64(int64(threshold))
+	for _, pub := range pubKeys {
+		builder.AddData(pub)
+	}
+	return builder.
+		AddInt64(int64(len(pubKeys))).
+		AddOp(OP_CHECKMULTISIG).
+		Script()
+}
+
+// ParseMultisigScript returns the threshold and public keys of a script
+// created by NewMultisigScript.
+func ParseMultisigScript(script []byte) (int, [][]byte, error) {
+	pops, err := parseScript(script)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	l := len(pops)
+	if l < 4 ||
+		!isSmallInt(pops[0].opcode) ||
+		!isSmallInt(pops[l-2].opcode) ||
+		pops[l-1].opcode.value != OP_CHECKMULTISIG {
+		return 0, nil, fmt.Errorf("not a multisig script")
+	}
+
+	threshold := smallIntValue(pops[0].opcode)
+	numKeys := smallIntValue(pops[l-2].opcode)
+	if numKeys != l-3 || threshold < 1 || threshold > numKeys {
+		return 0, nil, fmt.Errorf("invalid multisig script")
+	}
+
+	pubKeys := make([][]byte, numKeys)
+	for i := 0; i < numKeys; i++ {
+		data := pops[i+1].data
+		if len(data) != 33 {
+			return 0, nil, fmt.Errorf("invalid multisig public key")
+		}
+		pubKeys[i] = data
+	}
+	return threshold, pubKeys, nil
+}
+
+func smallIntValue(op *opcode) int {
+	if op.value == OP_0 {
+		return 0
+	}
+	return int(op.value - (OP_1 - 1))
+}
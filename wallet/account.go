@@ -9,13 +9,13 @@ import (
 	"github.com/kurumiimari/gohan/bio"
 	"github.com/kurumiimari/gohan/chain"
 	"github.com/kurumiimari/gohan/client"
+	"github.com/kurumiimari/gohan/gcrypto"
 	"github.com/kurumiimari/gohan/log"
 	"github.com/kurumiimari/gohan/shakedex"
 	"github.com/kurumiimari/gohan/txscript"
 	"github.com/kurumiimari/gohan/walletdb"
 	"github.com/pkg/errors"
 	"github.com/tyler-smith/go-bip32"
-	"golang.org/x/crypto/blake2b"
 	"gopkg.in/tomb.v2"
 	"regexp"
 	"strings"
@@ -33,6 +33,11 @@ var (
 	accLogger = log.ModuleLogger("account")
 )
 
+type Recipient struct {
+	Address *chain.Address `json:"address"`
+	Value   uint64         `json:"value"`
+}
+
 type UnspentBid struct {
 	Name            string `json:"name"`
 	BlockHeight     int    `json:"block_height"`
@@ -62,15 +67,28 @@ type Account struct {
 	bm            *BlockMonitor
 	keyLocker     *KeyLocker
 	ring          Keyring
+	msRing        *MultisigKeyring
 	addrBloom     *AddressBloom
 	recvMgr       *AddressManager
 	changeMgr     *AddressManager
 	dutchMgr      *AddressManager
 	id            string
 	idx           uint32
+	walletID      string
+	watchOnly     bool
 	rescanHeight  int
 	xPub          *bip32.Key
 	outpointBloom *OutpointBloom
+	autoReveal    bool
+	autoSweep     bool
+	autoRegister  *chain.Resource
+	autoRenew     int
+	events        *EventBus
+	lastBalances  *walletdb.Balances
+	webhookC      chan struct{}
+	queuedEvents  []*Event
+	scanSubs      []chan int
+	scanMtx       sync.Mutex
 	mtx           sync.RWMutex
 	lgr           log.Logger
 }
@@ -82,6 +100,7 @@ func NewAccount(
 	client *client.NodeRPCClient,
 	bm *BlockMonitor,
 	opts *walletdb.AccountOpts,
+	extSigner Signer,
 ) (*Account, error) {
 	box, err := UnmarshalSecretBox([]byte(opts.Seed))
 	if err != nil {
@@ -96,8 +115,24 @@ func NewAccount(
 		return nil, err
 	}
 
+	mnemonicBox, err := unmarshalOptionalSecretBox(opts.Mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
 	keyLocker := NewKeyLocker(box, network)
-	ring := NewAccountKeyring(keyLocker, opts.XPub, network)
+	keyLocker.mnemonicBox = mnemonicBox
+	var signer Signer = keyLocker
+	if extSigner != nil {
+		signer = extSigner
+	}
+
+	var ring Keyring = NewAccountKeyring(signer, opts.XPub, network)
+	var msRing *MultisigKeyring
+	if len(opts.Cosigners) > 0 {
+		msRing = NewMultisigKeyring(ring.(*AccountKeyring), opts.Cosigners, opts.Threshold)
+		ring = msRing
+	}
 
 	return &Account{
 		tmb:       tmb,
@@ -107,6 +142,7 @@ func NewAccount(
 		bm:        bm,
 		keyLocker: keyLocker,
 		ring:      ring,
+		msRing:    msRing,
 		addrBloom: addrBloom,
 		recvMgr: NewAddressManager(
 			ring,
@@ -115,6 +151,7 @@ func NewAccount(
 			chain.ReceiveBranch,
 			opts.RecvIdx,
 			int64(opts.LookaheadTips[chain.ReceiveBranch]),
+			WithLookSize(gapLimitOrDefault(opts.RecvGapLimit, AddrLookahead)),
 		),
 		changeMgr: NewAddressManager(
 			ring,
@@ -123,6 +160,7 @@ func NewAccount(
 			chain.ChangeBranch,
 			opts.ChangeIdx,
 			int64(opts.LookaheadTips[chain.ChangeBranch]),
+			WithLookSize(gapLimitOrDefault(opts.ChangeGapLimit, AddrLookahead)),
 		),
 		dutchMgr: NewAddressManager(
 			ring,
@@ -131,13 +169,21 @@ func NewAccount(
 			shakedex.AddressBranch,
 			opts.DutchAuctionIdx,
 			int64(opts.LookaheadTips[shakedex.AddressBranch]),
-			WithLookSize(10),
+			WithLookSize(gapLimitOrDefault(opts.DutchAuctionGapLimit, DutchAuctionLookahead)),
 			WithAddressMaker(HIP1AddressMaker),
 		),
 		id:            opts.ID,
 		idx:           opts.Idx,
+		walletID:      opts.WalletID,
+		watchOnly:     opts.WatchOnly,
 		rescanHeight:  opts.RescanHeight,
 		outpointBloom: outBloom,
+		autoReveal:    opts.AutoReveal,
+		autoSweep:     opts.AutoSweep,
+		autoRegister:  opts.AutoRegister,
+		autoRenew:     opts.AutoRenewBlocks,
+		events:        NewEventBus(),
+		webhookC:      make(chan struct{}, 1),
 		lgr: accLogger.Child(
 			"id",
 			opts.ID,
@@ -146,6 +192,26 @@ func NewAccount(
 }
 
 func (a *Account) Start() error {
+	// services subscribe to scanned heights before the scan loop starts so
+	// that they don't miss the initial poll
+	if err := NewAutoRevealer(a).Start(); err != nil {
+		return err
+	}
+	if err := NewAutoSweeper(a).Start(); err != nil {
+		return err
+	}
+	if err := NewAutoRenewer(a).Start(); err != nil {
+		return err
+	}
+	if err := NewWebhookDispatcher(a).Start(); err != nil {
+		return err
+	}
+	a.onNewBlocks(func() bool { return true }, func(height int) {
+		if err := a.refreshRenewalHeights(); err != nil {
+			a.lgr.Error("error refreshing name renewal heights", "err", err)
+		}
+	})
+
 	a.tmb.Go(func() error {
 		blockC := a.bm.Subscribe()
 
@@ -161,6 +227,7 @@ func (a *Account) Start() error {
 				if err := a.lockedRescan(notif); err != nil {
 					a.lgr.Error("error indexing block", "err", err)
 				}
+				a.notifyScanned(a.RescanHeight())
 			}
 		}
 	})
@@ -168,6 +235,49 @@ func (a *Account) Start() error {
 	return nil
 }
 
+// onNewBlocks calls cb once for each new height the account finishes
+// indexing while enabled returns true. Heights that arrive while cb is
+// still running are coalesced into the latest one.
+func (a *Account) onNewBlocks(enabled func() bool, cb func(height int)) {
+	scannedC := make(chan int, 1)
+	a.scanMtx.Lock()
+	a.scanSubs = append(a.scanSubs, scannedC)
+	a.scanMtx.Unlock()
+
+	a.tmb.Go(func() error {
+		var lastHeight int
+		for {
+			select {
+			case <-a.tmb.Dying():
+				return nil
+			case height := <-scannedC:
+				if height <= lastHeight || !enabled() {
+					continue
+				}
+				lastHeight = height
+				cb(height)
+			}
+		}
+	})
+}
+
+// notifyScanned tells onNewBlocks subscribers that the account has indexed
+// the chain up to height, replacing any height they haven't picked up yet.
+func (a *Account) notifyScanned(height int) {
+	a.scanMtx.Lock()
+	defer a.scanMtx.Unlock()
+	for _, c := range a.scanSubs {
+		select {
+		case <-c:
+		default:
+		}
+		select {
+		case c <- height:
+		default:
+		}
+	}
+}
+
 func (a *Account) ID() string {
 	return a.id
 }
@@ -176,6 +286,20 @@ func (a *Account) Index() uint32 {
 	return a.idx
 }
 
+// WalletID returns the ID of the wallet the account was derived from, or an
+// empty string if the account has a seed of its own.
+func (a *Account) WalletID() string {
+	return a.walletID
+}
+
+func (a *Account) WatchOnly() bool {
+	return a.watchOnly
+}
+
+func (a *Account) Multisig() *MultisigKeyring {
+	return a.msRing
+}
+
 func (a *Account) Locked() bool {
 	return a.keyLocker.Locked()
 }
@@ -187,11 +311,243 @@ func (a *Account) Unlock(password string) error {
 		return err
 	}
 	a.lgr.Info("wallet unlocked")
+	a.publish(&Event{
+		Type: EventLock,
+		Data: &LockEvent{Locked: false},
+	})
+	return nil
+}
+
+func (a *Account) unlockWithKey(ek chain.ExtendedKey) {
+	a.keyLocker.unlockWithKey(ek)
+	a.lgr.Info("wallet unlocked")
+	a.publish(&Event{
+		Type: EventLock,
+		Data: &LockEvent{Locked: false},
+	})
+}
+
+// ChangePassword re-encrypts the account's seed with a new password and KDF
+// parameters. Accounts that belong to a wallet share its password, so their
+// password is changed through the wallet instead.
+func (a *Account) ChangePassword(oldPassword, newPassword string, params KDFParams) error {
+	if a.walletID != "" {
+		return errors.Errorf("account belongs to wallet %s, change the wallet's password instead", a.walletID)
+	}
+
+	box, mnemonicBox, err := a.keyLocker.rekey(oldPassword, newPassword, params)
+	if err != nil {
+		a.lgr.Warning("change password attempt failed")
+		return err
+	}
+	seed, err := marshalSecretBox(box)
+	if err != nil {
+		return err
+	}
+	mnemonic, err := marshalSecretBox(mnemonicBox)
+	if err != nil {
+		return err
+	}
+	err = a.engine.Transaction(func(tx walletdb.Transactor) error {
+		return walletdb.UpdateAccountSeed(tx, a.id, seed, mnemonic)
+	})
+	if err != nil {
+		return err
+	}
+	a.keyLocker.setBoxes(box, mnemonicBox)
+	a.lgr.Info("password changed")
 	return nil
 }
 
+// Mnemonic decrypts the backup words the account was created from.
+func (a *Account) Mnemonic(password string) (string, error) {
+	if a.walletID != "" {
+		return "", errors.Errorf("account belongs to wallet %s, export the wallet's mnemonic instead", a.walletID)
+	}
+	mnemonic, err := a.keyLocker.mnemonic(password)
+	if err != nil {
+		a.lgr.Warning("mnemonic export attempt failed")
+		return "", err
+	}
+	return mnemonic, nil
+}
+
 func (a *Account) Lock() {
 	a.keyLocker.Lock()
+	a.publish(&Event{
+		Type: EventLock,
+		Data: &LockEvent{Locked: true},
+	})
+}
+
+func (a *Account) AutoReveal() bool {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	return a.autoReveal
+}
+
+func (a *Account) SetAutoReveal(enabled bool) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if enabled && (a.watchOnly || a.msRing != nil) {
+		return errors.New("auto-reveal requires an account that can sign on its own")
+	}
+
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		return walletdb.UpdateAutoReveal(tx, a.id, enabled)
+	})
+	if err != nil {
+		return err
+	}
+	a.autoReveal = enabled
+	return nil
+}
+
+func (a *Account) AutoSweep() bool {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	return a.autoSweep
+}
+
+// AutoRegisterResource returns the resource used when the sweeper registers
+// won names. A nil resource registers the name without any records.
+func (a *Account) AutoRegisterResource() *chain.Resource {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	return a.autoRegister
+}
+
+func (a *Account) SetAutoSweep(enabled bool, resource *chain.Resource) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if enabled && (a.watchOnly || a.msRing != nil) {
+		return errors.New("auto-sweep requires an account that can sign on its own")
+	}
+
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		return walletdb.UpdateAutoSweep(tx, a.id, enabled, resource)
+	})
+	if err != nil {
+		return err
+	}
+	a.autoSweep = enabled
+	a.autoRegister = resource
+	return nil
+}
+
+// AutoRenewBlocks returns how many blocks before expiry owned names are
+// automatically renewed. Zero means auto-renew is disabled.
+func (a *Account) AutoRenewBlocks() int {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	return a.autoRenew
+}
+
+func (a *Account) AutoRenew() bool {
+	return a.AutoRenewBlocks() > 0
+}
+
+func (a *Account) SetAutoRenew(blocks int) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if blocks < 0 {
+		return errors.New("blocks must not be negative")
+	}
+	// renewing any earlier would churn out a renewal every few blocks
+	if blocks >= a.network.RenewalWindow-a.network.RenewalMaturity {
+		return errors.Errorf("blocks must be less than %d", a.network.RenewalWindow-a.network.RenewalMaturity)
+	}
+	if blocks > 0 && (a.watchOnly || a.msRing != nil) {
+		return errors.New("auto-renew requires an account that can sign on its own")
+	}
+
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		return walletdb.UpdateAutoRenew(tx, a.id, blocks)
+	})
+	if err != nil {
+		return err
+	}
+	a.autoRenew = blocks
+	return nil
+}
+
+// AbandonTx removes an unconfirmed transaction from the account, undoing
+// its effects on the account's coins and names. Transactions with
+// descendants must have their descendants abandoned first.
+func (a *Account) AbandonTx(hash gcrypto.Hash) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	var events []*Event
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		dbTx, err := walletdb.GetTransactionByOutpoint(tx, a.id, hash)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("transaction not found")
+		}
+		if err != nil {
+			return err
+		}
+		if dbTx.BlockHeight != -1 {
+			return errors.New("transaction is already confirmed")
+		}
+
+		hasDescendants, err := walletdb.HasSpentOutputs(tx, a.id, dbTx.Hash)
+		if err != nil {
+			return err
+		}
+		if hasDescendants {
+			return errors.New("transaction has descendants")
+		}
+
+		snap, err := a.snapshotEvents(tx)
+		if err != nil {
+			return err
+		}
+		if err := walletdb.AbandonTransaction(tx, a.id, dbTx.Hash); err != nil {
+			return err
+		}
+		events, err = a.stateEvents(tx, snap)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	a.publish(events...)
+	return nil
+}
+
+// dropTx marks a pending transaction as dropped and releases the coins
+// it spent.
+func (a *Account) dropTx(hash string) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	var events []*Event
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		snap, err := a.snapshotEvents(tx)
+		if err != nil {
+			return err
+		}
+		if err := walletdb.DropTransaction(tx, a.id, hash); err != nil {
+			return err
+		}
+		events, err = a.stateEvents(tx, snap)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	a.publish(events...)
+	return nil
+}
+
+func (a *Account) markAutomated(tx *chain.Transaction) error {
+	return a.engine.Transaction(func(dTx walletdb.Transactor) error {
+		return walletdb.MarkNameHistoryAutomated(dTx, a.id, tx.IDHex())
+	})
 }
 
 func (a *Account) RescanHeight() int {
@@ -212,6 +568,10 @@ func (a *Account) AddressDepth() (uint32, uint32) {
 	return a.recvMgr.Depth(), a.changeMgr.Depth()
 }
 
+func (a *Account) GapLimits() (uint32, uint32, uint32) {
+	return a.recvMgr.GapLimit(), a.changeMgr.GapLimit(), a.dutchMgr.GapLimit()
+}
+
 func (a *Account) LookaheadDepth() (uint32, uint32) {
 	return a.recvMgr.Lookahead(), a.changeMgr.Lookahead()
 }
@@ -256,6 +616,50 @@ func (a *Account) GenerateChangeAddress() (*chain.Address, uint32, error) {
 	return addr, a.changeMgr.Depth(), err
 }
 
+// Addresses lists the account's derived addresses, including lookahead
+// addresses that haven't been handed out yet. branch is receive, change,
+// dutch_auction, or empty for all of them.
+func (a *Account) Addresses(branch string, count, offset int) ([]*walletdb.AddressInfo, error) {
+	branchNum := int64(-1)
+	switch branch {
+	case "":
+	case "receive":
+		branchNum = int64(chain.ReceiveBranch)
+	case "change":
+		branchNum = int64(chain.ChangeBranch)
+	case "dutch_auction":
+		branchNum = int64(shakedex.AddressBranch)
+	default:
+		return nil, errors.New("branch must be receive, change, or dutch_auction")
+	}
+
+	var infos []*walletdb.AddressInfo
+	err := a.engine.Transaction(func(q walletdb.Transactor) error {
+		var err error
+		infos, err = walletdb.GetAddressInfos(q, a.id, branchNum, count, offset)
+		return err
+	})
+	return infos, err
+}
+
+// AddressInfo describes addr if it belongs to the account. Otherwise, the
+// result is only marked as not owned.
+func (a *Account) AddressInfo(addr *chain.Address) (*walletdb.AddressInfo, error) {
+	var info *walletdb.AddressInfo
+	err := a.engine.Transaction(func(q walletdb.Transactor) error {
+		var err error
+		info, err = walletdb.GetAddressInfo(q, a.id, addr)
+		if errors.Is(err, sql.ErrNoRows) {
+			info = &walletdb.AddressInfo{
+				Address: addr,
+			}
+			return nil
+		}
+		return err
+	})
+	return info, err
+}
+
 func (a *Account) Coins() ([]*walletdb.Coin, error) {
 	var coins []*walletdb.Coin
 	err := a.engine.Transaction(func(q walletdb.Transactor) error {
@@ -263,6 +667,12 @@ func (a *Account) Coins() ([]*walletdb.Coin, error) {
 		if err != nil {
 			return err
 		}
+		for _, coin := range c {
+			coin.Label, err = walletdb.GetCoinLabel(q, a.id, coin.Prevout.String(), coin.Address.String())
+			if err != nil {
+				return err
+			}
+		}
 		coins = c
 		return nil
 	})
@@ -279,7 +689,54 @@ func (a *Account) Names(count, offset int) ([]*walletdb.Name, error) {
 		names = n
 		return nil
 	})
-	return names, err
+	if err != nil {
+		return nil, err
+	}
+
+	height := a.RescanHeight()
+	now := time.Now()
+	for _, name := range names {
+		a.setNameExpiry(name, height, now)
+	}
+	return names, nil
+}
+
+// Name returns the account's record of a single name, or nil if the
+// account has never interacted with it.
+func (a *Account) Name(name string) (*walletdb.Name, error) {
+	var out *walletdb.Name
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		n, err := walletdb.GetName(tx, a.id, name)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		out = n
+		return nil
+	})
+	if err != nil || out == nil {
+		return nil, err
+	}
+
+	a.setNameExpiry(out, a.RescanHeight(), time.Now())
+	return out, nil
+}
+
+func (a *Account) setNameExpiry(name *walletdb.Name, height int, now time.Time) {
+	if name.Status != walletdb.NameStatusOwned || name.RenewalHeight == 0 {
+		return
+	}
+	name.ExpiryHeight, name.ExpiresAt = nameExpiry(name.RenewalHeight, height, a.network.RenewalWindow, now)
+}
+
+// nameExpiry returns the height at which a name renewed at renewalHeight
+// expires, along with an estimate of when that block will be mined.
+func nameExpiry(renewalHeight, height, renewalWindow int, now time.Time) (int, int64) {
+	expiry := renewalHeight + renewalWindow
+	remaining := time.Duration(expiry-height) * chain.TargetSpacing * time.Second
+	return expiry, now.Add(remaining).Unix()
 }
 
 func (a *Account) History(name string, count, offset int) ([]*walletdb.RichNameHistoryEntry, error) {
@@ -295,236 +752,124 @@ func (a *Account) History(name string, count, offset int) ([]*walletdb.RichNameH
 	return history, err
 }
 
-func (a *Account) Transactions(count int, offset int) ([]*walletdb.RichTransaction, error) {
+func (a *Account) Transactions(filter *walletdb.TransactionFilter) ([]*walletdb.RichTransaction, *walletdb.TransactionCursor, error) {
 	var txs []*walletdb.RichTransaction
+	var next *walletdb.TransactionCursor
 	err := a.engine.Transaction(func(q walletdb.Transactor) error {
-		t, err := walletdb.ListTransactions(q, a.id, count, offset)
+		t, n, err := walletdb.SearchTransactions(q, a.id, filter)
 		if err != nil {
 			return err
 		}
 		txs = t
+		next = n
 		return nil
 	})
-	return txs, err
-}
-
-func (a *Account) Send(value uint64, feeRate uint64, address *chain.Address) (*chain.Transaction, error) {
-	a.mtx.Lock()
-	defer a.mtx.Unlock()
-
-	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
-		return a.send(dTx, address, value, feeRate)
-	})
+	return txs, next, err
 }
 
-func (a *Account) Open(name string, feeRate uint64) (*chain.Transaction, error) {
-	a.mtx.Lock()
-	defer a.mtx.Unlock()
-
-	if !chain.IsNameValid(name) {
-		return nil, errors.New("invalid name")
-	}
-
-	if chain.IsNameReserved(a.network, a.rescanHeight, name) {
-		return nil, errors.New("name is reserved")
-	}
-
-	if !chain.HasRollout(a.network, a.rescanHeight, name) {
-		return nil, errors.New("name not rolled out yet")
-	}
-
-	state, err := a.client.GetNameInfo(name)
-	if err != nil {
-		return nil, err
-	}
-	if state.Info != nil {
-		return nil, errors.New("name is not openable")
-	}
-
-	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
-		// TODO: double open
-
-		recvAddr := a.recvMgr.Address()
-
-		txb := new(TxBuilder)
-		txb.AddOutput(&chain.Output{
-			Value:   0,
-			Address: recvAddr,
-			Covenant: &chain.Covenant{
-				Type: chain.CovenantOpen,
-				Items: [][]byte{
-					chain.HashName(name),
-					bio.Uint32LE(0),
-					[]byte(name),
-				},
-			},
-		})
-
-		tx, err := a.fundTx(dTx, txb, feeRate)
-		if err != nil {
-			return nil, err
-		}
-		if err := a.sendTx(dTx, tx); err != nil {
-			return nil, err
-		}
-		return tx, nil
-	})
+func (a *Account) Send(value uint64, feeRate uint64, address *chain.Address, opts ...TxOption) (*chain.Transaction, error) {
+	return a.SendMany([]*Recipient{
+		{
+			Address: address,
+			Value:   value,
+		},
+	}, feeRate, opts...)
 }
 
-func (a *Account) Bid(name string, feeRate, value, lockup uint64) (*chain.Transaction, error) {
+func (a *Account) SendMany(recipients []*Recipient, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
-	if !chain.IsNameValid(name) {
-		return nil, errors.New("invalid name")
-	}
-
-	if chain.IsNameReserved(a.network, a.rescanHeight, name) {
-		return nil, errors.New("name is reserved")
+	if len(recipients) == 0 {
+		return nil, errors.New("must specify at least one recipient")
 	}
-
-	if !chain.HasRollout(a.network, a.rescanHeight, name) {
-		return nil, errors.New("name not rolled out yet")
-	}
-
-	if value > lockup {
-		return nil, errors.New("value exceeds lockup")
-	}
-
-	state, err := a.requireNameState(name, "BIDDING")
-	if err != nil {
-		return nil, err
-	}
-
-	recvAddr := a.recvMgr.Address()
-	blind := chain.CreateBlind(a.ring.PublicEK(), name, recvAddr, value)
-	txb := new(TxBuilder)
-	txb.AddOutput(&chain.Output{
-		Value:   lockup,
-		Address: recvAddr,
-		Covenant: &chain.Covenant{
-			Type: chain.CovenantBid,
-			Items: [][]byte{
-				chain.HashName(name),
-				bio.Uint32LE(uint32(state.Info.Height)),
-				[]byte(name),
-				blind,
-			},
-		},
-	})
-
-	var tx *chain.Transaction
-	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
-		tx, err = a.fundTx(dTx, txb, feeRate)
-		if err != nil {
-			return nil, errors.WithStack(err)
+	var total uint64
+	for _, recip := range recipients {
+		if recip.Address == nil {
+			return nil, errors.New("recipient address is required")
 		}
-
-		entry := &walletdb.NameHistory{
-			AccountID: a.id,
-			Name:      name,
-			Type:      walletdb.NameActionBid,
-			Outpoint: &chain.Outpoint{
-				Hash:  tx.ID(),
-				Index: 0,
-			},
-			Value:    lockup,
-			BidValue: value,
+		if recip.Value == 0 {
+			return nil, errors.Errorf("value sent to %s must be greater than zero", recip.Address)
 		}
-		if err := walletdb.UpdateNameHistory(dTx, entry); err != nil {
-			return nil, err
-		}
-		if err := a.sendTx(dTx, tx); err != nil {
-			return nil, err
+		if recip.Value > chain.MaxMoney-total {
+			return nil, ErrMaxMoney
 		}
-		return tx, nil
+		total += recip.Value
+	}
+
+	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
+		return a.send(dTx, recipients, feeRate, opts...)
 	})
 }
 
-func (a *Account) Reveal(name string, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) Open(name string, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
-	if !chain.IsNameValid(name) {
-		return nil, errors.New("invalid name")
-	}
+	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
+		// TODO: double open
+		txb := new(TxBuilder)
+		if err := a.addOpen(txb, name); err != nil {
+			return nil, err
+		}
+		return a.sendNameTx(dTx, txb, nil, feeRate, opts...)
+	})
+}
 
-	state, err := a.requireNameState(name, "REVEAL")
-	if err != nil {
-		return nil, err
-	}
+func (a *Account) Bid(name string, feeRate, value, lockup uint64, opts ...TxOption) (*chain.Transaction, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
 
 	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
-		bids, err := walletdb.GetRevealableBids(dTx, a.id, name, a.network, state.Info.Stats.RevealPeriodStart)
+		txb := new(TxBuilder)
+		entry, err := a.addBid(txb, name, value, lockup)
 		if err != nil {
 			return nil, err
 		}
-		if len(bids) == 0 {
-			return nil, errors.New("no bids to reveal")
-		}
-
-		return a.sendReveals(dTx, bids, name, state.Info.Height, feeRate)
+		return a.sendNameTx(dTx, txb, []*walletdb.NameHistory{entry}, feeRate, opts...)
 	})
 }
 
-func (a *Account) Redeem(name string, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) Reveal(name string, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
-	if !chain.IsNameValid(name) {
-		return nil, errors.New("invalid name")
-	}
-
-	state, err := a.requireNameState(name, "CLOSED")
-	if err != nil {
-		return nil, err
-	}
-
-	winner := state.Info.Owner
-	winnerOutpoint := &chain.Outpoint{
-		Hash:  winner.Hash,
-		Index: winner.Index,
-	}
-
 	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
-		coins, err := walletdb.GetRedeemableReveals(dTx, a.id, name)
-		if err != nil {
+		txb := new(TxBuilder)
+		if err := a.addReveals(dTx, txb, name); err != nil {
 			return nil, err
 		}
-		if len(coins) == 0 {
-			return nil, errors.New("no reveals to redeem")
-		}
+		return a.sendNameTx(dTx, txb, nil, feeRate, opts...)
+	})
+}
 
-		losingReveals := make([]*chain.Coin, 0)
-		for _, rev := range coins {
-			if rev.Prevout.Equal(winnerOutpoint) {
-				continue
-			}
-			losingReveals = append(losingReveals, rev.AsChain())
-		}
+func (a *Account) Redeem(name string, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
 
-		if len(losingReveals) == 0 {
-			return nil, errors.New("no losing reveals")
+	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
+		txb := new(TxBuilder)
+		if err := a.addRedeems(dTx, txb, name); err != nil {
+			return nil, err
 		}
-
-		return a.sendRedeems(dTx, losingReveals, name, state.Info.Height, feeRate)
+		return a.sendNameTx(dTx, txb, nil, feeRate, opts...)
 	})
 }
 
-func (a *Account) Update(name string, resource *chain.Resource, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) Update(name string, resource *chain.Resource, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
-	var tx *chain.Transaction
-	var err error
-	err = a.engine.Transaction(func(q walletdb.Transactor) error {
-		tx, err = a.sendUpdate(q, name, resource, feeRate)
-		return err
+	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
+		txb := new(TxBuilder)
+		if err := a.addUpdate(dTx, txb, name, resource); err != nil {
+			return nil, err
+		}
+		return a.sendNameTx(dTx, txb, nil, feeRate, opts...)
 	})
-	return tx, err
 }
 
-func (a *Account) Transfer(name string, address *chain.Address, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) Transfer(name string, address *chain.Address, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
@@ -551,7 +896,7 @@ func (a *Account) Transfer(name string, address *chain.Address, feeRate uint64)
 			Covenant: chain.NewTransferCovenant(name, state.Info.Height, address),
 		})
 
-		tx, err := a.fundTx(q, txb, feeRate)
+		tx, err = a.fundTx(q, txb, feeRate, opts...)
 		if err != nil {
 			return errors.Wrap(err, "error funding transaction")
 		}
@@ -561,7 +906,7 @@ func (a *Account) Transfer(name string, address *chain.Address, feeRate uint64)
 	return tx, err
 }
 
-func (a *Account) Finalize(name string, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) Finalize(name string, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
@@ -608,7 +953,7 @@ func (a *Account) Finalize(name string, feeRate uint64) (*chain.Transaction, err
 			),
 		})
 
-		tx, err := a.fundTx(q, txb, feeRate)
+		tx, err = a.fundTx(q, txb, feeRate, opts...)
 		if err != nil {
 			return errors.Wrap(err, "error funding transaction")
 		}
@@ -618,16 +963,20 @@ func (a *Account) Finalize(name string, feeRate uint64) (*chain.Transaction, err
 	return tx, err
 }
 
-func (a *Account) Renew(name string, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) Renew(name string, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
-	return a.txTransactor(func(q walletdb.Transactor) (*chain.Transaction, error) {
-		return a.sendRenewal(q, name, feeRate)
+	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
+		txb := new(TxBuilder)
+		if err := a.addRenewal(dTx, txb, name); err != nil {
+			return nil, err
+		}
+		return a.sendNameTx(dTx, txb, nil, feeRate, opts...)
 	})
 }
 
-func (a *Account) Revoke(name string, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) Revoke(name string, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
@@ -659,7 +1008,7 @@ func (a *Account) Revoke(name string, feeRate uint64) (*chain.Transaction, error
 			},
 		})
 
-		tx, err := a.fundTx(q, txb, feeRate)
+		tx, err := a.fundTx(q, txb, feeRate, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -673,10 +1022,15 @@ func (a *Account) Revoke(name string, feeRate uint64) (*chain.Transaction, error
 func (a *Account) TransferDutchAuctionListing(
 	name string,
 	feeRate uint64,
+	opts ...TxOption,
 ) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
+	if a.msRing != nil {
+		return nil, ErrMultisigUnsupported
+	}
+
 	state, err := a.requireNameState(name, "CLOSED")
 	if err != nil {
 		return nil, err
@@ -700,7 +1054,7 @@ func (a *Account) TransferDutchAuctionListing(
 			Covenant: chain.NewTransferCovenant(name, state.Info.Height, listingADdress),
 		})
 
-		tx, err := a.fundTx(dTx, txb, feeRate)
+		tx, err := a.fundTx(dTx, txb, feeRate, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -728,10 +1082,15 @@ func (a *Account) TransferDutchAuctionListing(
 func (a *Account) FinalizeDutchAuctionListing(
 	name string,
 	feeRate uint64,
+	opts ...TxOption,
 ) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
+	if a.msRing != nil {
+		return nil, ErrMultisigUnsupported
+	}
+
 	state, err := a.requireNameState(name, "CLOSED")
 	if err != nil {
 		return nil, err
@@ -770,7 +1129,7 @@ func (a *Account) FinalizeDutchAuctionListing(
 			),
 		})
 
-		tx, err := a.fundTx(dTx, txb, feeRate)
+		tx, err := a.fundTx(dTx, txb, feeRate, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -806,6 +1165,10 @@ func (a *Account) UpdateDutchAuctionListing(
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
+	if a.msRing != nil {
+		return nil, ErrMultisigUnsupported
+	}
+
 	var presigns *shakedex.DutchAuction
 	var err error
 	err = a.engine.Transaction(func(tx walletdb.Transactor) error {
@@ -865,10 +1228,15 @@ func (a *Account) FillDutchAuction(
 	bid,
 	auctionFee,
 	feeRate uint64,
+	opts ...TxOption,
 ) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
+	if a.msRing != nil {
+		return nil, ErrMultisigUnsupported
+	}
+
 	state, err := a.requireNameState(name, "CLOSED")
 	if err != nil {
 		return nil, err
@@ -965,28 +1333,24 @@ func (a *Account) FillDutchAuction(
 	}
 
 	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
-		tx, err := a.fundTx(dTx, txb, feeRate)
+		tx, err := a.fundTx(dTx, txb, feeRate, opts...)
 		if err != nil {
 			return nil, err
 		}
 
-		// reorder to preserve singlereverse, then resign
+		// reorder to preserve singlereverse, then resign. watch-only
+		// accounts have nothing to resign, but their inputs are signed
+		// later against the reordered outputs.
 		if len(tx.Outputs) > len(tmplTx.Outputs) {
 			tx.Outputs[len(tx.Outputs)-1], tx.Outputs[len(tx.Outputs)-2] =
 				tx.Outputs[len(tx.Outputs)-2], tx.Outputs[len(tx.Outputs)-1]
-
+		}
+		if len(tx.Outputs) > len(tmplTx.Outputs) && !a.watchOnly {
 			for i := 1; i < len(txb.Coins); i++ {
-				pk, err := a.ring.PrivateKey(txb.Coins[i].Derivation...)
+				newWitness, err := P2PKHWitness(a.ring, tx, i, txb.Coins[i])
 				if err != nil {
 					return nil, err
 				}
-
-				newWitness, err := txscript.P2PKHWitnessSignature(
-					tx,
-					i,
-					txb.Coins[i].Value,
-					pk,
-				)
 				tx.Witnesses[i] = newWitness
 			}
 		}
@@ -1001,10 +1365,15 @@ func (a *Account) FillDutchAuction(
 func (a *Account) FinalizeDutchAuction(
 	name string,
 	feeRate uint64,
+	opts ...TxOption,
 ) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
+	if a.msRing != nil {
+		return nil, ErrMultisigUnsupported
+	}
+
 	state, err := a.requireNameState(name, "CLOSED")
 	if err != nil {
 		return nil, err
@@ -1062,7 +1431,7 @@ func (a *Account) FinalizeDutchAuction(
 		}
 		txb.Witnesses = append(txb.Witnesses, wit)
 
-		tx, err := a.fundTx(dTx, txb, feeRate)
+		tx, err := a.fundTx(dTx, txb, feeRate, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -1073,10 +1442,14 @@ func (a *Account) FinalizeDutchAuction(
 	})
 }
 
-func (a *Account) TransferDutchAuctionCancel(name string, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) TransferDutchAuctionCancel(name string, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
+	if a.msRing != nil {
+		return nil, ErrMultisigUnsupported
+	}
+
 	state, err := a.requireNameState(name, "CLOSED")
 	if err != nil {
 		return nil, err
@@ -1101,17 +1474,29 @@ func (a *Account) TransferDutchAuctionCancel(name string, feeRate uint64) (*chai
 			Covenant: chain.NewTransferCovenant(name, state.Info.Height, destAddress),
 		})
 
-		privKey, err := a.ring.PrivateKey(coin.Derivation[0], coin.Derivation[1])
+		script, err := txscript.NewHIP1LockingScript(a.ring.PublicKey(coin.Derivation...).SerializeCompressed())
 		if err != nil {
 			return nil, err
 		}
-		wit, err := txscript.HIP1CancelWitnessSignature(txb.Build(), 0, coin.Value, privKey)
+		sig, err := SignInput(
+			a.ring,
+			txb.Build(),
+			0,
+			coin.AsChain(),
+			script,
+			txscript.SigHashAnyOneCanPay|txscript.SigHashSingle,
+		)
 		if err != nil {
 			return nil, err
 		}
-		txb.Witnesses = append(txb.Witnesses, wit)
+		txb.Witnesses = append(txb.Witnesses, &chain.Witness{
+			Items: [][]byte{
+				sig,
+				script,
+			},
+		})
 
-		tx, err := a.fundTx(dTx, txb, feeRate)
+		tx, err := a.fundTx(dTx, txb, feeRate, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -1122,10 +1507,14 @@ func (a *Account) TransferDutchAuctionCancel(name string, feeRate uint64) (*chai
 	})
 }
 
-func (a *Account) FinalizeDutchAuctionCancel(name string, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) FinalizeDutchAuctionCancel(name string, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	a.mtx.Lock()
 	defer a.mtx.Unlock()
 
+	if a.msRing != nil {
+		return nil, ErrMultisigUnsupported
+	}
+
 	state, err := a.requireNameState(name, "CLOSED")
 	if err != nil {
 		return nil, err
@@ -1179,7 +1568,7 @@ func (a *Account) FinalizeDutchAuctionCancel(name string, feeRate uint64) (*chai
 		}
 		txb.Witnesses = append(txb.Witnesses, wit)
 
-		tx, err := a.fundTx(dTx, txb, feeRate)
+		tx, err := a.fundTx(dTx, txb, feeRate, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -1200,12 +1589,8 @@ func (a *Account) Zap() error {
 }
 
 func (a *Account) Rescan(height int) error {
-	if a.bm.LastHeight() < height {
-		return errors.New("cannot rescan beyond the chain head")
-	}
-
-	if height < 0 {
-		return errors.New("cannot rescan to a negative height")
+	if err := a.checkRescanHeight(height); err != nil {
+		return err
 	}
 
 	a.tmb.Go(func() error {
@@ -1224,6 +1609,87 @@ func (a *Account) Rescan(height int) error {
 	return nil
 }
 
+// DiscoveryRescan rescans from height like Rescan, but first widens each
+// branch's lookahead past its gap limit. While a pass turns up addresses
+// beyond the previous depth, the lookahead is doubled and the scan repeated.
+// This finds funds sent to addresses far past the gap limit, e.g. by another
+// wallet that handed out many addresses before they were used.
+func (a *Account) DiscoveryRescan(height int) error {
+	if err := a.checkRescanHeight(height); err != nil {
+		return err
+	}
+
+	a.tmb.Go(func() error {
+		a.mtx.Lock()
+		defer a.mtx.Unlock()
+		if err := a.discover(height); err != nil {
+			a.lgr.Error("error running discovery rescan", "err", err)
+		}
+		return nil
+	})
+
+	return nil
+}
+
+func (a *Account) checkRescanHeight(height int) error {
+	if a.bm.LastHeight() < height {
+		return errors.New("cannot rescan beyond the chain head")
+	}
+
+	if height < 0 {
+		return errors.New("cannot rescan to a negative height")
+	}
+	return nil
+}
+
+func (a *Account) discover(height int) error {
+	mgrs := []*AddressManager{a.recvMgr, a.changeMgr, a.dutchMgr}
+	multiple := uint32(2)
+	for {
+		depths := make([]uint32, len(mgrs))
+		err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+			for i, mgr := range mgrs {
+				depths[i] = mgr.Depth()
+				size := mgr.GapLimit() * multiple
+				if size > MaxGapLimit {
+					size = MaxGapLimit
+				}
+				if size < mgr.GapLimit() {
+					size = mgr.GapLimit()
+				}
+				if err := mgr.ExtendLookahead(tx, size); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		a.lgr.Info("running discovery pass", "height", height, "lookahead_multiple", multiple)
+		if err := a.rollback(height); err != nil {
+			return err
+		}
+		if err := a.rescan(a.bm.LastHeight()); err != nil {
+			return err
+		}
+
+		var found bool
+		for i, mgr := range mgrs {
+			found = found || mgr.Depth() > depths[i]
+		}
+		if !found {
+			a.lgr.Info("discovery complete", "height", height)
+			return nil
+		}
+		multiple *= 2
+		if multiple > MaxGapLimit {
+			multiple = MaxGapLimit
+		}
+	}
+}
+
 func (a *Account) SignMessage(addr *chain.Address, msg []byte) (*btcec.Signature, error) {
 	var dbAddr *walletdb.Address
 	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
@@ -1245,15 +1711,11 @@ func (a *Account) SignMessage(addr *chain.Address, msg []byte) (*btcec.Signature
 		return nil, errors.New("cannot sign messages with script hash addresses")
 	}
 
-	key, err := a.ring.PrivateKey(dbAddr.Derivation...)
-	if err != nil {
-		return nil, err
-	}
-
-	h, _ := blake2b.New256(nil)
-	h.Write([]byte(chain.SignMessageMagic))
-	h.Write(msg)
-	return key.Sign(h.Sum(nil))
+	return a.ring.Sign(&SignRequest{
+		SigHash:    chain.MessageHash(msg),
+		Derivation: dbAddr.Derivation,
+		Message:    msg,
+	})
 }
 
 func (a *Account) SignMessageWithName(name string, msg []byte) (*btcec.Signature, error) {
@@ -1282,6 +1744,120 @@ func (a *Account) SignMessageWithName(name string, msg []byte) (*btcec.Signature
 	return a.SignMessage(dbCoin.Address, msg)
 }
 
+func (a *Account) PartialTx(hash gcrypto.Hash) (*PartialTx, error) {
+	var ptx *PartialTx
+	err := a.engine.Transaction(func(q walletdb.Transactor) error {
+		var raw []byte
+		dbTx, err := walletdb.GetTransactionByOutpoint(q, a.id, hash)
+		if errors.Is(err, sql.ErrNoRows) {
+			replacement, err := walletdb.GetPendingReplacement(q, a.id, hash.String())
+			if errors.Is(err, sql.ErrNoRows) {
+				return errors.New("transaction not found")
+			}
+			if err != nil {
+				return err
+			}
+			raw = replacement.Raw
+		} else if err != nil {
+			return err
+		} else if dbTx.BlockHeight != -1 {
+			return errors.New("transaction is already confirmed")
+		} else {
+			raw = dbTx.Raw
+		}
+
+		tx := new(chain.Transaction)
+		if _, err := tx.ReadFrom(bytes.NewReader(raw)); err != nil {
+			return err
+		}
+
+		coins := make([]*chain.Coin, len(tx.Inputs))
+		for i, input := range tx.Inputs {
+			coin, err := walletdb.GetCoinByPrevout(q, a.id, input.Prevout)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			coins[i] = coin.AsChain()
+		}
+
+		ptx = NewPartialTx(tx, coins)
+		return nil
+	})
+	return ptx, err
+}
+
+func (a *Account) SignPartialTx(ptx *PartialTx) (int, error) {
+	if a.watchOnly {
+		return 0, errors.New("cannot sign with a watch-only account")
+	}
+
+	signed, err := ptx.Sign(a.ring)
+	if err != nil {
+		return signed, err
+	}
+	if signed == 0 {
+		return 0, errors.New("no inputs could be signed by this account")
+	}
+	return signed, nil
+}
+
+func (a *Account) FinalizePartialTx(ptx *PartialTx) (*chain.Transaction, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if !ptx.Complete() {
+		return nil, errors.New("partial transaction is missing signatures")
+	}
+	ptx.Finalize()
+	if err := ptx.Verify(); err != nil {
+		return nil, err
+	}
+
+	tx := ptx.Tx
+	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
+		replacement, err := walletdb.GetPendingReplacement(dTx, a.id, tx.IDHex())
+		if err == nil {
+			parentHash, err := hex.DecodeString(replacement.ReplacesHash)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			parent, err := walletdb.GetTransactionByOutpoint(dTx, a.id, parentHash)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			if err != nil || parent.BlockHeight != -1 || parent.Dropped {
+				return nil, errors.New("the transaction being replaced is no longer pending")
+			}
+			if err := a.commitReplacement(dTx, replacement.ReplacesHash, tx); err != nil {
+				return nil, err
+			}
+			return tx, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		_, err = walletdb.GetTransactionByOutpoint(dTx, a.id, tx.ID())
+		if errors.Is(err, sql.ErrNoRows) {
+			if err := a.recordTx(dTx, tx); err != nil {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		} else if err := walletdb.UpdateTransactionRaw(dTx, a.id, tx.IDHex(), tx.Bytes()); err != nil {
+			return nil, err
+		}
+
+		if err := a.broadcastTx(tx); err != nil {
+			return nil, err
+		}
+		return tx, nil
+	})
+}
+
 func (a *Account) UnspentBids(count, offset int) ([]*UnspentBid, error) {
 	var unspents []*walletdb.UnspentBid
 	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
@@ -1395,7 +1971,13 @@ func (a *Account) UnspentReveals(count, offset int) ([]*UnspentReveal, error) {
 func (a *Account) rollback(height int) error {
 	a.lgr.Info("rolling back account", "height", height)
 
+	var snap *eventSnapshot
 	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		snap, err = a.snapshotEvents(tx)
+		if err != nil {
+			return err
+		}
 		return walletdb.Rollback(tx, a.id, height)
 	})
 	if err != nil {
@@ -1403,6 +1985,21 @@ func (a *Account) rollback(height int) error {
 	}
 
 	a.rescanHeight = height
+
+	var events []*Event
+	err = a.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		events, err = a.stateEvents(tx, snap)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	a.publish(&Event{
+		Type: EventRollback,
+		Data: &RollbackEvent{Height: height},
+	})
+	a.publish(events...)
 	return nil
 }
 
@@ -1455,6 +2052,13 @@ func (a *Account) rescan(chainHeight int) error {
 				"chain_height",
 				chainHeight,
 			)
+			a.publish(&Event{
+				Type: EventRescanProgress,
+				Data: &RescanProgressEvent{
+					Height:      a.rescanHeight,
+					ChainHeight: chainHeight,
+				},
+			})
 		}
 	}
 
@@ -1466,6 +2070,15 @@ func (a *Account) rescan(chainHeight int) error {
 	}
 
 	a.lgr.Info("scan complete", "height", chainHeight)
+	if j > BlockFetchConcurrency {
+		a.publish(&Event{
+			Type: EventRescanProgress,
+			Data: &RescanProgressEvent{
+				Height:      chainHeight,
+				ChainHeight: chainHeight,
+			},
+		})
+	}
 	return nil
 }
 
@@ -1507,9 +2120,18 @@ check:
 
 	var spends int
 	var coins int
+	var events []*Event
+	var queuedWebhooks bool
 	err := a.engine.Transaction(func(dTx walletdb.Transactor) error {
+		snap, err := a.snapshotEvents(dTx)
+		if err != nil {
+			return err
+		}
+		incoming := make(map[string]bool)
+
 		for txIdx, tx := range block.Transactions {
 			var shouldIndexTx bool
+			var spent bool
 			coinbase := tx.Inputs[0].Prevout.Hash.IsZero()
 
 			for inIdx, input := range tx.Inputs {
@@ -1525,6 +2147,7 @@ check:
 					continue
 				}
 				shouldIndexTx = true
+				spent = true
 				spends++
 			}
 
@@ -1570,6 +2193,23 @@ check:
 				continue
 			}
 
+			if err := a.scanRenewals(dTx, tx, height); err != nil {
+				return err
+			}
+
+			if snap != nil {
+				evt, err := a.txEvent(dTx, tx, height)
+				if err != nil {
+					return err
+				}
+				if evt != nil {
+					events = append(events, evt)
+					if evt.Type == EventTransaction && !spent {
+						incoming[tx.IDHex()] = true
+					}
+				}
+			}
+
 			dbTx := &walletdb.Transaction{
 				Hash:        tx.IDHex(),
 				Idx:         txIdx,
@@ -1583,11 +2223,29 @@ check:
 			}
 		}
 
-		return walletdb.UpdateRescanHeight(dTx, a.id, height)
+		if err := walletdb.UpdateRescanHeight(dTx, a.id, height); err != nil {
+			return err
+		}
+
+		stateEvents, err := a.stateEvents(dTx, snap)
+		if err != nil {
+			return err
+		}
+		events = append(events, stateEvents...)
+
+		if snap == nil || !snap.webhooks {
+			return nil
+		}
+		queuedWebhooks = true
+		return a.queueBlockWebhooks(dTx, height, events, incoming)
 	})
 	if err != nil {
 		return err
 	}
+	a.publish(events...)
+	if queuedWebhooks {
+		a.wakeWebhooks()
+	}
 
 	if spends > 0 || coins > 0 {
 		a.lgr.Info(
@@ -1747,9 +2405,15 @@ func (a *Account) scanCovenant(q walletdb.Transactor, tx *chain.Transaction, out
 		entry.Type = walletdb.NameActionRegister
 		entry.ParentTxHash = input.Prevout.Hash.String()
 		entry.ParentOutIdx = input.Prevout.Index
-		if err := walletdb.UpsertNameHash(q, a.id, entry.NameHash, walletdb.NameStatusOwned); err != nil {
-			return err
-		}
+		if err := walletdb.UpsertNameHash(q, a.id, entry.NameHash, walletdb.NameStatusOwned); err != nil {
+			return err
+		}
+	case chain.CovenantRenew:
+		input := tx.Inputs[outIdx]
+		entry.NameHash = out.Covenant.Items[0]
+		entry.Type = walletdb.NameActionRenew
+		entry.ParentTxHash = input.Prevout.Hash.String()
+		entry.ParentOutIdx = input.Prevout.Index
 	case chain.CovenantRevoke:
 		input := tx.Inputs[outIdx]
 		entry.NameHash = out.Covenant.Items[0]
@@ -1772,6 +2436,67 @@ func (a *Account) scanCovenant(q walletdb.Transactor, tx *chain.Transaction, out
 	return errors.Wrap(walletdb.UpdateNameHistory(q, entry), "error saving name history")
 }
 
+// scanRenewals records the renewal height of any names owned by the account
+// that were registered, renewed, or finalized in tx.
+func (a *Account) scanRenewals(q walletdb.Transactor, tx *chain.Transaction, height int) error {
+	for _, out := range tx.Outputs {
+		switch out.Covenant.Type {
+		case chain.CovenantRegister, chain.CovenantRenew, chain.CovenantFinalize:
+		default:
+			continue
+		}
+		if err := walletdb.UpdateNameRenewalHeight(q, a.id, out.Covenant.Items[0], height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshRenewalHeights asks the node for the renewal heights of owned
+// names the wallet hasn't indexed one for, such as names owned before
+// renewal heights were tracked. Their local history can't be used since it
+// may be missing earlier renewals.
+func (a *Account) refreshRenewalHeights() error {
+	var missing []*walletdb.Name
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		missing, err = walletdb.GetNamesWithoutRenewalHeight(tx, a.id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(missing))
+	for i, name := range missing {
+		names[i] = name.Name
+	}
+	infos, err := a.client.BatchGetNameInfo(names)
+	if err != nil {
+		return err
+	}
+
+	return a.engine.Transaction(func(tx walletdb.Transactor) error {
+		for i, info := range infos {
+			if info.Error != nil {
+				a.lgr.Warning("error getting name info for renewal height", "name", names[i], "err", info.Error)
+				continue
+			}
+			if info.Info.Info == nil || info.Info.Info.Renewal == 0 {
+				continue
+			}
+			err := walletdb.UpdateNameRenewalHeight(tx, a.id, info.Info.Info.NameHash, info.Info.Info.Renewal)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (a *Account) scanTransfer(q walletdb.Transactor, tx *chain.Transaction, outIdx int) (bool, error) {
 	output := tx.Outputs[outIdx]
 	transfereeAddr := &chain.Address{
@@ -1960,7 +2685,7 @@ func (a *Account) scanFinalize(q walletdb.Transactor, tx *chain.Transaction, out
 		if addr == nil {
 			return false, nil
 		}
-		return a.scanIncomingFinalize(q, tx, outIdx)
+		return a.scanIncomingFinalize(q, addr, tx, outIdx)
 	}
 
 	if !a.outpointBloom.Test(input.Prevout) {
@@ -1970,7 +2695,7 @@ func (a *Account) scanFinalize(q walletdb.Transactor, tx *chain.Transaction, out
 	return false, a.scanOutgoingFinalize(q, tx, outIdx)
 }
 
-func (a *Account) scanIncomingFinalize(q walletdb.Transactor, tx *chain.Transaction, outIdx int) (bool, error) {
+func (a *Account) scanIncomingFinalize(q walletdb.Transactor, addr *walletdb.Address, tx *chain.Transaction, outIdx int) (bool, error) {
 	input := tx.Inputs[outIdx]
 	output := tx.Outputs[outIdx]
 	outpoint := &chain.Outpoint{
@@ -2001,7 +2726,7 @@ func (a *Account) scanIncomingFinalize(q walletdb.Transactor, tx *chain.Transact
 				return false, nil
 			}
 		} else if err == nil {
-			if output.Address.IsScriptHash() {
+			if addr.Derivation[0] == shakedex.AddressBranch {
 				coinType = walletdb.CoinTypeDutchAuctionListing
 				entry.Type = walletdb.NameActionFinalizeDutchAuctionListing
 			} else {
@@ -2012,14 +2737,12 @@ func (a *Account) scanIncomingFinalize(q walletdb.Transactor, tx *chain.Transact
 			return false, err
 		}
 	} else {
-		if output.Address.IsScriptHash() {
+		// multisig accounts receive names on script addresses too,
+		// so only our HIP-1 branch counts as a listing
+		if addr.Derivation[0] == shakedex.AddressBranch {
 			entry.Type = walletdb.NameActionFinalizeDutchAuctionListing
 			coinType = walletdb.CoinTypeDutchAuctionListing
 		} else {
-			addr, err := a.checkAddrInDB(q, output.Address)
-			if err != nil {
-				return false, err
-			}
 			if err := a.updateOutputBloom(q, addr, tx, outIdx); err != nil {
 				return false, err
 			}
@@ -2091,17 +2814,19 @@ func (a *Account) checkAddrInDB(q walletdb.Querier, addr *chain.Address) (*walle
 	return dbAddr, errors.Wrap(err, "error getting address")
 }
 
-func (a *Account) send(dTx walletdb.Transactor, addr *chain.Address, value uint64, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) send(dTx walletdb.Transactor, recipients []*Recipient, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	txb := new(TxBuilder)
-	txb.AddOutput(&chain.Output{
-		Value:    value,
-		Address:  addr,
-		Covenant: chain.EmptyCovenant,
-	})
+	for _, recip := range recipients {
+		txb.AddOutput(&chain.Output{
+			Value:    recip.Value,
+			Address:  recip.Address,
+			Covenant: chain.EmptyCovenant,
+		})
+	}
 
 	var tx *chain.Transaction
 
-	tx, err := a.fundTx(dTx, txb, feeRate)
+	tx, err := a.fundTx(dTx, txb, feeRate, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -2111,10 +2836,113 @@ func (a *Account) send(dTx walletdb.Transactor, addr *chain.Address, value uint6
 	return tx, nil
 }
 
-func (a *Account) sendReveals(dTx walletdb.Transactor, bids []*walletdb.RevealableBid, name string, nsHeight int, feeRate uint64) (*chain.Transaction, error) {
-	var tx *chain.Transaction
-	var err error
-	txb := new(TxBuilder)
+func (a *Account) addOpen(txb *TxBuilder, name string) error {
+	if !chain.IsNameValid(name) {
+		return errors.New("invalid name")
+	}
+
+	if chain.IsNameReserved(a.network, a.rescanHeight, name) {
+		return errors.New("name is reserved")
+	}
+
+	if !chain.HasRollout(a.network, a.rescanHeight, name) {
+		return errors.New("name not rolled out yet")
+	}
+
+	state, err := a.client.GetNameInfo(name)
+	if err != nil {
+		return err
+	}
+	if state.Info != nil {
+		return errors.New("name is not openable")
+	}
+
+	txb.AddOutput(&chain.Output{
+		Value:   0,
+		Address: a.recvMgr.Address(),
+		Covenant: &chain.Covenant{
+			Type: chain.CovenantOpen,
+			Items: [][]byte{
+				chain.HashName(name),
+				bio.Uint32LE(0),
+				[]byte(name),
+			},
+		},
+	})
+	return nil
+}
+
+// addBid returns the bid's name history entry, which must be saved with
+// the funded transaction's hash since it's the only record of the
+// bid's value.
+func (a *Account) addBid(txb *TxBuilder, name string, value, lockup uint64) (*walletdb.NameHistory, error) {
+	if !chain.IsNameValid(name) {
+		return nil, errors.New("invalid name")
+	}
+
+	if chain.IsNameReserved(a.network, a.rescanHeight, name) {
+		return nil, errors.New("name is reserved")
+	}
+
+	if !chain.HasRollout(a.network, a.rescanHeight, name) {
+		return nil, errors.New("name not rolled out yet")
+	}
+
+	if value > lockup {
+		return nil, errors.New("value exceeds lockup")
+	}
+
+	state, err := a.requireNameState(name, "BIDDING")
+	if err != nil {
+		return nil, err
+	}
+
+	recvAddr := a.recvMgr.Address()
+	blind := chain.CreateBlind(a.ring.PublicEK(), name, recvAddr, value)
+	txb.AddOutput(&chain.Output{
+		Value:   lockup,
+		Address: recvAddr,
+		Covenant: &chain.Covenant{
+			Type: chain.CovenantBid,
+			Items: [][]byte{
+				chain.HashName(name),
+				bio.Uint32LE(uint32(state.Info.Height)),
+				[]byte(name),
+				blind,
+			},
+		},
+	})
+
+	return &walletdb.NameHistory{
+		AccountID: a.id,
+		Name:      name,
+		Type:      walletdb.NameActionBid,
+		Outpoint: &chain.Outpoint{
+			Index: uint32(len(txb.Outputs) - 1),
+		},
+		Value:    lockup,
+		BidValue: value,
+	}, nil
+}
+
+func (a *Account) addReveals(q walletdb.Transactor, txb *TxBuilder, name string) error {
+	if !chain.IsNameValid(name) {
+		return errors.New("invalid name")
+	}
+
+	state, err := a.requireNameState(name, "REVEAL")
+	if err != nil {
+		return err
+	}
+
+	bids, err := walletdb.GetRevealableBids(q, a.id, name, a.network, state.Info.Stats.RevealPeriodStart)
+	if err != nil {
+		return err
+	}
+	if len(bids) == 0 {
+		return errors.New("no bids to reveal")
+	}
+
 	for _, bid := range bids {
 		nonce := chain.GenerateNonce(a.ring.PublicEK(), name, bid.Coin.Address, bid.Value)
 		txb.AddCoin(bid.Coin.AsChain())
@@ -2125,59 +2953,74 @@ func (a *Account) sendReveals(dTx walletdb.Transactor, bids []*walletdb.Revealab
 				Type: chain.CovenantReveal,
 				Items: [][]byte{
 					chain.HashName(name),
-					bio.Uint32LE(uint32(nsHeight)),
+					bio.Uint32LE(uint32(state.Info.Height)),
 					nonce,
 				},
 			},
 		})
 	}
+	return nil
+}
+
+func (a *Account) addRedeems(q walletdb.Transactor, txb *TxBuilder, name string) error {
+	if !chain.IsNameValid(name) {
+		return errors.New("invalid name")
+	}
 
-	tx, err = a.fundTx(dTx, txb, feeRate)
+	state, err := a.requireNameState(name, "CLOSED")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return tx, a.sendTx(dTx, tx)
-}
+	winner := state.Info.Owner
+	winnerOutpoint := &chain.Outpoint{
+		Hash:  winner.Hash,
+		Index: winner.Index,
+	}
 
-func (a *Account) sendRedeems(dTx walletdb.Transactor, coins []*chain.Coin, name string, nsHeight int, feeRate uint64) (*chain.Transaction, error) {
-	var tx *chain.Transaction
-	var err error
-	txb := new(TxBuilder)
-	for _, coin := range coins {
-		txb.AddCoin(coin)
+	coins, err := walletdb.GetRedeemableReveals(q, a.id, name)
+	if err != nil {
+		return err
+	}
+	if len(coins) == 0 {
+		return errors.New("no reveals to redeem")
+	}
+
+	var redeemed int
+	for _, rev := range coins {
+		if rev.Prevout.Equal(winnerOutpoint) {
+			continue
+		}
+		txb.AddCoin(rev.AsChain())
 		txb.AddOutput(&chain.Output{
-			Value:   coin.Value,
-			Address: coin.Address,
+			Value:   rev.Value,
+			Address: rev.Address,
 			Covenant: &chain.Covenant{
 				Type: chain.CovenantRedeem,
 				Items: [][]byte{
 					chain.HashName(name),
-					bio.Uint32LE(uint32(nsHeight)),
+					bio.Uint32LE(uint32(state.Info.Height)),
 				},
 			},
 		})
+		redeemed++
 	}
 
-	tx, err = a.fundTx(dTx, txb, feeRate)
-	if err != nil {
-		return nil, err
-	}
-	if err := a.sendTx(dTx, tx); err != nil {
-		return nil, err
+	if redeemed == 0 {
+		return errors.New("no losing reveals")
 	}
-	return tx, err
+	return nil
 }
 
-func (a *Account) sendUpdate(q walletdb.Transactor, name string, resource *chain.Resource, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) addUpdate(q walletdb.Transactor, txb *TxBuilder, name string, resource *chain.Resource) error {
 	hasName, err := walletdb.HasOwnedName(q, a.id, name)
 	if err != nil {
-		return nil, errors.Wrap(err, "error checking for id")
+		return errors.Wrap(err, "error checking for id")
 	}
 
 	state, err := a.requireNameState(name, "CLOSED")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	coin, err := walletdb.GetCoinByPrevout(q, a.id, &chain.Outpoint{
@@ -2185,13 +3028,15 @@ func (a *Account) sendUpdate(q walletdb.Transactor, name string, resource *chain
 		Index: state.Info.Owner.Index,
 	})
 	if errors.Is(err, sql.ErrNoRows) {
-		return nil, errors.New("you do not own this name")
+		return errors.New("you do not own this name")
+	}
+	if err != nil {
+		return err
 	}
 	if coin.Spent {
-		return nil, errors.New("name is already registered")
+		return errors.New("name is already registered")
 	}
 
-	txb := new(TxBuilder)
 	txb.AddCoin(coin.AsChain())
 	if hasName {
 		txb.AddOutput(&chain.Output{
@@ -2199,54 +3044,46 @@ func (a *Account) sendUpdate(q walletdb.Transactor, name string, resource *chain
 			Address:  coin.Address,
 			Covenant: chain.NewUpdateCovenant(name, state.Info.Height, resource),
 		})
-	} else {
-		renewalBlock, err := a.client.GetRenewalBlock(a.network, state.Info.Height)
-		if err != nil {
-			return nil, err
-		}
-
-		txb.AddOutput(&chain.Output{
-			Value:    uint64(state.Info.Value),
-			Address:  coin.Address,
-			Covenant: chain.NewRegisterCovenant(name, state.Info.Height, renewalBlock.Hash(), resource),
-		})
+		return nil
 	}
 
-	tx, err := a.fundTx(q, txb, feeRate)
+	renewalBlock, err := a.client.GetRenewalBlock(a.network, state.Info.Height)
 	if err != nil {
-		return nil, err
-	}
-	if err := a.sendTx(q, tx); err != nil {
-		return nil, err
+		return err
 	}
-	return tx, nil
+
+	txb.AddOutput(&chain.Output{
+		Value:    uint64(state.Info.Value),
+		Address:  coin.Address,
+		Covenant: chain.NewRegisterCovenant(name, state.Info.Height, renewalBlock.Hash(), resource),
+	})
+	return nil
 }
 
-func (a *Account) sendRenewal(q walletdb.Transactor, name string, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) addRenewal(q walletdb.Transactor, txb *TxBuilder, name string) error {
 	if !chain.IsNameValid(name) {
-		return nil, errors.New("invalid name")
+		return errors.New("invalid name")
 	}
 
 	state, err := a.requireNameState(name, "CLOSED")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	coin, err := walletdb.GetOwnedNameCoin(q, a.id, name)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if a.rescanHeight < state.Info.Renewal+a.network.TreeInterval {
-		return nil, errors.New("must wait to renew")
+		return errors.New("must wait to renew")
 	}
 
 	renewalBlock, err := a.client.GetRenewalBlock(a.network, state.Info.Height)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	txb := new(TxBuilder)
 	txb.AddCoin(coin.AsChain())
 	txb.AddOutput(&chain.Output{
 		Value:   coin.Value,
@@ -2260,39 +3097,58 @@ func (a *Account) sendRenewal(q walletdb.Transactor, name string, feeRate uint64
 			},
 		},
 	})
+	return nil
+}
 
-	tx, err := a.fundTx(q, txb, feeRate)
+// sendNameTx funds and broadcasts a transaction built from name actions,
+// saving any bid entries once the transaction's hash is known.
+func (a *Account) sendNameTx(dTx walletdb.Transactor, txb *TxBuilder, bids []*walletdb.NameHistory, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
+	// check before funding so that nothing is signed for a transaction
+	// that's too large, then again once funding inputs are added
+	if a.estimateTxSize(txb.Coins, txb.Outputs)*chain.WitnessScaleFactor > chain.MaxTxWeight {
+		return nil, errors.New("transaction is too large")
+	}
+	tx, err := a.fundTx(dTx, txb, feeRate, opts...)
 	if err != nil {
 		return nil, err
 	}
-	if err := a.sendTx(q, tx); err != nil {
+	if a.estimateTxSize(txb.Coins, tx.Outputs)*chain.WitnessScaleFactor > chain.MaxTxWeight {
+		return nil, errors.New("transaction is too large")
+	}
+
+	for _, entry := range bids {
+		entry.Outpoint.Hash = tx.ID()
+		if err := walletdb.UpdateNameHistory(dTx, entry); err != nil {
+			return nil, err
+		}
+	}
+	if err := a.sendTx(dTx, tx); err != nil {
 		return nil, err
 	}
 	return tx, nil
 }
 
-func (a *Account) fundTx(q walletdb.Querier, txb *TxBuilder, feeRate uint64) (*chain.Transaction, error) {
+func (a *Account) fundTx(q walletdb.Querier, txb *TxBuilder, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
 	if feeRate == 0 {
-		smartFee, err := a.client.EstimateSmartFee(10)
-		if err != nil {
-			a.lgr.Warning("error estimating smart fee", "err", err)
-			feeRate = 100
-		} else if smartFee < 100 {
-			a.lgr.Warning("smart fee less than minimum")
-			feeRate = 100
-		} else {
-			feeRate = smartFee
-		}
+		feeRate = a.estimateFeeRate()
 	}
 
-	dbCoins, err := walletdb.GetFundingCoins(q, a.id, a.network, a.rescanHeight)
+	txOpts := newTxOptions(opts)
+	coins, err := a.fundingCoins(q, txb, txOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	coins := make([]*chain.Coin, len(dbCoins))
-	for i := 0; i < len(dbCoins); i++ {
-		coins[i] = dbCoins[i].AsChain()
+	selector, err := NewCoinSelector(txOpts.coinSelection)
+	if err != nil {
+		return nil, err
+	}
+	txb.CoinSelector = selector
+	if txOpts.replaceable {
+		txb.SetReplaceable()
+	}
+	if a.msRing != nil {
+		txb.WitnessSize = a.msRing.WitnessSize
 	}
 
 	changeAddr := a.changeMgr.Address()
@@ -2300,6 +3156,19 @@ func (a *Account) fundTx(q walletdb.Querier, txb *TxBuilder, feeRate uint64) (*c
 		return nil, err
 	}
 
+	if a.msRing != nil {
+		if err := txb.SignMultisig(a.msRing, !a.watchOnly); err != nil {
+			return nil, err
+		}
+		tx := txb.Build()
+		FinalizeMultisigWitnesses(tx)
+		return tx, nil
+	}
+
+	if a.watchOnly {
+		return txb.Build(), nil
+	}
+
 	if err := txb.Sign(a.ring); err != nil {
 		return nil, err
 	}
@@ -2307,7 +3176,53 @@ func (a *Account) fundTx(q walletdb.Querier, txb *TxBuilder, feeRate uint64) (*c
 	return txb.Build(), nil
 }
 
+func (a *Account) estimateFeeRate() uint64 {
+	smartFee, err := a.client.EstimateSmartFee(10)
+	if err != nil {
+		a.lgr.Warning("error estimating smart fee", "err", err)
+		return MinRelayFeeRate
+	}
+	if smartFee < MinRelayFeeRate {
+		a.lgr.Warning("smart fee less than minimum")
+		return MinRelayFeeRate
+	}
+	return smartFee
+}
+
 func (a *Account) sendTx(dTx walletdb.Transactor, tx *chain.Transaction) error {
+	snap, err := a.snapshotEvents(dTx)
+	if err != nil {
+		return err
+	}
+
+	if err := a.recordTx(dTx, tx); err != nil {
+		return err
+	}
+
+	// watch-only and multisig transactions are broadcast once
+	// they are signed elsewhere and finalized via FinalizePartialTx
+	if !IsTxComplete(tx) {
+		a.lgr.Info("created transaction awaiting signatures", "hash", tx.IDHex())
+	} else if err := a.broadcastTx(tx); err != nil {
+		return err
+	}
+
+	events, err := a.stateEvents(dTx, snap)
+	if err != nil {
+		return err
+	}
+	a.queueEvents(&Event{
+		Type: EventTransaction,
+		Data: &TransactionEvent{
+			Hash:   tx.IDHex(),
+			Height: -1,
+		},
+	})
+	a.queueEvents(events...)
+	return nil
+}
+
+func (a *Account) recordTx(dTx walletdb.Transactor, tx *chain.Transaction) error {
 	hashStr := tx.IDHex()
 	_, err := walletdb.UpsertTransaction(dTx, a.id, &walletdb.Transaction{
 		Hash:        hashStr,
@@ -2346,6 +3261,11 @@ func (a *Account) sendTx(dTx walletdb.Transactor, tx *chain.Transaction) error {
 		}
 	}
 
+	return nil
+}
+
+func (a *Account) broadcastTx(tx *chain.Transaction) error {
+	hashStr := tx.IDHex()
 	if _, err := a.client.SendRawTransaction(tx.Bytes()); err != nil {
 		return errors.Wrap(err, "error broadcasting transaction")
 	}
@@ -2371,8 +3291,11 @@ func (a *Account) sendTx(dTx walletdb.Transactor, tx *chain.Transaction) error {
 	return errors.New("transaction did not appear in mempool")
 }
 
+// txTransactor runs cb in a database transaction and publishes the events
+// it queued once the transaction commits. Callers must hold mtx.
 func (a *Account) txTransactor(cb func(dTx walletdb.Transactor) (*chain.Transaction, error)) (*chain.Transaction, error) {
 	var tx *chain.Transaction
+	a.queuedEvents = nil
 	err := a.engine.Transaction(func(dTx walletdb.Transactor) error {
 		inTx, err := cb(dTx)
 		if err != nil {
@@ -2381,7 +3304,13 @@ func (a *Account) txTransactor(cb func(dTx walletdb.Transactor) (*chain.Transact
 		tx = inTx
 		return nil
 	})
-	return tx, err
+	events := a.queuedEvents
+	a.queuedEvents = nil
+	if err != nil {
+		return tx, err
+	}
+	a.publish(events...)
+	return tx, nil
 }
 
 func (a *Account) requireNameState(name string, expState string) (*client.NameInfoRes, error) {
@@ -0,0 +1,79 @@
+package wallet
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/tomb.v2"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOnNewBlocksWaitsForScan(t *testing.T) {
+	acc := &Account{
+		tmb: new(tomb.Tomb),
+	}
+	var enabled int32 = 1
+	checked := make(chan struct{}, 10)
+	heights := make(chan int, 10)
+	acc.onNewBlocks(func() bool {
+		checked <- struct{}{}
+		return atomic.LoadInt32(&enabled) == 1
+	}, func(height int) {
+		heights <- height
+	})
+	defer func() {
+		acc.tmb.Kill(nil)
+		require.NoError(t, acc.tmb.Wait())
+	}()
+
+	next := func() int {
+		select {
+		case height := <-heights:
+			return height
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for callback")
+			return 0
+		}
+	}
+
+	acc.notifyScanned(5)
+	require.Equal(t, 5, next())
+
+	// heights that were already handled or that went backwards in a
+	// reorg are skipped
+	acc.notifyScanned(5)
+	acc.notifyScanned(4)
+	acc.notifyScanned(7)
+	require.Equal(t, 7, next())
+
+	// heights scanned while disabled are skipped
+	for len(checked) > 0 {
+		<-checked
+	}
+	atomic.StoreInt32(&enabled, 0)
+	acc.notifyScanned(8)
+	<-checked
+	atomic.StoreInt32(&enabled, 1)
+	acc.notifyScanned(9)
+	require.Equal(t, 9, next())
+}
+
+func TestSendManyRejectsOverflow(t *testing.T) {
+	acc := new(Account)
+	addr := NewAccountKeyring(nil, testAccountKey(0).Neuter(), chain.NetworkRegtest).Address(chain.ReceiveBranch, 0)
+
+	// the sum wraps around to 1 without the check
+	_, err := acc.SendMany([]*Recipient{
+		{Address: addr, Value: math.MaxUint64},
+		{Address: addr, Value: 2},
+	}, 100)
+	require.Equal(t, ErrMaxMoney, err)
+
+	_, err = acc.SendMany([]*Recipient{
+		{Address: addr, Value: chain.MaxMoney},
+		{Address: addr, Value: 1},
+	}, 100)
+	require.Equal(t, ErrMaxMoney, err)
+}
@@ -15,6 +15,11 @@ var (
 	AddrLookahead = uint32(1000)
 )
 
+const (
+	DutchAuctionLookahead = uint32(10)
+	MaxGapLimit           = uint32(10000)
+)
+
 type BloomSaver func(dTx walletdb.Transactor, newFilter []byte) error
 
 type AddressBloom struct {
@@ -143,6 +148,10 @@ func (a *AddressManager) Lookahead() uint32 {
 	return uint32(a.lookIdx)
 }
 
+func (a *AddressManager) GapLimit() uint32 {
+	return uint32(a.lookSize)
+}
+
 func (a *AddressManager) Address() *chain.Address {
 	a.mtx.RLock()
 	defer a.mtx.RUnlock()
@@ -178,8 +187,19 @@ func (a *AddressManager) setAddressIdx(dTx walletdb.Transactor, newIdx uint32) e
 		a.currIdx = newIdx + 1
 	}
 
-	newLookTip := int64(newIdx) + a.lookSize
+	return a.extendLookahead(dTx, int64(newIdx)+a.lookSize)
+}
 
+// ExtendLookahead derives and watches addresses up to size past the current
+// depth, even if that's further out than the gap limit.
+func (a *AddressManager) ExtendLookahead(dTx walletdb.Transactor, size uint32) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.ensureInitialized()
+	return a.extendLookahead(dTx, int64(a.currIdx)+int64(size))
+}
+
+func (a *AddressManager) extendLookahead(dTx walletdb.Transactor, newLookTip int64) error {
 	if a.lookIdx > newLookTip {
 		return nil
 	}
@@ -201,6 +221,13 @@ func (a *AddressManager) setAddressIdx(dTx walletdb.Transactor, newIdx uint32) e
 	return nil
 }
 
+func gapLimitOrDefault(limit, def uint32) uint32 {
+	if limit == 0 {
+		return def
+	}
+	return limit
+}
+
 func (a *AddressManager) ensureInitialized() {
 	if a.lookIdx < 0 {
 		panic("address manager is not initialized")
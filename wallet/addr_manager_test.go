@@ -56,6 +56,30 @@ func TestAddrManager(t *testing.T) {
 	}
 }
 
+func TestAddrManagerExtendLookahead(t *testing.T) {
+	mk := chain.NewMasterExtendedKeyFromMnemonic(Mnemonic, "", chain.NetworkRegtest)
+	ring := NewAccountKeyring(NewEKPrivateKeyer(mk), mk, chain.NetworkRegtest)
+	dTx := new(dummyTransactor)
+	bloom := NewAddressBloom()
+	mgr := NewAddressManager(ring, bloom, "dummy", chain.ReceiveBranch, 0, 5, WithLookSize(5))
+	require.EqualValues(t, 5, mgr.GapLimit())
+
+	require.False(t, bloom.Test(ring.Address(chain.ReceiveBranch, 20)))
+	require.NoError(t, mgr.ExtendLookahead(dTx, 20))
+	require.EqualValues(t, 0, mgr.Depth())
+	require.EqualValues(t, 20, mgr.Lookahead())
+	require.True(t, bloom.Test(ring.Address(chain.ReceiveBranch, 20)))
+
+	// using an address within the widened lookahead only extends it by the
+	// gap limit
+	require.NoError(t, mgr.SetAddressIdx(dTx, 18))
+	require.EqualValues(t, 19, mgr.Depth())
+	require.EqualValues(t, 23, mgr.Lookahead())
+
+	require.NoError(t, mgr.ExtendLookahead(dTx, 2))
+	require.EqualValues(t, 23, mgr.Lookahead())
+}
+
 type dummyTransactor struct{}
 
 func (d *dummyTransactor) Query(q string, args ...interface{}) (*sql.Rows, error) {
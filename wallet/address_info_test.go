@@ -0,0 +1,101 @@
+package wallet
+
+import (
+	"bytes"
+	"database/sql"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/gcrypto"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAddressInfoStorage(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	used := chain.NewAddressFromHash(bytes.Repeat([]byte{0x01}, 20))
+	unused := chain.NewAddressFromHash(bytes.Repeat([]byte{0x02}, 20))
+	change := chain.NewAddressFromHash(bytes.Repeat([]byte{0x03}, 20))
+	other := chain.NewAddressFromHash(bytes.Repeat([]byte{0x04}, 20))
+	fundHash := gcrypto.Hash(bytes.Repeat([]byte{0xaa}, 32))
+	spendHash := gcrypto.Hash(bytes.Repeat([]byte{0xbb}, 32))
+	pendingHash := gcrypto.Hash(bytes.Repeat([]byte{0xcc}, 32))
+
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		_, err := walletdb.CreateAddress(tx, "alice", used, chain.ReceiveBranch, 0)
+		require.NoError(t, err)
+		_, err = walletdb.CreateAddress(tx, "alice", unused, chain.ReceiveBranch, 1)
+		require.NoError(t, err)
+		_, err = walletdb.CreateAddress(tx, "alice", change, chain.ChangeBranch, 0)
+		require.NoError(t, err)
+
+		for hash, height := range map[string]int{
+			fundHash.String():    10,
+			spendHash.String():   15,
+			pendingHash.String(): -1,
+		} {
+			_, err = walletdb.UpsertTransaction(tx, "alice", &walletdb.Transaction{
+				Hash:        hash,
+				BlockHeight: height,
+				BlockHash:   hash,
+				Raw:         []byte{},
+			})
+			require.NoError(t, err)
+		}
+
+		covenant := &chain.Covenant{Type: chain.CovenantNone}
+		spent := &chain.Outpoint{Hash: fundHash, Index: 0}
+		require.NoError(t, walletdb.CreateCoin(tx, "alice", spent, 1000, used, covenant, false, walletdb.CoinTypeDefault))
+		require.NoError(t, walletdb.UpdateCoinSpent(tx, spent, spendHash))
+		unspent := &chain.Outpoint{Hash: fundHash, Index: 1}
+		require.NoError(t, walletdb.CreateCoin(tx, "alice", unspent, 500, used, covenant, false, walletdb.CoinTypeDefault))
+		pending := &chain.Outpoint{Hash: pendingHash, Index: 0}
+		require.NoError(t, walletdb.CreateCoin(tx, "alice", pending, 250, change, covenant, false, walletdb.CoinTypeDefault))
+		_, err = walletdb.SetLabel(tx, "alice", walletdb.LabelTypeAddress, used.String(), "invoice #1", 1)
+		require.NoError(t, err)
+
+		infos, err := walletdb.GetAddressInfos(tx, "alice", -1, 50, 0)
+		require.NoError(t, err)
+		require.Len(t, infos, 3)
+
+		first, last := 10, 15
+		require.Equal(t, &walletdb.AddressInfo{
+			Address:         used,
+			Own:             true,
+			Derivation:      chain.Derivation{chain.ReceiveBranch, 0},
+			Label:           "invoice #1",
+			Received:        true,
+			Balance:         500,
+			FirstSeenHeight: &first,
+			LastSeenHeight:  &last,
+		}, infos[0])
+		require.Equal(t, &walletdb.AddressInfo{
+			Address:    unused,
+			Own:        true,
+			Derivation: chain.Derivation{chain.ReceiveBranch, 1},
+		}, infos[1])
+		require.True(t, infos[2].Received)
+		require.EqualValues(t, 250, infos[2].Balance)
+		require.Nil(t, infos[2].FirstSeenHeight)
+
+		infos, err = walletdb.GetAddressInfos(tx, "alice", int64(chain.ChangeBranch), 50, 0)
+		require.NoError(t, err)
+		require.Len(t, infos, 1)
+		require.Equal(t, change.String(), infos[0].Address.String())
+
+		infos, err = walletdb.GetAddressInfos(tx, "alice", int64(chain.ReceiveBranch), 1, 1)
+		require.NoError(t, err)
+		require.Len(t, infos, 1)
+		require.Equal(t, unused.String(), infos[0].Address.String())
+
+		info, err := walletdb.GetAddressInfo(tx, "alice", change)
+		require.NoError(t, err)
+		require.Equal(t, chain.Derivation{chain.ChangeBranch, 0}, info.Derivation)
+		_, err = walletdb.GetAddressInfo(tx, "alice", other)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = walletdb.GetAddressInfo(tx, "bob", used)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		return nil
+	}))
+}
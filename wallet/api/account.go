@@ -2,12 +2,15 @@ package api
 
 import (
 	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/kurumiimari/gohan/chain"
 	"github.com/kurumiimari/gohan/gcrypto"
 	"github.com/kurumiimari/gohan/wallet"
 	"github.com/pkg/errors"
 	"net/http"
+	"strconv"
 )
 
 func AccountParams(r *http.Request) string {
@@ -29,24 +32,48 @@ func (a *API) HandleAccountsPOST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var createOpts []wallet.CreateOption
+	if len(req.Cosigners) > 0 {
+		createOpts = append(createOpts, wallet.WithMultisig(req.Threshold, req.Cosigners...))
+	}
+	if req.Signer != "" {
+		createOpts = append(createOpts, wallet.WithExternalSigner(req.Signer))
+	}
+	createOpts = append(createOpts, gapLimitOpts(req.ReceiveGapLimit, req.ChangeGapLimit, req.DutchAuctionGapLimit)...)
+	if req.Discover && req.XPub == "" && req.Mnemonic == "" {
+		MarshalErrorJSON(w, errors.New("discovery is only supported when importing"), 400)
+		return
+	}
+	if req.Passphrase != "" && req.XPub != "" {
+		MarshalErrorJSON(w, errors.New("passphrases are not supported for xpub imports"), 400)
+		return
+	}
+
 	var err error
+	var acc *wallet.Account
 	var mnemonic string
 	if req.XPub != "" {
-		_, err = a.node.ImportXPub(req.ID, req.Password, req.XPub, req.Index)
+		acc, err = a.node.ImportXPub(req.ID, req.Password, req.XPub, req.Index, createOpts...)
 	} else if req.Mnemonic != "" {
-		_, err = a.node.ImportMnemonic(req.ID, req.Password, req.Mnemonic, req.Index)
+		acc, err = a.node.ImportMnemonic(req.ID, req.Password, req.Mnemonic, req.Passphrase, req.Index, createOpts...)
 	} else {
-		_, mnemonic, err = a.node.CreateWallet(req.ID, req.Password, req.Index)
+		_, mnemonic, err = a.node.CreateWallet(req.ID, req.Password, req.Passphrase, req.Index, createOpts...)
 	}
 
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
 	}
+	if req.Discover {
+		if err := acc.DiscoveryRescan(0); err != nil {
+			MarshalErrorJSON(w, err, 500)
+			return
+		}
+	}
 
 	res := &CreateAccountRes{
 		ID:        req.ID,
-		WatchOnly: req.XPub != "",
+		WatchOnly: req.XPub != "" && req.Signer == "",
 	}
 	if mnemonic != "" {
 		res.Mnemonic = &mnemonic
@@ -74,6 +101,48 @@ func (a *API) HandleAccountUnlockPOST(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(204)
 }
 
+func (a *API) HandleAccountChangePasswordPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(ChangePasswordReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	if err := acc.ChangePassword(req.OldPassword, req.NewPassword, req.KDFParams()); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleAccountMnemonicPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(MnemonicReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	mnemonic, err := acc.Mnemonic(req.Password)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	MarshalResponseJSON(w, &MnemonicRes{
+		Mnemonic: mnemonic,
+	})
+}
+
 func (a *API) HandleAccountLockPOST(w http.ResponseWriter, r *http.Request) {
 	acc, err := a.getAccount(r)
 	if err != nil {
@@ -98,6 +167,7 @@ func (a *API) HandleAccountGET(w http.ResponseWriter, r *http.Request) {
 
 	recvDepth, chgDepth := acc.AddressDepth()
 	recvLook, chgLook := acc.LookaheadDepth()
+	recvGap, chgGap, dutchGap := acc.GapLimits()
 	balances, err := acc.Balances()
 	if err != nil {
 		MarshalErrorJSON(w, errors.Wrap(err, "error getting balances"), 500)
@@ -120,10 +190,25 @@ func (a *API) HandleAccountGET(w http.ResponseWriter, r *http.Request) {
 			recvLook,
 			chgLook,
 		},
-		ReceiveAddress: recvAddr.String(),
-		ChangeAddress:  chgAddr.String(),
-		XPub:           acc.XPub(),
-		RescanHeight:   acc.RescanHeight(),
+		GapLimit: &AccountGapLimit{
+			Receive:      recvGap,
+			Change:       chgGap,
+			DutchAuction: dutchGap,
+		},
+		ReceiveAddress:  recvAddr.String(),
+		ChangeAddress:   chgAddr.String(),
+		XPub:            acc.XPub(),
+		RescanHeight:    acc.RescanHeight(),
+		AutoReveal:      acc.AutoReveal(),
+		AutoSweep:       acc.AutoSweep(),
+		AutoRegister:    acc.AutoRegisterResource(),
+		AutoRenewBlocks: acc.AutoRenewBlocks(),
+	}
+	if ms := acc.Multisig(); ms != nil {
+		res.Multisig = &AccountMultisig{
+			Threshold: ms.Threshold(),
+			Cosigners: ms.Cosigners(),
+		}
 	}
 	MarshalResponseJSON(w, res)
 }
@@ -135,19 +220,70 @@ func (a *API) HandleAccountTransactionsGET(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	q := r.URL.Query()
-	count := GetIntFromQuery(q, "count", 50)
-	offset := GetIntFromQuery(q, "offset", 0)
+	filter, err := ParseTransactionFilter(r.URL.Query())
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
 
-	txs, err := acc.Transactions(count, offset)
+	txs, next, err := acc.Transactions(filter)
 	if err != nil {
 		MarshalErrorJSON(w, err, 500)
 		return
 	}
 
+	if next != nil {
+		w.Header().Set(NextCursorHeader, next.String())
+	}
 	MarshalResponseJSON(w, txs)
 }
 
+func (a *API) HandleBumpFeePOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 404)
+		return
+	}
+
+	hash, err := hex.DecodeString(mux.Vars(r)["hash"])
+	if err != nil {
+		MarshalErrorJSON(w, errors.New("invalid transaction hash"), 400)
+		return
+	}
+
+	req := new(BumpFeeReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	tx, err := acc.BumpFee(hash, req.Method, req.FeeRate)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	MarshalResponseJSON(w, tx)
+}
+
+func (a *API) HandleAbandonTxPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 404)
+		return
+	}
+
+	hash, err := hex.DecodeString(mux.Vars(r)["hash"])
+	if err != nil {
+		MarshalErrorJSON(w, errors.New("invalid transaction hash"), 400)
+		return
+	}
+
+	if err := acc.AbandonTx(hash); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	w.WriteHeader(204)
+}
+
 func (a *API) HandleCoinsGET(w http.ResponseWriter, r *http.Request) {
 	acc, err := a.getAccount(r)
 	if err != nil {
@@ -165,6 +301,332 @@ func (a *API) HandleCoinsGET(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (a *API) HandleFreezeCoinsPOST(w http.ResponseWriter, r *http.Request) {
+	a.handleFreezeCoins(w, r, true)
+}
+
+func (a *API) HandleUnfreezeCoinsPOST(w http.ResponseWriter, r *http.Request) {
+	a.handleFreezeCoins(w, r, false)
+}
+
+func (a *API) handleFreezeCoins(w http.ResponseWriter, r *http.Request, frozen bool) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(FreezeCoinsReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	if err := acc.FreezeCoins(req.Coins, frozen); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleAutoRevealPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(AutoRevealReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	if err := acc.SetAutoReveal(req.Enabled); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleAutoSweepPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(AutoSweepReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	if err := acc.SetAutoSweep(req.Enabled, req.Resource); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleAutoRenewPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(AutoRenewReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	if err := acc.SetAutoRenew(req.Blocks); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleExportGET(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		MarshalErrorJSON(w, errors.New("format must be json or csv"), 400)
+		return
+	}
+
+	rows, err := acc.Export()
+	if err != nil {
+		MarshalErrorJSON(w, err, 500)
+		return
+	}
+
+	if format != "csv" {
+		MarshalResponseJSON(w, rows)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", acc.ID()))
+	if err := wallet.WriteExportCSV(w, rows); err != nil {
+		apiLogger.Warning("error writing CSV export", "err", err)
+	}
+}
+
+func (a *API) HandleWebhooksGET(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	hooks, err := acc.Webhooks()
+	if err != nil {
+		MarshalErrorJSON(w, err, 500)
+		return
+	}
+
+	MarshalResponseJSON(w, &GetWebhooksRes{Webhooks: hooks})
+}
+
+func (a *API) HandleWebhooksPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(CreateWebhookReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	hook, err := acc.AddWebhook(req.URL)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, hook)
+}
+
+func (a *API) HandleWebhookDeletePOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["webhook_id"])
+	if err != nil {
+		MarshalErrorJSON(w, errors.New("invalid webhook ID"), 400)
+		return
+	}
+
+	if err := acc.RemoveWebhook(id); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleLabelsGET(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	labels, err := acc.Labels(r.URL.Query().Get("type"))
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, &GetLabelsRes{Labels: labels})
+}
+
+func (a *API) HandleLabelsPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(SetLabelReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	label, err := acc.SetLabel(req.Type, req.Target, req.Label)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, label)
+}
+
+func (a *API) HandleLabelDeletePOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(DeleteLabelReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	if err := acc.RemoveLabel(req.Type, req.Target); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleAddressBookGET(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	contacts, err := acc.Contacts()
+	if err != nil {
+		MarshalErrorJSON(w, err, 500)
+		return
+	}
+
+	MarshalResponseJSON(w, &GetContactsRes{Contacts: contacts})
+}
+
+func (a *API) HandleAddressBookPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(CreateContactReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	contact, err := acc.AddContact(req.Name, req.Address)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, contact)
+}
+
+func (a *API) HandleAddressBookDeletePOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(DeleteContactReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	if err := acc.RemoveContact(req.Name); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleAddressesGET(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	q := r.URL.Query()
+	count := GetIntFromQuery(q, "count", 50)
+	offset := GetIntFromQuery(q, "offset", 0)
+
+	addresses, err := acc.Addresses(q.Get("branch"), count, offset)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, &GetAddressesRes{Addresses: addresses})
+}
+
+func (a *API) HandleAddressGET(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	addr, err := chain.NewAddressFromBech32(mux.Vars(r)["address"])
+	if err != nil {
+		MarshalErrorJSON(w, errors.New("invalid address"), 400)
+		return
+	}
+
+	info, err := acc.AddressInfo(addr)
+	if err != nil {
+		MarshalErrorJSON(w, err, 500)
+		return
+	}
+
+	MarshalResponseJSON(w, info)
+}
+
 func (a *API) HandleNamesGET(w http.ResponseWriter, r *http.Request) {
 	acc, err := a.getAccount(r)
 	if err != nil {
@@ -198,13 +660,22 @@ func (a *API) HandleNameGET(w http.ResponseWriter, r *http.Request) {
 	count := GetIntFromQuery(q, "count", 50)
 	offset := GetIntFromQuery(q, "offset", 0)
 
+	info, err := acc.Name(name)
+	if err != nil {
+		MarshalErrorJSON(w, err, 500)
+		return
+	}
+
 	history, err := acc.History(name, count, offset)
 	if err != nil {
 		MarshalErrorJSON(w, err, 500)
 		return
 	}
 
-	MarshalResponseJSON(w, &GetNameRes{History: history})
+	MarshalResponseJSON(w, &GetNameRes{
+		Name:    info,
+		History: history,
+	})
 }
 
 func (a *API) HandleGenerateReceiveAddress(w http.ResponseWriter, r *http.Request) {
@@ -257,7 +728,7 @@ func (a *API) HandleAccountOpensPOST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, err := acc.Open(req.Name, req.FeeRate)
+	tx, err := acc.Open(req.Name, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -278,7 +749,7 @@ func (a *API) HandleAccountBidsPOST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, err := acc.Bid(req.Name, req.FeeRate, req.Value, req.Lockup)
+	tx, err := acc.Bid(req.Name, req.FeeRate, req.Value, req.Lockup, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -299,7 +770,7 @@ func (a *API) HandleAccountRevealsPOST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, err := acc.Reveal(req.Name, req.FeeRate)
+	tx, err := acc.Reveal(req.Name, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 500)
 		return
@@ -320,7 +791,7 @@ func (a *API) HandleAccountRedeemsPOST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, err := acc.Redeem(req.Name, req.FeeRate)
+	tx, err := acc.Redeem(req.Name, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 500)
 		return
@@ -340,7 +811,7 @@ func (a *API) HandleAccountUpdatesPOST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, err := acc.Update(req.Name, req.Resource, req.FeeRate)
+	tx, err := acc.Update(req.Name, req.Resource, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -366,7 +837,7 @@ func (a *API) HandleAccountTransfersPOST(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	tx, err := acc.Transfer(req.Name, addr, req.FeeRate)
+	tx, err := acc.Transfer(req.Name, addr, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -386,7 +857,7 @@ func (a *API) HandleAccountFinalizesPOST(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	tx, err := acc.Finalize(req.Name, req.FeeRate)
+	tx, err := acc.Finalize(req.Name, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -406,7 +877,7 @@ func (a *API) HandleAccountRenewalsPOST(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	tx, err := acc.Renew(req.Name, req.FeeRate)
+	tx, err := acc.Renew(req.Name, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -426,7 +897,7 @@ func (a *API) HandleAccountRevokesPOST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tx, err := acc.Revoke(req.Name, req.FeeRate)
+	tx, err := acc.Revoke(req.Name, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -434,24 +905,57 @@ func (a *API) HandleAccountRevokesPOST(w http.ResponseWriter, r *http.Request) {
 	MarshalResponseJSON(w, tx)
 }
 
-func (a *API) HandleAccountSendPOST(w http.ResponseWriter, r *http.Request) {
+func (a *API) HandleAccountBatchesPOST(w http.ResponseWriter, r *http.Request) {
 	acc, err := a.getAccount(r)
 	if err != nil {
-		MarshalErrorJSON(w, err, 404)
+		MarshalErrorJSON(w, err, 400)
 		return
 	}
 
-	req := new(CreateSendReq)
+	req := new(CreateBatchReq)
 	if !UnmarshalRequestJSON(w, r, req) {
 		return
 	}
 
-	addr, err := chain.NewAddressFromBech32(req.Address)
+	tx, err := acc.Batch(req.Actions, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
 	}
-	tx, err := acc.Send(req.Value, req.FeeRate, addr)
+	MarshalResponseJSON(w, tx)
+}
+
+func (a *API) HandleAccountSendPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 404)
+		return
+	}
+
+	req := new(CreateSendReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	recipients := req.Outputs
+	if req.Address != "" || req.Value != 0 {
+		if len(recipients) > 0 {
+			MarshalErrorJSON(w, errors.New("cannot specify both outputs and a single address"), 400)
+			return
+		}
+		addr, err := chain.NewAddressFromBech32(req.Address)
+		if err != nil {
+			MarshalErrorJSON(w, err, 400)
+			return
+		}
+		recipients = []*wallet.Recipient{
+			{
+				Address: addr,
+				Value:   req.Value,
+			},
+		}
+	}
+	tx, err := acc.SendMany(recipients, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -485,7 +989,11 @@ func (a *API) HandleRescanPOST(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := acc.Rescan(req.Height); err != nil {
+	rescan := acc.Rescan
+	if req.Discover {
+		rescan = acc.DiscoveryRescan
+	}
+	if err := rescan(req.Height); err != nil {
 		MarshalErrorJSON(w, err, 500)
 		return
 	}
@@ -600,6 +1108,7 @@ func (a *API) HandleDutchAuctionListingTransfersPOST(w http.ResponseWriter, r *h
 	tx, err := acc.TransferDutchAuctionListing(
 		req.Name,
 		req.FeeRate,
+		req.TxOptions()...,
 	)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
@@ -621,7 +1130,7 @@ func (a *API) HandleDutchAuctionListingFinalizesPOST(w http.ResponseWriter, r *h
 		return
 	}
 
-	tx, err := acc.FinalizeDutchAuctionListing(req.Name, req.FeeRate)
+	tx, err := acc.FinalizeDutchAuctionListing(req.Name, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -685,6 +1194,7 @@ func (a *API) HandleDutchAuctionFillTransfersPOST(w http.ResponseWriter, r *http
 		req.Bid,
 		req.AuctionFee,
 		req.FeeRate,
+		req.TxOptions()...,
 	)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
@@ -706,7 +1216,7 @@ func (a *API) HandleDutchAuctionFillFinalizesPOST(w http.ResponseWriter, r *http
 		return
 	}
 
-	tx, err := acc.FinalizeDutchAuction(req.Name, req.FeeRate)
+	tx, err := acc.FinalizeDutchAuction(req.Name, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -727,7 +1237,7 @@ func (a *API) HandleDutchAuctionCancelTransfersPOST(w http.ResponseWriter, r *ht
 		return
 	}
 
-	tx, err := acc.TransferDutchAuctionCancel(req.Name, req.FeeRate)
+	tx, err := acc.TransferDutchAuctionCancel(req.Name, req.FeeRate, req.TxOptions()...)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -748,7 +1258,86 @@ func (a *API) HandleDutchAuctionCancelFinalizesPOST(w http.ResponseWriter, r *ht
 		return
 	}
 
-	tx, err := acc.FinalizeDutchAuctionCancel(req.Name, req.FeeRate)
+	tx, err := acc.FinalizeDutchAuctionCancel(req.Name, req.FeeRate, req.TxOptions()...)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, tx)
+}
+
+func (a *API) HandlePartialTxGET(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 404)
+		return
+	}
+
+	hash, err := hex.DecodeString(mux.Vars(r)["hash"])
+	if err != nil {
+		MarshalErrorJSON(w, errors.New("invalid transaction hash"), 400)
+		return
+	}
+
+	ptx, err := acc.PartialTx(hash)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, &PartialTxRes{
+		PartialTx: ptx,
+		Complete:  ptx.Complete(),
+	})
+}
+
+func (a *API) HandlePartialTxSignaturesPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 404)
+		return
+	}
+
+	req := new(PartialTxReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+	if req.PartialTx == nil {
+		MarshalErrorJSON(w, errors.New("must provide a partial transaction"), 400)
+		return
+	}
+
+	signed, err := acc.SignPartialTx(req.PartialTx)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, &PartialTxRes{
+		PartialTx: req.PartialTx,
+		Signed:    signed,
+		Complete:  req.PartialTx.Complete(),
+	})
+}
+
+func (a *API) HandlePartialTxFinalizesPOST(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 404)
+		return
+	}
+
+	req := new(PartialTxReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+	if req.PartialTx == nil {
+		MarshalErrorJSON(w, errors.New("must provide a partial transaction"), 400)
+		return
+	}
+
+	tx, err := acc.FinalizePartialTx(req.PartialTx)
 	if err != nil {
 		MarshalErrorJSON(w, err, 400)
 		return
@@ -759,6 +1348,13 @@ func (a *API) HandleDutchAuctionCancelFinalizesPOST(w http.ResponseWriter, r *ht
 
 func (a *API) getAccount(r *http.Request) (*wallet.Account, error) {
 	accountID := AccountParams(r)
+	if walletID := WalletParams(r); walletID != "" {
+		w, err := a.node.Wallet(walletID)
+		if err != nil {
+			return nil, err
+		}
+		return w.Account(accountID)
+	}
 	account, err := a.node.Account(accountID)
 	if err != nil {
 		return nil, err
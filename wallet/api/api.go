@@ -67,44 +67,88 @@ func NewAPI(network *chain.Network, service *wallet.Node, apiKey string) http.Ha
 	v1 := r.PathPrefix("/api/v1").Subrouter()
 	v1.HandleFunc("/status", api.Status)
 	postOnly(v1.HandleFunc("/poll_block", api.PollBlock))
+	jsonPostOnly(v1.HandleFunc("/verify_message", api.HandleVerifyMessagePOST))
+	jsonPostOnly(v1.HandleFunc("/verify_message_with_name", api.HandleVerifyMessageWithNamePOST))
 	getOnly(v1.HandleFunc("/accounts", api.HandleAccountsGET))
 	postOnly(v1.HandleFunc("/accounts", api.HandleAccountsPOST))
-	accounts := v1.PathPrefix("/accounts/{accountID}").Subrouter()
-	getOnly(accounts.HandleFunc("/", api.HandleAccountGET))
-	jsonPostOnly(accounts.HandleFunc("/unlock", api.HandleAccountUnlockPOST))
-	jsonPostOnly(accounts.HandleFunc("/lock", api.HandleAccountLockPOST))
-	getOnly(accounts.HandleFunc("/transactions", api.HandleAccountTransactionsGET))
-	getOnly(accounts.HandleFunc("/coins", api.HandleCoinsGET))
-	getOnly(accounts.HandleFunc("/names", api.HandleNamesGET))
-	getOnly(accounts.HandleFunc("/unspent_bids", api.HandleUnspentBidsGET))
-	getOnly(accounts.HandleFunc("/unspent_reveals", api.HandleUnspentRevealsGET))
-	getOnly(accounts.HandleFunc("/names/{name}", api.HandleNameGET))
-	jsonPostOnly(accounts.HandleFunc("/receive_address", api.HandleGenerateReceiveAddress))
-	jsonPostOnly(accounts.HandleFunc("/change_address", api.HandleGenerateChangeAddress))
-	jsonPostOnly(accounts.HandleFunc("/sends", api.HandleAccountSendPOST))
-	jsonPostOnly(accounts.HandleFunc("/opens", api.HandleAccountOpensPOST))
-	jsonPostOnly(accounts.HandleFunc("/bids", api.HandleAccountBidsPOST))
-	jsonPostOnly(accounts.HandleFunc("/reveals", api.HandleAccountRevealsPOST))
-	jsonPostOnly(accounts.HandleFunc("/redeems", api.HandleAccountRedeemsPOST))
-	jsonPostOnly(accounts.HandleFunc("/updates", api.HandleAccountUpdatesPOST))
-	jsonPostOnly(accounts.HandleFunc("/transfers", api.HandleAccountTransfersPOST))
-	jsonPostOnly(accounts.HandleFunc("/finalizes", api.HandleAccountFinalizesPOST))
-	jsonPostOnly(accounts.HandleFunc("/renewals", api.HandleAccountRenewalsPOST))
-	jsonPostOnly(accounts.HandleFunc("/revokes", api.HandleAccountRevokesPOST))
-	jsonPostOnly(accounts.HandleFunc("/dutch_auction_listing_transfers", api.HandleDutchAuctionListingTransfersPOST))
-	jsonPostOnly(accounts.HandleFunc("/dutch_auction_listing_finalizes", api.HandleDutchAuctionListingFinalizesPOST))
-	jsonPostOnly(accounts.HandleFunc("/dutch_auction_cancel_transfers", api.HandleDutchAuctionCancelTransfersPOST))
-	jsonPostOnly(accounts.HandleFunc("/dutch_auction_cancel_finalizes", api.HandleDutchAuctionCancelFinalizesPOST))
-	jsonPostOnly(accounts.HandleFunc("/dutch_auction_listings", api.HandleUpdateDutchAuctionListingsPOST))
-	jsonPostOnly(accounts.HandleFunc("/dutch_auction_fill_transfers", api.HandleDutchAuctionFillTransfersPOST))
-	jsonPostOnly(accounts.HandleFunc("/dutch_auction_fill_finalizes", api.HandleDutchAuctionFillFinalizesPOST))
-	jsonPostOnly(accounts.HandleFunc("/zap", api.HandleZapPost))
-	jsonPostOnly(accounts.HandleFunc("/rescan", api.HandleRescanPOST))
-	jsonPostOnly(accounts.HandleFunc("/sign_message", api.HandleSignMessagePOST))
-	jsonPostOnly(accounts.HandleFunc("/sign_message_with_name", api.HandleSignMessageWithNamePOST))
+	api.registerAccountRoutes(v1.PathPrefix("/accounts/{accountID}").Subrouter())
+	getOnly(v1.HandleFunc("/wallets", api.HandleWalletsGET))
+	jsonPostOnly(v1.HandleFunc("/wallets", api.HandleWalletsPOST))
+	wallets := v1.PathPrefix("/wallets/{walletID}").Subrouter()
+	getOnly(wallets.HandleFunc("/", api.HandleWalletGET))
+	jsonPostOnly(wallets.HandleFunc("/unlock", api.HandleWalletUnlockPOST))
+	jsonPostOnly(wallets.HandleFunc("/lock", api.HandleWalletLockPOST))
+	jsonPostOnly(wallets.HandleFunc("/change_password", api.HandleWalletChangePasswordPOST))
+	jsonPostOnly(wallets.HandleFunc("/mnemonic", api.HandleWalletMnemonicPOST))
+	getOnly(wallets.HandleFunc("/accounts", api.HandleWalletAccountsGET))
+	jsonPostOnly(wallets.HandleFunc("/accounts", api.HandleWalletAccountsPOST))
+	api.registerAccountRoutes(wallets.PathPrefix("/accounts/{accountID}").Subrouter())
 	return r
 }
 
+// registerAccountRoutes adds the per-account routes to accounts, which is
+// mounted both at the top level and under each wallet.
+func (a *API) registerAccountRoutes(accounts *mux.Router) {
+	getOnly(accounts.HandleFunc("/", a.HandleAccountGET))
+	jsonPostOnly(accounts.HandleFunc("/unlock", a.HandleAccountUnlockPOST))
+	jsonPostOnly(accounts.HandleFunc("/lock", a.HandleAccountLockPOST))
+	jsonPostOnly(accounts.HandleFunc("/change_password", a.HandleAccountChangePasswordPOST))
+	jsonPostOnly(accounts.HandleFunc("/mnemonic", a.HandleAccountMnemonicPOST))
+	getOnly(accounts.HandleFunc("/transactions", a.HandleAccountTransactionsGET))
+	getOnly(accounts.HandleFunc("/events", a.HandleEventsGET))
+	jsonPostOnly(accounts.HandleFunc("/transactions/{hash}/bump", a.HandleBumpFeePOST))
+	jsonPostOnly(accounts.HandleFunc("/transactions/{hash}/abandon", a.HandleAbandonTxPOST))
+	getOnly(accounts.HandleFunc("/coins", a.HandleCoinsGET))
+	jsonPostOnly(accounts.HandleFunc("/freeze_coins", a.HandleFreezeCoinsPOST))
+	jsonPostOnly(accounts.HandleFunc("/unfreeze_coins", a.HandleUnfreezeCoinsPOST))
+	jsonPostOnly(accounts.HandleFunc("/auto_reveal", a.HandleAutoRevealPOST))
+	jsonPostOnly(accounts.HandleFunc("/auto_sweep", a.HandleAutoSweepPOST))
+	jsonPostOnly(accounts.HandleFunc("/auto_renew", a.HandleAutoRenewPOST))
+	getOnly(accounts.HandleFunc("/export", a.HandleExportGET))
+	getOnly(accounts.HandleFunc("/webhooks", a.HandleWebhooksGET))
+	jsonPostOnly(accounts.HandleFunc("/webhooks", a.HandleWebhooksPOST))
+	jsonPostOnly(accounts.HandleFunc("/webhooks/{webhook_id}/delete", a.HandleWebhookDeletePOST))
+	getOnly(accounts.HandleFunc("/labels", a.HandleLabelsGET))
+	jsonPostOnly(accounts.HandleFunc("/labels", a.HandleLabelsPOST))
+	jsonPostOnly(accounts.HandleFunc("/labels/delete", a.HandleLabelDeletePOST))
+	getOnly(accounts.HandleFunc("/address_book", a.HandleAddressBookGET))
+	jsonPostOnly(accounts.HandleFunc("/address_book", a.HandleAddressBookPOST))
+	jsonPostOnly(accounts.HandleFunc("/address_book/delete", a.HandleAddressBookDeletePOST))
+	getOnly(accounts.HandleFunc("/addresses", a.HandleAddressesGET))
+	getOnly(accounts.HandleFunc("/addresses/{address}", a.HandleAddressGET))
+	getOnly(accounts.HandleFunc("/names", a.HandleNamesGET))
+	getOnly(accounts.HandleFunc("/unspent_bids", a.HandleUnspentBidsGET))
+	getOnly(accounts.HandleFunc("/unspent_reveals", a.HandleUnspentRevealsGET))
+	getOnly(accounts.HandleFunc("/names/{name}", a.HandleNameGET))
+	jsonPostOnly(accounts.HandleFunc("/receive_address", a.HandleGenerateReceiveAddress))
+	jsonPostOnly(accounts.HandleFunc("/change_address", a.HandleGenerateChangeAddress))
+	jsonPostOnly(accounts.HandleFunc("/sends", a.HandleAccountSendPOST))
+	jsonPostOnly(accounts.HandleFunc("/opens", a.HandleAccountOpensPOST))
+	jsonPostOnly(accounts.HandleFunc("/bids", a.HandleAccountBidsPOST))
+	jsonPostOnly(accounts.HandleFunc("/reveals", a.HandleAccountRevealsPOST))
+	jsonPostOnly(accounts.HandleFunc("/redeems", a.HandleAccountRedeemsPOST))
+	jsonPostOnly(accounts.HandleFunc("/updates", a.HandleAccountUpdatesPOST))
+	jsonPostOnly(accounts.HandleFunc("/transfers", a.HandleAccountTransfersPOST))
+	jsonPostOnly(accounts.HandleFunc("/finalizes", a.HandleAccountFinalizesPOST))
+	jsonPostOnly(accounts.HandleFunc("/renewals", a.HandleAccountRenewalsPOST))
+	jsonPostOnly(accounts.HandleFunc("/revokes", a.HandleAccountRevokesPOST))
+	jsonPostOnly(accounts.HandleFunc("/batches", a.HandleAccountBatchesPOST))
+	jsonPostOnly(accounts.HandleFunc("/dutch_auction_listing_transfers", a.HandleDutchAuctionListingTransfersPOST))
+	jsonPostOnly(accounts.HandleFunc("/dutch_auction_listing_finalizes", a.HandleDutchAuctionListingFinalizesPOST))
+	jsonPostOnly(accounts.HandleFunc("/dutch_auction_cancel_transfers", a.HandleDutchAuctionCancelTransfersPOST))
+	jsonPostOnly(accounts.HandleFunc("/dutch_auction_cancel_finalizes", a.HandleDutchAuctionCancelFinalizesPOST))
+	jsonPostOnly(accounts.HandleFunc("/dutch_auction_listings", a.HandleUpdateDutchAuctionListingsPOST))
+	jsonPostOnly(accounts.HandleFunc("/dutch_auction_fill_transfers", a.HandleDutchAuctionFillTransfersPOST))
+	jsonPostOnly(accounts.HandleFunc("/dutch_auction_fill_finalizes", a.HandleDutchAuctionFillFinalizesPOST))
+	jsonPostOnly(accounts.HandleFunc("/zap", a.HandleZapPost))
+	jsonPostOnly(accounts.HandleFunc("/rescan", a.HandleRescanPOST))
+	jsonPostOnly(accounts.HandleFunc("/sign_message", a.HandleSignMessagePOST))
+	jsonPostOnly(accounts.HandleFunc("/sign_message_with_name", a.HandleSignMessageWithNamePOST))
+	getOnly(accounts.HandleFunc("/partial_txs/{hash}", a.HandlePartialTxGET))
+	jsonPostOnly(accounts.HandleFunc("/partial_tx_signatures", a.HandlePartialTxSignaturesPOST))
+	jsonPostOnly(accounts.HandleFunc("/partial_tx_finalizes", a.HandlePartialTxFinalizesPOST))
+}
+
 func (a *API) Status(w http.ResponseWriter, r *http.Request) {
 	MarshalResponseJSON(w, a.node.Status())
 }
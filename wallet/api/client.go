@@ -1,19 +1,28 @@
 package api
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/kurumiimari/gohan/chain"
 	"github.com/kurumiimari/gohan/ghttp"
 	"github.com/kurumiimari/gohan/shakedex"
 	"github.com/kurumiimari/gohan/wallet"
 	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
 type Client struct {
-	url    string
-	apiKey string
+	url         string
+	apiKey      string
+	walletID    string
+	coinControl CoinControl
 }
 
 func NewClient(url string, apiKey string) *Client {
@@ -23,6 +32,22 @@ func NewClient(url string, apiKey string) *Client {
 	}
 }
 
+// WithCoinControl returns a copy of the client that applies the provided
+// coin selection to every transaction it creates.
+func (c *Client) WithCoinControl(cc CoinControl) *Client {
+	cpy := *c
+	cpy.coinControl = cc
+	return &cpy
+}
+
+// InWallet returns a copy of the client whose account methods address
+// accounts through the provided wallet.
+func (c *Client) InWallet(walletID string) *Client {
+	cpy := *c
+	cpy.walletID = walletID
+	return &cpy
+}
+
 func (c *Client) Status() (*wallet.NodeStatus, error) {
 	res := new(wallet.NodeStatus)
 	err := c.doGet("api/v1/status", res)
@@ -39,6 +64,58 @@ func (c *Client) CreateAccount(req *CreateAccountReq) (*CreateAccountRes, error)
 	return res, err
 }
 
+func (c *Client) CreateWallet(req *CreateWalletReq) (*CreateWalletRes, error) {
+	res := new(CreateWalletRes)
+	err := c.doPost("api/v1/wallets", req, res)
+	return res, err
+}
+
+func (c *Client) GetWallets() (*GetWalletsRes, error) {
+	res := new(GetWalletsRes)
+	err := c.doGet("api/v1/wallets", res)
+	return res, err
+}
+
+func (c *Client) GetWallet(walletID string) (*WalletGetRes, error) {
+	res := new(WalletGetRes)
+	err := c.doGet(c.walletPath(walletID), res)
+	return res, err
+}
+
+func (c *Client) UnlockWallet(walletID string, password string) error {
+	return c.doPost(c.walletPath(walletID, "unlock"), &UnlockReq{
+		Password: password,
+	}, nil)
+}
+
+func (c *Client) LockWallet(walletID string) error {
+	return c.doPost(c.walletPath(walletID, "lock"), nil, nil)
+}
+
+func (c *Client) ChangeWalletPassword(walletID string, req *ChangePasswordReq) error {
+	return c.doPost(c.walletPath(walletID, "change_password"), req, nil)
+}
+
+func (c *Client) GetWalletMnemonic(walletID string, password string) (string, error) {
+	res := new(MnemonicRes)
+	err := c.doPost(c.walletPath(walletID, "mnemonic"), &MnemonicReq{
+		Password: password,
+	}, res)
+	return res.Mnemonic, err
+}
+
+func (c *Client) GetWalletAccounts(walletID string) ([]*WalletAccount, error) {
+	res := new(GetWalletAccountsRes)
+	err := c.doGet(c.walletPath(walletID, "accounts"), res)
+	return res.Accounts, err
+}
+
+func (c *Client) CreateWalletAccount(walletID string, req *CreateWalletAccountReq) (*WalletAccount, error) {
+	res := new(WalletAccount)
+	err := c.doPost(c.walletPath(walletID, "accounts"), req, res)
+	return res, err
+}
+
 func (c *Client) Unlock(accountID string, password string) error {
 	return c.doPost(c.accountPath(accountID, "unlock"), &UnlockReq{
 		Password: password,
@@ -49,6 +126,18 @@ func (c *Client) Lock(accountID string) error {
 	return c.doPost(c.accountPath(accountID, "lock"), nil, nil)
 }
 
+func (c *Client) ChangePassword(accountID string, req *ChangePasswordReq) error {
+	return c.doPost(c.accountPath(accountID, "change_password"), req, nil)
+}
+
+func (c *Client) GetMnemonic(accountID string, password string) (string, error) {
+	res := new(MnemonicRes)
+	err := c.doPost(c.accountPath(accountID, "mnemonic"), &MnemonicReq{
+		Password: password,
+	}, res)
+	return res.Mnemonic, err
+}
+
 func (c *Client) GetAccounts() (*GetAccountsRes, error) {
 	res := new(GetAccountsRes)
 	err := c.doGet("api/v1/accounts", res)
@@ -79,6 +168,57 @@ func (c *Client) GetAccountTransactions(accountID string, count, offset int) ([]
 	return res, err
 }
 
+// SearchTransactions returns the transactions matching filter along with the
+// cursor for the next page, which is empty on the last page.
+func (c *Client) SearchTransactions(accountID string, filter *walletdb.TransactionFilter) ([]*walletdb.RichTransaction, string, error) {
+	path := fmt.Sprintf("%s?%s", c.accountPath(accountID, "transactions"), TransactionFilterQuery(filter).Encode())
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s", c.url, path), nil)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", ghttp.NewError(-1, nil, errors.WithStack(err))
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 10*1024*1024))
+	if err != nil {
+		return nil, "", ghttp.NewError(-1, nil, errors.WithStack(err))
+	}
+	if res.StatusCode != 200 {
+		return nil, "", ghttp.NewError(res.StatusCode, body, errors.Errorf("non-200 status code %d", res.StatusCode))
+	}
+
+	var txs []*walletdb.RichTransaction
+	if err := json.Unmarshal(body, &txs); err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	return txs, res.Header.Get(NextCursorHeader), nil
+}
+
+func (c *Client) Export(accountID string) ([]*wallet.ExportRow, error) {
+	var res []*wallet.ExportRow
+	err := c.doGet(c.accountPath(accountID, "export"), &res)
+	return res, err
+}
+
+func (c *Client) BumpFee(accountID, hash, method string, feeRate uint64) (*chain.Transaction, error) {
+	res := new(chain.Transaction)
+	err := c.doPost(c.accountPath(accountID, "transactions", hash, "bump"), &BumpFeeReq{
+		Method:  method,
+		FeeRate: feeRate,
+	}, res)
+	return res, err
+}
+
+func (c *Client) AbandonTx(accountID, hash string) error {
+	return c.doPost(c.accountPath(accountID, "transactions", hash, "abandon"), nil, nil)
+}
+
 func (c *Client) GenerateAccountReceiveAddress(accountID string) (*GenAddressRes, error) {
 	res := new(GenAddressRes)
 	err := c.doPost(c.accountPath(accountID, "receive_address"), nil, res)
@@ -94,20 +234,43 @@ func (c *Client) GenerateAccountChangeAddress(accountID string) (*GenAddressRes,
 func (c *Client) Send(accountID string, value, feeRate uint64, address string, createOnly bool) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "sends"), &CreateSendReq{
-		Value:      value,
-		Address:    address,
-		FeeRate:    feeRate,
-		CreateOnly: createOnly,
+		CoinControl: c.coinControl,
+		Value:       value,
+		Address:     address,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
 	}, res)
 	return res, err
 }
 
+func (c *Client) SendMany(accountID string, recipients []*wallet.Recipient, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
+	res := new(chain.Transaction)
+	err := c.doPost(c.accountPath(accountID, "sends"), &CreateSendReq{
+		CoinControl: c.coinControl,
+		Outputs:     recipients,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
+	}, res)
+	return res, err
+}
+
+// SendCSV pays every recipient in a CSV of address,amount rows in a
+// single transaction. See ParseRecipientsCSV for the format.
+func (c *Client) SendCSV(accountID string, recipients io.Reader, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
+	parsed, err := ParseRecipientsCSV(recipients)
+	if err != nil {
+		return nil, err
+	}
+	return c.SendMany(accountID, parsed, feeRate, createOnly)
+}
+
 func (c *Client) Open(accountID, name string, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "opens"), &CreateOpenReq{
-		Name:       name,
-		FeeRate:    feeRate,
-		CreateOnly: createOnly,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
 	}, res)
 	return res, err
 }
@@ -115,11 +278,12 @@ func (c *Client) Open(accountID, name string, feeRate uint64, createOnly bool) (
 func (c *Client) Bid(accountID, name string, feeRate, value, lockup uint64, createOnly bool) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "bids"), &CreateBidReq{
-		Name:       name,
-		FeeRate:    feeRate,
-		Value:      value,
-		Lockup:     lockup,
-		CreateOnly: createOnly,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
+		Value:       value,
+		Lockup:      lockup,
+		CreateOnly:  createOnly,
 	}, res)
 	return res, err
 }
@@ -127,9 +291,10 @@ func (c *Client) Bid(accountID, name string, feeRate, value, lockup uint64, crea
 func (c *Client) Reveal(accountID, name string, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "reveals"), &CreateRevealReq{
-		Name:       name,
-		FeeRate:    feeRate,
-		CreateOnly: createOnly,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
 	}, res)
 	return res, err
 }
@@ -137,9 +302,10 @@ func (c *Client) Reveal(accountID, name string, feeRate uint64, createOnly bool)
 func (c *Client) Redeem(accountID, name string, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "redeems"), &CreateRedeemReq{
-		Name:       name,
-		FeeRate:    feeRate,
-		CreateOnly: createOnly,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
 	}, res)
 	return res, err
 }
@@ -147,10 +313,11 @@ func (c *Client) Redeem(accountID, name string, feeRate uint64, createOnly bool)
 func (c *Client) Update(accountID, name string, resource *chain.Resource, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "updates"), &CreateUpdateReq{
-		Name:       name,
-		Resource:   resource,
-		FeeRate:    feeRate,
-		CreateOnly: createOnly,
+		CoinControl: c.coinControl,
+		Name:        name,
+		Resource:    resource,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
 	}, res)
 	return res, err
 }
@@ -158,10 +325,11 @@ func (c *Client) Update(accountID, name string, resource *chain.Resource, feeRat
 func (c *Client) Transfer(accountID, name, address string, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "transfers"), &CreateTransferReq{
-		Name:       name,
-		Address:    address,
-		FeeRate:    feeRate,
-		CreateOnly: createOnly,
+		CoinControl: c.coinControl,
+		Name:        name,
+		Address:     address,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
 	}, res)
 	return res, err
 }
@@ -169,9 +337,10 @@ func (c *Client) Transfer(accountID, name, address string, feeRate uint64, creat
 func (c *Client) Finalize(accountID, name string, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "finalizes"), &CreateFinalizeReq{
-		Name:       name,
-		FeeRate:    feeRate,
-		CreateOnly: createOnly,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
 	}, res)
 	return res, err
 }
@@ -179,9 +348,10 @@ func (c *Client) Finalize(accountID, name string, feeRate uint64, createOnly boo
 func (c *Client) Renew(accountID, name string, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "renewals"), &CreateRenewalsReq{
-		Name:       name,
-		FeeRate:    feeRate,
-		CreateOnly: createOnly,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
 	}, res)
 	return res, err
 }
@@ -189,9 +359,21 @@ func (c *Client) Renew(accountID, name string, feeRate uint64, createOnly bool)
 func (c *Client) Revoke(accountID, name string, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "revokes"), &CreateRevokeReq{
-		Name:       name,
-		FeeRate:    feeRate,
-		CreateOnly: createOnly,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
+	}, res)
+	return res, err
+}
+
+func (c *Client) Batch(accountID string, actions []*wallet.BatchAction, feeRate uint64, createOnly bool) (*chain.Transaction, error) {
+	res := new(chain.Transaction)
+	err := c.doPost(c.accountPath(accountID, "batches"), &CreateBatchReq{
+		CoinControl: c.coinControl,
+		Actions:     actions,
+		FeeRate:     feeRate,
+		CreateOnly:  createOnly,
 	}, res)
 	return res, err
 }
@@ -200,8 +382,9 @@ func (c *Client) TransferDutchAuctionListing(accountID, name string, feeRate uin
 ) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "dutch_auction_listing_transfers"), &DutchAuctionListingTransferReq{
-		Name:    name,
-		FeeRate: feeRate,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
 	}, res)
 	return res, err
 }
@@ -209,8 +392,9 @@ func (c *Client) TransferDutchAuctionListing(accountID, name string, feeRate uin
 func (c *Client) FinalizeDutchAuctionListing(accountID, name string, feeRate uint64) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "dutch_auction_listing_finalizes"), &DutchAuctionListingFinalizeReq{
-		Name:    name,
-		FeeRate: feeRate,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
 	}, res)
 	return res, err
 }
@@ -218,8 +402,9 @@ func (c *Client) FinalizeDutchAuctionListing(accountID, name string, feeRate uin
 func (c *Client) TransferDutchAuctionCancel(accountID, name string, feeRate uint64) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "dutch_auction_cancel_transfers"), &DutchAuctionCancelTransferReq{
-		Name:    name,
-		FeeRate: feeRate,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
 	}, res)
 	return res, err
 }
@@ -227,8 +412,9 @@ func (c *Client) TransferDutchAuctionCancel(accountID, name string, feeRate uint
 func (c *Client) FinalizeDutchAuctionCancel(accountID, name string, feeRate uint64) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "dutch_auction_cancel_finalizes"), &DutchAuctionCancelFinalizeReq{
-		Name:    name,
-		FeeRate: feeRate,
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
 	}, res)
 	return res, err
 }
@@ -248,12 +434,130 @@ func (c *Client) TransferDutchAuctionFill(accountID string, req *TransferDutchAu
 func (c *Client) FinalizeDutchAuctionFill(accountID string, name string, feeRate uint64) (*chain.Transaction, error) {
 	res := new(chain.Transaction)
 	err := c.doPost(c.accountPath(accountID, "dutch_auction_fill_finalizes"), &DutchAuctionFillFinalizeReq{
+		CoinControl: c.coinControl,
+		Name:        name,
+		FeeRate:     feeRate,
+	}, res)
+	return res, err
+}
+
+func (c *Client) Coins(accountID string) (*CoinsGetRes, error) {
+	res := new(CoinsGetRes)
+	err := c.doGet(c.accountPath(accountID, "coins"), res)
+	return res, err
+}
+
+func (c *Client) FreezeCoins(accountID string, coins []*chain.Outpoint) error {
+	return c.doPost(c.accountPath(accountID, "freeze_coins"), &FreezeCoinsReq{
+		Coins: coins,
+	}, nil)
+}
+
+func (c *Client) UnfreezeCoins(accountID string, coins []*chain.Outpoint) error {
+	return c.doPost(c.accountPath(accountID, "unfreeze_coins"), &FreezeCoinsReq{
+		Coins: coins,
+	}, nil)
+}
+
+func (c *Client) SetAutoReveal(accountID string, enabled bool) error {
+	return c.doPost(c.accountPath(accountID, "auto_reveal"), &AutoRevealReq{
+		Enabled: enabled,
+	}, nil)
+}
+
+func (c *Client) SetAutoSweep(accountID string, enabled bool, resource *chain.Resource) error {
+	return c.doPost(c.accountPath(accountID, "auto_sweep"), &AutoSweepReq{
+		Enabled:  enabled,
+		Resource: resource,
+	}, nil)
+}
+
+func (c *Client) SetAutoRenew(accountID string, blocks int) error {
+	return c.doPost(c.accountPath(accountID, "auto_renew"), &AutoRenewReq{
+		Blocks: blocks,
+	}, nil)
+}
+
+func (c *Client) AddWebhook(accountID, hookURL string) (*walletdb.Webhook, error) {
+	res := new(walletdb.Webhook)
+	err := c.doPost(c.accountPath(accountID, "webhooks"), &CreateWebhookReq{
+		URL: hookURL,
+	}, res)
+	return res, err
+}
+
+func (c *Client) GetWebhooks(accountID string) ([]*walletdb.Webhook, error) {
+	res := new(GetWebhooksRes)
+	err := c.doGet(c.accountPath(accountID, "webhooks"), res)
+	return res.Webhooks, err
+}
+
+func (c *Client) RemoveWebhook(accountID string, id int) error {
+	return c.doPost(c.accountPath(accountID, "webhooks", strconv.Itoa(id), "delete"), nil, nil)
+}
+
+func (c *Client) SetLabel(accountID, targetType, target, label string) (*walletdb.Label, error) {
+	res := new(walletdb.Label)
+	err := c.doPost(c.accountPath(accountID, "labels"), &SetLabelReq{
+		Type:   targetType,
+		Target: target,
+		Label:  label,
+	}, res)
+	return res, err
+}
+
+func (c *Client) GetLabels(accountID, targetType string) ([]*walletdb.Label, error) {
+	res := new(GetLabelsRes)
+	err := c.doGet(c.QueryStringPath(c.accountPath(accountID, "labels"), url.Values{
+		"type": []string{targetType},
+	}), res)
+	return res.Labels, err
+}
+
+func (c *Client) RemoveLabel(accountID, targetType, target string) error {
+	return c.doPost(c.accountPath(accountID, "labels", "delete"), &DeleteLabelReq{
+		Type:   targetType,
+		Target: target,
+	}, nil)
+}
+
+func (c *Client) AddContact(accountID, name, address string) (*walletdb.Contact, error) {
+	res := new(walletdb.Contact)
+	err := c.doPost(c.accountPath(accountID, "address_book"), &CreateContactReq{
 		Name:    name,
-		FeeRate: feeRate,
+		Address: address,
 	}, res)
 	return res, err
 }
 
+func (c *Client) GetContacts(accountID string) ([]*walletdb.Contact, error) {
+	res := new(GetContactsRes)
+	err := c.doGet(c.accountPath(accountID, "address_book"), res)
+	return res.Contacts, err
+}
+
+func (c *Client) RemoveContact(accountID, name string) error {
+	return c.doPost(c.accountPath(accountID, "address_book", "delete"), &DeleteContactReq{
+		Name: name,
+	}, nil)
+}
+
+func (c *Client) GetAddresses(accountID, branch string, count, offset int) ([]*walletdb.AddressInfo, error) {
+	res := new(GetAddressesRes)
+	err := c.doGet(c.QueryStringPath(c.accountPath(accountID, "addresses"), url.Values{
+		"branch": []string{branch},
+		"count":  []string{strconv.Itoa(count)},
+		"offset": []string{strconv.Itoa(offset)},
+	}), res)
+	return res.Addresses, err
+}
+
+func (c *Client) GetAddress(accountID, address string) (*walletdb.AddressInfo, error) {
+	res := new(walletdb.AddressInfo)
+	err := c.doGet(c.accountPath(accountID, "addresses", address), res)
+	return res, err
+}
+
 func (c *Client) Zap(accountID string) error {
 	return c.doPost(c.accountPath(accountID, "zap"), nil, nil)
 }
@@ -262,6 +566,13 @@ func (c *Client) Rescan(accountID string, height int) error {
 	return c.doPost(c.accountPath(accountID, "rescan"), &RescanReq{Height: height}, nil)
 }
 
+func (c *Client) DiscoveryRescan(accountID string, height int) error {
+	return c.doPost(c.accountPath(accountID, "rescan"), &RescanReq{
+		Height:   height,
+		Discover: true,
+	}, nil)
+}
+
 func (c *Client) SignMessage(accountID, address, message string) (string, error) {
 	res := new(SignMessageRes)
 	err := c.doPost(c.accountPath(accountID, "sign_message"), &SignMessageReq{
@@ -280,6 +591,26 @@ func (c *Client) SignMessageWithName(accountID, name, message string) (string, e
 	return res.Signature, err
 }
 
+func (c *Client) VerifyMessage(address, signature, message string) (*VerifyMessageRes, error) {
+	res := new(VerifyMessageRes)
+	err := c.doPost("api/v1/verify_message", &VerifyMessageReq{
+		Address:   address,
+		Message:   message,
+		Signature: signature,
+	}, res)
+	return res, err
+}
+
+func (c *Client) VerifyMessageWithName(name, signature, message string) (*VerifyMessageRes, error) {
+	res := new(VerifyMessageRes)
+	err := c.doPost("api/v1/verify_message_with_name", &VerifyMessageWithNameReq{
+		Name:      name,
+		Message:   message,
+		Signature: signature,
+	}, res)
+	return res, err
+}
+
 func (c *Client) UnspentBids(accountID string, count, offset int) (*UnspentBidsRes, error) {
 	res := new(UnspentBidsRes)
 	err := c.doGet(
@@ -298,6 +629,77 @@ func (c *Client) UnspentReveals(accountID string, count, offset int) (*UnspentRe
 	return res, err
 }
 
+func (c *Client) PartialTx(accountID, hash string) (*PartialTxRes, error) {
+	res := new(PartialTxRes)
+	err := c.doGet(c.accountPath(accountID, "partial_txs", hash), res)
+	return res, err
+}
+
+func (c *Client) SignPartialTx(accountID string, ptx *wallet.PartialTx) (*PartialTxRes, error) {
+	res := new(PartialTxRes)
+	err := c.doPost(c.accountPath(accountID, "partial_tx_signatures"), &PartialTxReq{
+		PartialTx: ptx,
+	}, res)
+	return res, err
+}
+
+func (c *Client) FinalizePartialTx(accountID string, ptx *wallet.PartialTx) (*chain.Transaction, error) {
+	res := new(chain.Transaction)
+	err := c.doPost(c.accountPath(accountID, "partial_tx_finalizes"), &PartialTxReq{
+		PartialTx: ptx,
+	}, res)
+	return res, err
+}
+
+// SubscribeEvents streams an account's events. The returned channel is
+// closed once ctx is cancelled or the stream ends, which also happens if
+// the subscriber falls too far behind. Each event's Data can be decoded
+// into the wallet package's event type that matches its Type.
+func (c *Client) SubscribeEvents(ctx context.Context, accountID string) (<-chan *Event, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/%s", c.url, c.accountPath(accountID, "events")), nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, ghttp.NewError(-1, nil, errors.WithStack(err))
+	}
+	if res.StatusCode != 200 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024*1024))
+		res.Body.Close()
+		return nil, ghttp.NewError(res.StatusCode, body, errors.Errorf("non-200 status code %d", res.StatusCode))
+	}
+
+	events := make(chan *Event)
+	go func() {
+		defer close(events)
+		defer res.Body.Close()
+
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			evt := new(Event)
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), evt); err != nil {
+				return
+			}
+			select {
+			case events <- evt:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
 func (c *Client) doGet(path string, resObj interface{}) error {
 	return ghttp.DefaultClient.DoGetJSON(fmt.Sprintf("%s/%s", c.url, path), resObj)
 }
@@ -307,9 +709,16 @@ func (c *Client) doPost(path string, reqObj interface{}, resObj interface{}) err
 }
 
 func (c *Client) accountPath(accountID string, suffixes ...string) string {
+	if c.walletID != "" {
+		return fmt.Sprintf("%saccounts/%s/%s", c.walletPath(c.walletID), accountID, strings.Join(suffixes, "/"))
+	}
 	return fmt.Sprintf("api/v1/accounts/%s/%s", accountID, strings.Join(suffixes, "/"))
 }
 
+func (c *Client) walletPath(walletID string, suffixes ...string) string {
+	return fmt.Sprintf("api/v1/wallets/%s/%s", walletID, strings.Join(suffixes, "/"))
+}
+
 func (c *Client) QueryStringPath(name string, q url.Values) string {
 	return fmt.Sprintf("%s?%s", name, q.Encode())
 }
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"time"
+)
+
+// eventKeepaliveInterval is how often a comment is sent on idle event
+// streams so that proxies don't close them.
+const eventKeepaliveInterval = 15 * time.Second
+
+// HandleEventsGET streams the account's events as server-sent events.
+func (a *API) HandleEventsGET(w http.ResponseWriter, r *http.Request) {
+	acc, err := a.getAccount(r)
+	if err != nil {
+		MarshalErrorJSON(w, err, 404)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		MarshalErrorJSON(w, errors.New("streaming is not supported"), 500)
+		return
+	}
+
+	events, unsubscribe := acc.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(200)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(eventKeepaliveInterval)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		case evt, ok := <-events:
+			// the subscription is closed if we fall behind
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(evt)
+			if err != nil {
+				apiLogger.Error("error marshaling event", "err", err)
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Type, data)
+		}
+		flusher.Flush()
+	}
+}
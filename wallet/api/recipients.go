@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/csv"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/wallet"
+	"github.com/pkg/errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseRecipientsCSV reads address,amount rows, with amounts in whole
+// HNS. A leading header row is skipped.
+func ParseRecipientsCSV(r io.Reader) ([]*wallet.Recipient, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+
+	var recipients []*wallet.Recipient
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading recipients")
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+
+		addr, err := chain.NewAddressFromBech32(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, errors.Errorf("invalid address on line %d", line)
+		}
+		value, err := ParseHNS(strings.TrimSpace(record[1]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid amount on line %d", line)
+		}
+		recipients = append(recipients, &wallet.Recipient{
+			Address: addr,
+			Value:   value,
+		})
+	}
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients found")
+	}
+	return recipients, nil
+}
+
+// ParseHNS converts a decimal amount of whole HNS into subunits.
+func ParseHNS(in string) (uint64, error) {
+	splits := strings.SplitN(in, ".", 2)
+	whole, err := strconv.ParseUint(splits[0], 10, 64)
+	if err != nil {
+		return 0, errors.New("amount must be a positive decimal number")
+	}
+	var frac uint64
+	if len(splits) == 2 {
+		if len(splits[1]) > 6 {
+			return 0, errors.New("amount has more than 6 decimal places")
+		}
+		frac, err = strconv.ParseUint(splits[1]+strings.Repeat("0", 6-len(splits[1])), 10, 64)
+		if err != nil {
+			return 0, errors.New("amount must be a positive decimal number")
+		}
+	}
+	if whole > (math.MaxUint64-frac)/1000000 {
+		return 0, errors.New("amount is too large")
+	}
+	return whole*1000000 + frac, nil
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestParseHNS(t *testing.T) {
+	amount, err := ParseHNS("18446744073709.551615")
+	require.NoError(t, err)
+	require.EqualValues(t, uint64(18446744073709551615), amount)
+
+	_, err = ParseHNS("18446744073709.551616")
+	require.Error(t, err)
+	_, err = ParseHNS("18446744073710")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "amount is too large")
+}
+
+func TestParseRecipientsCSV(t *testing.T) {
+	addr := "rs1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqn6kda"
+	expAddr, err := chain.NewAddressFromBech32(addr)
+	require.NoError(t, err)
+	tests := []struct {
+		name     string
+		in       string
+		expected []uint64
+		err      string
+	}{
+		{
+			"with header",
+			"address,amount\n" + addr + ",1.5\n" + addr + ", 0.000001\n",
+			[]uint64{1500000, 1},
+			"",
+		},
+		{
+			"without header",
+			addr + ",10\n",
+			[]uint64{10000000},
+			"",
+		},
+		{
+			"comments",
+			"# payroll\n" + addr + ",2\n",
+			[]uint64{2000000},
+			"",
+		},
+		{
+			"invalid address",
+			"notanaddress,1\n",
+			nil,
+			"invalid address on line 1",
+		},
+		{
+			"too many decimals",
+			addr + ",1.0000001\n",
+			nil,
+			"more than 6 decimal places",
+		},
+		{
+			"negative amount",
+			addr + ",-1\n",
+			nil,
+			"invalid amount on line 1",
+		},
+		{
+			"overflowing amount",
+			addr + ",18446744073710\n",
+			nil,
+			"amount is too large",
+		},
+		{
+			"wrong field count",
+			addr + ",1,2\n",
+			nil,
+			"wrong number of fields",
+		},
+		{
+			"empty",
+			"address,amount\n",
+			nil,
+			"no recipients found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipients, err := ParseRecipientsCSV(strings.NewReader(tt.in))
+			if tt.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, recipients, len(tt.expected))
+			for i, recip := range recipients {
+				require.True(t, expAddr.Equal(recip.Address))
+				require.Equal(t, tt.expected[i], recip.Value)
+			}
+		})
+	}
+}
@@ -11,7 +11,7 @@ import (
 	"net/http"
 )
 
-func Start(tmb *tomb.Tomb, network *chain.Network, prefix, apiKey, nodeAPIKey, altNodeURL string) error {
+func Start(tmb *tomb.Tomb, network *chain.Network, prefix, apiKey, nodeAPIKey, altNodeURL string, opts ...wallet.NodeOption) error {
 	chain.SetCurrNetwork(network)
 	var nodeURL string
 	if altNodeURL == "" {
@@ -30,7 +30,7 @@ func Start(tmb *tomb.Tomb, network *chain.Network, prefix, apiKey, nodeAPIKey, a
 	}
 
 	bm := wallet.NewBlockMonitor(tmb, nodeClient, engine)
-	service := wallet.NewNode(tmb, network, engine, nodeClient, bm)
+	service := wallet.NewNode(tmb, network, engine, nodeClient, bm, opts...)
 	if err := service.Start(); err != nil {
 		return errors.Wrap(err, "error opening wallets")
 	}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// NextCursorHeader holds the cursor for the next page of transactions. It's
+// omitted on the last page.
+const NextCursorHeader = "X-Next-Cursor"
+
+// ParseTransactionFilter reads a transaction search from query parameters.
+func ParseTransactionFilter(q url.Values) (*walletdb.TransactionFilter, error) {
+	filter := &walletdb.TransactionFilter{
+		Name:       q.Get("name"),
+		NamePrefix: q.Get("name_prefix"),
+		Address:    q.Get("address"),
+		Direction:  strings.ToLower(q.Get("direction")),
+		Status:     strings.ToUpper(q.Get("status")),
+		Sort:       strings.ToLower(q.Get("sort")),
+		Count:      GetIntFromQuery(q, "count", 50),
+		Offset:     GetIntFromQuery(q, "offset", 0),
+	}
+
+	switch strings.ToLower(q.Get("order")) {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		return nil, errors.New("order must be asc or desc")
+	}
+
+	if cov := q.Get("covenant"); cov != "" {
+		covType, err := parseCovenantType(cov)
+		if err != nil {
+			return nil, err
+		}
+		filter.Covenant = &covType
+	}
+
+	if cursor := q.Get("cursor"); cursor != "" {
+		c, err := walletdb.ParseTransactionCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.Cursor = c
+	}
+
+	var err error
+	if filter.MinValue, err = optionalUint64(q, "min_value"); err != nil {
+		return nil, err
+	}
+	if filter.MaxValue, err = optionalUint64(q, "max_value"); err != nil {
+		return nil, err
+	}
+	if filter.MinHeight, err = optionalInt(q, "min_height"); err != nil {
+		return nil, err
+	}
+	if filter.MaxHeight, err = optionalInt(q, "max_height"); err != nil {
+		return nil, err
+	}
+	if filter.StartTime, err = optionalInt(q, "start_time"); err != nil {
+		return nil, err
+	}
+	if filter.EndTime, err = optionalInt(q, "end_time"); err != nil {
+		return nil, err
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// TransactionFilterQuery is the inverse of ParseTransactionFilter.
+func TransactionFilterQuery(filter *walletdb.TransactionFilter) url.Values {
+	q := PaginationQuery(filter.Count, filter.Offset)
+	setIfNotEmpty := func(key, val string) {
+		if val != "" {
+			q.Set(key, val)
+		}
+	}
+	setIfNotEmpty("name", filter.Name)
+	setIfNotEmpty("name_prefix", filter.NamePrefix)
+	setIfNotEmpty("address", filter.Address)
+	setIfNotEmpty("direction", filter.Direction)
+	setIfNotEmpty("status", filter.Status)
+	setIfNotEmpty("sort", filter.Sort)
+	if filter.Ascending {
+		q.Set("order", "asc")
+	}
+	if filter.Covenant != nil {
+		q.Set("covenant", filter.Covenant.String())
+	}
+	if filter.Cursor != nil {
+		q.Set("cursor", filter.Cursor.String())
+	}
+	if filter.MinValue != nil {
+		q.Set("min_value", strconv.FormatUint(*filter.MinValue, 10))
+	}
+	if filter.MaxValue != nil {
+		q.Set("max_value", strconv.FormatUint(*filter.MaxValue, 10))
+	}
+	for key, val := range map[string]*int{
+		"min_height": filter.MinHeight,
+		"max_height": filter.MaxHeight,
+		"start_time": filter.StartTime,
+		"end_time":   filter.EndTime,
+	} {
+		if val != nil {
+			q.Set(key, strconv.Itoa(*val))
+		}
+	}
+	return q
+}
+
+func parseCovenantType(s string) (chain.CovenantType, error) {
+	s = strings.ToUpper(s)
+	for covType := chain.CovenantNone; covType <= chain.CovenantRevoke; covType++ {
+		if covType.String() == s {
+			return covType, nil
+		}
+	}
+	return 0, errors.Errorf("invalid covenant type %s", s)
+}
+
+func optionalInt(q url.Values, key string) (*int, error) {
+	valStr := q.Get(key)
+	if valStr == "" {
+		return nil, nil
+	}
+	val, err := strconv.Atoi(valStr)
+	if err != nil {
+		return nil, errors.Errorf("invalid %s", key)
+	}
+	return &val, nil
+}
+
+func optionalUint64(q url.Values, key string) (*uint64, error) {
+	valStr := q.Get(key)
+	if valStr == "" {
+		return nil, nil
+	}
+	val, err := strconv.ParseUint(valStr, 10, 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid %s", key)
+	}
+	return &val, nil
+}
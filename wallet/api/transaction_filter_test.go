@@ -0,0 +1,62 @@
+package api
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseTransactionFilter(t *testing.T) {
+	q, err := url.ParseQuery("count=10&name=foo&covenant=bid&direction=IN&min_value=100&max_height=50&start_time=1600000000&status=confirmed&sort=time&order=asc")
+	require.NoError(t, err)
+	filter, err := ParseTransactionFilter(q)
+	require.NoError(t, err)
+
+	bid := chain.CovenantBid
+	minValue := uint64(100)
+	maxHeight := 50
+	startTime := 1600000000
+	require.Equal(t, &walletdb.TransactionFilter{
+		Name:      "foo",
+		Covenant:  &bid,
+		Direction: walletdb.TxDirectionIn,
+		MinValue:  &minValue,
+		MaxHeight: &maxHeight,
+		StartTime: &startTime,
+		Status:    walletdb.TxStatusConfirmed,
+		Sort:      walletdb.TxSortTime,
+		Ascending: true,
+		Count:     10,
+	}, filter)
+
+	roundTripped, err := ParseTransactionFilter(TransactionFilterQuery(filter))
+	require.NoError(t, err)
+	require.Equal(t, filter, roundTripped)
+
+	cursor := &walletdb.TransactionCursor{Key: 52, Hash: strings.Repeat("ab", 32)}
+	q = url.Values{"cursor": []string{cursor.String()}}
+	filter, err = ParseTransactionFilter(q)
+	require.NoError(t, err)
+	require.Equal(t, cursor, filter.Cursor)
+
+	for _, bad := range []string{
+		"covenant=nope",
+		"direction=sideways",
+		"status=lost",
+		"sort=value",
+		"order=up",
+		"min_value=-1",
+		"max_height=tall",
+		"cursor=!!!",
+		"cursor=NTI6eno",
+		"offset=10&covenant=bid",
+	} {
+		q, err := url.ParseQuery(bad)
+		require.NoError(t, err)
+		_, err = ParseTransactionFilter(q)
+		require.Error(t, err, bad)
+	}
+}
@@ -10,11 +10,67 @@ import (
 )
 
 type CreateAccountReq struct {
-	ID       string `json:"id"`
-	XPub     string `json:"xpub"`
-	Mnemonic string `json:"mnemonic"`
-	Password string `json:"password"`
-	Index    uint32 `json:"index"`
+	ID                   string   `json:"id"`
+	XPub                 string   `json:"xpub"`
+	Mnemonic             string   `json:"mnemonic"`
+	Passphrase           string   `json:"passphrase"`
+	Password             string   `json:"password"`
+	Index                uint32   `json:"index"`
+	Threshold            int      `json:"threshold"`
+	Cosigners            []string `json:"cosigners"`
+	Signer               string   `json:"signer"`
+	ReceiveGapLimit      uint32   `json:"receive_gap_limit"`
+	ChangeGapLimit       uint32   `json:"change_gap_limit"`
+	DutchAuctionGapLimit uint32   `json:"dutch_auction_gap_limit"`
+	Discover             bool     `json:"discover"`
+}
+
+type CreateWalletReq struct {
+	ID                   string `json:"id"`
+	Mnemonic             string `json:"mnemonic"`
+	Passphrase           string `json:"passphrase"`
+	Password             string `json:"password"`
+	ReceiveGapLimit      uint32 `json:"receive_gap_limit"`
+	ChangeGapLimit       uint32 `json:"change_gap_limit"`
+	DutchAuctionGapLimit uint32 `json:"dutch_auction_gap_limit"`
+	Discover             bool   `json:"discover"`
+}
+
+type CreateWalletRes struct {
+	ID       string  `json:"id"`
+	Mnemonic *string `json:"mnemonic"`
+}
+
+type GetWalletsRes struct {
+	Wallets []string `json:"wallets"`
+}
+
+type WalletAccount struct {
+	ID     string `json:"id"`
+	Index  uint32 `json:"index"`
+	Locked bool   `json:"locked"`
+}
+
+type WalletGetRes struct {
+	ID       string             `json:"id"`
+	Locked   bool               `json:"locked"`
+	Balances *walletdb.Balances `json:"balances"`
+	Accounts []*WalletAccount   `json:"accounts"`
+}
+
+type GetWalletAccountsRes struct {
+	Accounts []*WalletAccount `json:"accounts"`
+}
+
+// CreateWalletAccountReq derives a new account. Index defaults to the next
+// unused one.
+type CreateWalletAccountReq struct {
+	ID                   string  `json:"id"`
+	Password             string  `json:"password"`
+	Index                *uint32 `json:"index"`
+	ReceiveGapLimit      uint32  `json:"receive_gap_limit"`
+	ChangeGapLimit       uint32  `json:"change_gap_limit"`
+	DutchAuctionGapLimit uint32  `json:"dutch_auction_gap_limit"`
 }
 
 type CreateAccountRes struct {
@@ -27,21 +83,61 @@ type UnlockReq struct {
 	Password string `json:"password"`
 }
 
+type MnemonicReq struct {
+	Password string `json:"password"`
+}
+
+type MnemonicRes struct {
+	Mnemonic string `json:"mnemonic"`
+}
+
+// ChangePasswordReq re-encrypts a seed with a new password. KDF defaults to
+// wallet.DefaultKDFParams.
+type ChangePasswordReq struct {
+	OldPassword string            `json:"old_password"`
+	NewPassword string            `json:"new_password"`
+	KDF         *wallet.KDFParams `json:"kdf"`
+}
+
+func (r *ChangePasswordReq) KDFParams() wallet.KDFParams {
+	if r.KDF == nil {
+		return wallet.DefaultKDFParams
+	}
+	return *r.KDF
+}
+
 type AccountAddressDepth struct {
 	Receive uint32 `json:"receive"`
 	Change  uint32 `json:"change"`
 }
 
+type AccountGapLimit struct {
+	Receive      uint32 `json:"receive"`
+	Change       uint32 `json:"change"`
+	DutchAuction uint32 `json:"dutch_auction"`
+}
+
 type AccountGetRes struct {
-	ID             string               `json:"id"`
-	Index          uint32               `json:"index"`
-	Balances       *walletdb.Balances   `json:"balances"`
-	AddressDepth   *AccountAddressDepth `json:"address_depth"`
-	LookaheadDepth *AccountAddressDepth `json:"lookahead_depth"`
-	ReceiveAddress string               `json:"receive_address"`
-	ChangeAddress  string               `json:"change_address"`
-	XPub           string               `json:"xpub"`
-	RescanHeight   int                  `json:"rescan_height"`
+	ID              string               `json:"id"`
+	Index           uint32               `json:"index"`
+	Balances        *walletdb.Balances   `json:"balances"`
+	AddressDepth    *AccountAddressDepth `json:"address_depth"`
+	LookaheadDepth  *AccountAddressDepth `json:"lookahead_depth"`
+	GapLimit        *AccountGapLimit     `json:"gap_limit"`
+	ReceiveAddress  string               `json:"receive_address"`
+	ChangeAddress   string               `json:"change_address"`
+	XPub            string               `json:"xpub"`
+	RescanHeight    int                  `json:"rescan_height"`
+	Multisig        *AccountMultisig     `json:"multisig"`
+	AutoReveal      bool                 `json:"auto_reveal"`
+	AutoSweep       bool                 `json:"auto_sweep"`
+	AutoRegister    *chain.Resource      `json:"auto_register_resource"`
+	AutoRenewBlocks int                  `json:"auto_renew_blocks"`
+}
+
+type AccountMultisig struct {
+	Threshold int      `json:"threshold"`
+	Cosigners []string `json:"cosigners"`
 }
 
 type CoinsGetRes struct {
@@ -53,6 +149,7 @@ type GetNamesRes struct {
 }
 
 type GetNameRes struct {
+	Name    *walletdb.Name                   `json:"name"`
 	History []*walletdb.RichNameHistoryEntry `json:"history"`
 }
 
@@ -62,12 +159,14 @@ type GenAddressRes struct {
 }
 
 type CreateOpenReq struct {
+	CoinControl
 	Name       string `json:"name"`
 	FeeRate    uint64 `json:"fee_rate"`
 	CreateOnly bool   `json:"create_only"`
 }
 
 type CreateBidReq struct {
+	CoinControl
 	Name       string `json:"name"`
 	FeeRate    uint64 `json:"fee_rate"`
 	Value      uint64 `json:"value"`
@@ -76,18 +175,21 @@ type CreateBidReq struct {
 }
 
 type CreateRevealReq struct {
+	CoinControl
 	Name       string `json:"name"`
 	FeeRate    uint64 `json:"fee_rate"`
 	CreateOnly bool   `json:"create_only"`
 }
 
 type CreateRedeemReq struct {
+	CoinControl
 	Name       string `json:"name"`
 	FeeRate    uint64 `json:"fee_rate"`
 	CreateOnly bool   `json:"create_only"`
 }
 
 type CreateUpdateReq struct {
+	CoinControl
 	Name       string          `json:"name"`
 	Resource   *chain.Resource `json:"resource"`
 	FeeRate    uint64          `json:"fee_rate"`
@@ -95,6 +197,7 @@ type CreateUpdateReq struct {
 }
 
 type CreateTransferReq struct {
+	CoinControl
 	Name       string `json:"name"`
 	Address    string `json:"address"`
 	FeeRate    uint64 `json:"fee_rate"`
@@ -102,32 +205,139 @@ type CreateTransferReq struct {
 }
 
 type CreateFinalizeReq struct {
+	CoinControl
 	Name       string `json:"name"`
 	FeeRate    uint64 `json:"fee_rate"`
 	CreateOnly bool   `json:"create_only"`
 }
 
 type CreateRenewalsReq struct {
+	CoinControl
 	Name       string `json:"name"`
 	FeeRate    uint64 `json:"fee_rate"`
 	CreateOnly bool   `json:"create_only"`
 }
 
 type CreateRevokeReq struct {
+	CoinControl
 	Name       string `json:"name"`
 	FeeRate    uint64 `json:"fee_rate"`
 	CreateOnly bool   `json:"create_only"`
 }
 
 type CreateSendReq struct {
-	Value      uint64 `json:"value"`
-	Address    string `json:"address"`
-	FeeRate    uint64 `json:"fee_rate"`
-	CreateOnly bool   `json:"create_only"`
+	CoinControl
+	Value      uint64              `json:"value,omitempty"`
+	Address    string              `json:"address,omitempty"`
+	Outputs    []*wallet.Recipient `json:"outputs,omitempty"`
+	FeeRate    uint64              `json:"fee_rate"`
+	CreateOnly bool                `json:"create_only"`
+}
+
+type CreateBatchReq struct {
+	CoinControl
+	Actions    []*wallet.BatchAction `json:"actions"`
+	FeeRate    uint64                `json:"fee_rate"`
+	CreateOnly bool                  `json:"create_only"`
+}
+
+type CoinControl struct {
+	Coins         []*chain.Outpoint `json:"coins,omitempty"`
+	ExcludeCoins  []*chain.Outpoint `json:"exclude_coins,omitempty"`
+	CoinSelection string            `json:"coin_selection,omitempty"`
+	NoRBF         bool              `json:"no_rbf,omitempty"`
+}
+
+func (c CoinControl) TxOptions() []wallet.TxOption {
+	var opts []wallet.TxOption
+	if len(c.Coins) > 0 {
+		opts = append(opts, wallet.WithCoins(c.Coins...))
+	}
+	if len(c.ExcludeCoins) > 0 {
+		opts = append(opts, wallet.WithoutCoins(c.ExcludeCoins...))
+	}
+	if c.CoinSelection != "" {
+		opts = append(opts, wallet.WithCoinSelection(c.CoinSelection))
+	}
+	if c.NoRBF {
+		opts = append(opts, wallet.WithReplaceable(false))
+	}
+	return opts
+}
+
+type BumpFeeReq struct {
+	Method  string `json:"method"`
+	FeeRate uint64 `json:"fee_rate"`
+}
+
+type AutoRevealReq struct {
+	Enabled bool `json:"enabled"`
+}
+
+type AutoSweepReq struct {
+	Enabled  bool            `json:"enabled"`
+	Resource *chain.Resource `json:"resource,omitempty"`
+}
+
+// Event is an account event as received by a client. Data holds the
+// JSON encoding of the wallet package's event type for Type.
+type Event struct {
+	Type      wallet.EventType `json:"type"`
+	AccountID string           `json:"account_id"`
+	Data      json.RawMessage  `json:"data"`
+}
+
+type AutoRenewReq struct {
+	Blocks int `json:"blocks"`
+}
+
+type CreateWebhookReq struct {
+	URL string `json:"url"`
+}
+
+type GetWebhooksRes struct {
+	Webhooks []*walletdb.Webhook `json:"webhooks"`
+}
+
+type SetLabelReq struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+	Label  string `json:"label"`
+}
+
+type DeleteLabelReq struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+type GetAddressesRes struct {
+	Addresses []*walletdb.AddressInfo `json:"addresses"`
+}
+
+type GetLabelsRes struct {
+	Labels []*walletdb.Label `json:"labels"`
+}
+
+type CreateContactReq struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type DeleteContactReq struct {
+	Name string `json:"name"`
+}
+
+type GetContactsRes struct {
+	Contacts []*walletdb.Contact `json:"contacts"`
+}
+
+type FreezeCoinsReq struct {
+	Coins []*chain.Outpoint `json:"coins"`
 }
 
 type RescanReq struct {
-	Height int `json:"height"`
+	Height   int  `json:"height"`
+	Discover bool `json:"discover"`
 }
 
 type SignMessageReq struct {
@@ -144,6 +354,23 @@ type SignMessageWithNameReq struct {
 	Message string `json:"message"`
 }
 
+type VerifyMessageReq struct {
+	Address   string `json:"address"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+type VerifyMessageWithNameReq struct {
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+type VerifyMessageRes struct {
+	Valid   bool   `json:"valid"`
+	Address string `json:"address"`
+}
+
 type UnspentBidsRes struct {
 	UnspentBids []*wallet.UnspentBid `json:"unspent_bids"`
 }
@@ -163,21 +390,25 @@ type UpdateDutchAuctionListingsReq struct {
 }
 
 type DutchAuctionListingTransferReq struct {
+	CoinControl
 	Name    string
 	FeeRate uint64
 }
 
 type DutchAuctionListingFinalizeReq struct {
+	CoinControl
 	Name    string
 	FeeRate uint64
 }
 
 type DutchAuctionFillFinalizeReq struct {
+	CoinControl
 	Name    string
 	FeeRate uint64
 }
 
 type TransferDutchAuctionFillReq struct {
+	CoinControl
 	Name             string         `json:"name"`
 	LockScriptTxHash gcrypto.Hash   `json:"lock_script_tx_hash"`
 	LockScriptOutIdx uint32         `json:"lock_script_out_idx"`
@@ -192,15 +423,27 @@ type TransferDutchAuctionFillReq struct {
 }
 
 type DutchAuctionCancelTransferReq struct {
+	CoinControl
 	Name    string
 	FeeRate uint64
 }
 
 type DutchAuctionCancelFinalizeReq struct {
+	CoinControl
 	Name    string
 	FeeRate uint64
 }
 
+type PartialTxRes struct {
+	PartialTx *wallet.PartialTx `json:"partial_tx"`
+	Signed    int               `json:"signed"`
+	Complete  bool              `json:"complete"`
+}
+
+type PartialTxReq struct {
+	PartialTx *wallet.PartialTx `json:"partial_tx"`
+}
+
 type MultiRes struct {
 	Txs  []*chain.Transaction
 	Errs []error
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/base64"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/pkg/errors"
+	"net/http"
+)
+
+func (a *API) HandleVerifyMessagePOST(w http.ResponseWriter, r *http.Request) {
+	req := new(VerifyMessageReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	addr, err := chain.NewAddressFromBech32(req.Address)
+	if err != nil {
+		MarshalErrorJSON(w, errors.New("invalid address"), 400)
+		return
+	}
+	sig, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		MarshalErrorJSON(w, errors.New("signature must be base64"), 400)
+		return
+	}
+
+	ok, err := chain.VerifyMessage(addr, []byte(req.Message), sig)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, &VerifyMessageRes{
+		Valid:   ok,
+		Address: addr.String(),
+	})
+}
+
+func (a *API) HandleVerifyMessageWithNamePOST(w http.ResponseWriter, r *http.Request) {
+	req := new(VerifyMessageWithNameReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(req.Signature)
+	if err != nil {
+		MarshalErrorJSON(w, errors.New("signature must be base64"), 400)
+		return
+	}
+
+	addr, ok, err := a.node.VerifyMessageWithName(req.Name, []byte(req.Message), sig)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, &VerifyMessageRes{
+		Valid:   ok,
+		Address: addr.String(),
+	})
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/kurumiimari/gohan/wallet"
+	"github.com/pkg/errors"
+	"net/http"
+)
+
+func WalletParams(r *http.Request) string {
+	return mux.Vars(r)["walletID"]
+}
+
+func (a *API) HandleWalletsGET(w http.ResponseWriter, r *http.Request) {
+	MarshalResponseJSON(w, &GetWalletsRes{
+		Wallets: a.node.Wallets(),
+	})
+}
+
+func (a *API) HandleWalletsPOST(w http.ResponseWriter, r *http.Request) {
+	req := new(CreateWalletReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+	if req.Discover && req.Mnemonic == "" {
+		MarshalErrorJSON(w, errors.New("discovery is only supported when importing"), 400)
+		return
+	}
+
+	wal, mnemonic, err := a.node.CreateHDWallet(
+		req.ID,
+		req.Password,
+		req.Mnemonic,
+		req.Passphrase,
+		gapLimitOpts(req.ReceiveGapLimit, req.ChangeGapLimit, req.DutchAuctionGapLimit)...,
+	)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	if req.Discover {
+		acc, err := wal.Account(req.ID)
+		if err == nil {
+			err = acc.DiscoveryRescan(0)
+		}
+		if err != nil {
+			MarshalErrorJSON(w, err, 500)
+			return
+		}
+	}
+
+	res := &CreateWalletRes{
+		ID: req.ID,
+	}
+	if req.Mnemonic == "" {
+		res.Mnemonic = &mnemonic
+	}
+	MarshalResponseJSON(w, res)
+}
+
+func (a *API) HandleWalletGET(w http.ResponseWriter, r *http.Request) {
+	wal, err := a.node.Wallet(WalletParams(r))
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	balances, err := wal.Balances()
+	if err != nil {
+		MarshalErrorJSON(w, errors.Wrap(err, "error getting balances"), 500)
+		return
+	}
+
+	MarshalResponseJSON(w, &WalletGetRes{
+		ID:       wal.ID(),
+		Locked:   wal.Locked(),
+		Balances: balances,
+		Accounts: walletAccounts(wal),
+	})
+}
+
+func (a *API) HandleWalletUnlockPOST(w http.ResponseWriter, r *http.Request) {
+	wal, err := a.node.Wallet(WalletParams(r))
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(UnlockReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	if err := wal.Unlock(req.Password); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleWalletChangePasswordPOST(w http.ResponseWriter, r *http.Request) {
+	wal, err := a.node.Wallet(WalletParams(r))
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(ChangePasswordReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	if err := wal.ChangePassword(req.OldPassword, req.NewPassword, req.KDFParams()); err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleWalletMnemonicPOST(w http.ResponseWriter, r *http.Request) {
+	wal, err := a.node.Wallet(WalletParams(r))
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	req := new(MnemonicReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	mnemonic, err := wal.Mnemonic(req.Password)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+	MarshalResponseJSON(w, &MnemonicRes{
+		Mnemonic: mnemonic,
+	})
+}
+
+func (a *API) HandleWalletLockPOST(w http.ResponseWriter, r *http.Request) {
+	wal, err := a.node.Wallet(WalletParams(r))
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	wal.Lock()
+	w.WriteHeader(204)
+}
+
+func (a *API) HandleWalletAccountsGET(w http.ResponseWriter, r *http.Request) {
+	wal, err := a.node.Wallet(WalletParams(r))
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, &GetWalletAccountsRes{
+		Accounts: walletAccounts(wal),
+	})
+}
+
+func (a *API) HandleWalletAccountsPOST(w http.ResponseWriter, r *http.Request) {
+	walletID := WalletParams(r)
+	req := new(CreateWalletAccountReq)
+	if !UnmarshalRequestJSON(w, r, req) {
+		return
+	}
+
+	index := int64(-1)
+	if req.Index != nil {
+		index = int64(*req.Index)
+	}
+	acc, err := a.node.CreateWalletAccount(
+		walletID,
+		req.ID,
+		req.Password,
+		index,
+		gapLimitOpts(req.ReceiveGapLimit, req.ChangeGapLimit, req.DutchAuctionGapLimit)...,
+	)
+	if err != nil {
+		MarshalErrorJSON(w, err, 400)
+		return
+	}
+
+	MarshalResponseJSON(w, &WalletAccount{
+		ID:     acc.ID(),
+		Index:  acc.Index(),
+		Locked: acc.Locked(),
+	})
+}
+
+func walletAccounts(wal *wallet.Wallet) []*WalletAccount {
+	accounts := make([]*WalletAccount, 0)
+	for _, acc := range wal.Accounts() {
+		accounts = append(accounts, &WalletAccount{
+			ID:     acc.ID(),
+			Index:  acc.Index(),
+			Locked: acc.Locked(),
+		})
+	}
+	return accounts
+}
+
+func gapLimitOpts(recv, change, dutchAuction uint32) []wallet.CreateOption {
+	if recv == 0 && change == 0 && dutchAuction == 0 {
+		return nil
+	}
+	return []wallet.CreateOption{wallet.WithGapLimits(recv, change, dutchAuction)}
+}
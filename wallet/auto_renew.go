@@ -0,0 +1,134 @@
+package wallet
+
+import (
+	"github.com/kurumiimari/gohan/walletdb"
+)
+
+type renewalDecision int
+
+const (
+	// renewalWait means the name isn't renewable yet.
+	renewalWait renewalDecision = iota
+	// renewalRefresh means the node knows of a newer renewal than the
+	// wallet has indexed.
+	renewalRefresh
+	// renewalDue means the name should be renewed now.
+	renewalDue
+)
+
+// AutoRenewer renews an account's owned names once they come within the
+// account's configured number of blocks of expiring. Each name is renewed
+// in its own transaction so that one failure can't hold up the rest. Names
+// with an unconfirmed renewal in the wallet are skipped until it confirms
+// or disappears.
+type AutoRenewer struct {
+	acc *Account
+}
+
+func NewAutoRenewer(acc *Account) *AutoRenewer {
+	return &AutoRenewer{
+		acc: acc,
+	}
+}
+
+func (r *AutoRenewer) Start() error {
+	r.acc.onNewBlocks(r.acc.AutoRenew, func(height int) {
+		if err := r.onBlock(height); err != nil {
+			r.acc.lgr.Error("error auto-renewing names", "err", err)
+		}
+	})
+	return nil
+}
+
+func (r *AutoRenewer) onBlock(height int) error {
+	blocks := r.acc.AutoRenewBlocks()
+	window := r.acc.network.RenewalWindow
+
+	var due []*walletdb.Name
+	pending := make(map[string]bool)
+	err := r.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		due, err = walletdb.GetNamesDueForRenewal(tx, r.acc.id, height+blocks-window)
+		if err != nil {
+			return err
+		}
+		pendingNames, err := walletdb.GetPendingActionNames(tx, r.acc.id, walletdb.NameActionRenew)
+		for _, name := range pendingNames {
+			pending[name] = true
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, name := range due {
+		if !pending[name.Name] {
+			names = append(names, name.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	infos, err := r.acc.client.BatchGetNameInfo(names)
+	if err != nil {
+		return err
+	}
+
+	for i, info := range infos {
+		if info.Error != nil {
+			r.acc.lgr.Warning("error getting name info for auto-renew", "name", names[i], "err", info.Error)
+			continue
+		}
+		if info.Info.Info == nil || info.Info.Info.State != "CLOSED" {
+			continue
+		}
+		if info.Info.Info.Expired {
+			r.acc.lgr.Error("name expired before it could be auto-renewed", "name", names[i])
+			continue
+		}
+
+		// the node is authoritative, so pick up renewals we haven't
+		// indexed, e.g. names owned before renewal heights were tracked
+		renewal := info.Info.Info.Renewal
+		switch renewalAction(height, renewal, window, blocks, r.acc.network.TreeInterval) {
+		case renewalRefresh:
+			err := r.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+				return walletdb.UpdateNameRenewalHeight(tx, r.acc.id, info.Info.Info.NameHash, renewal)
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		case renewalWait:
+			continue
+		}
+
+		tx, err := r.acc.Renew(names[i], 0)
+		if err != nil {
+			r.acc.lgr.Error("error sending auto-renew", "name", names[i], "err", err)
+			continue
+		}
+		r.acc.lgr.Info("sent auto-renew", "name", names[i], "hash", tx.IDHex())
+		if err := r.acc.markAutomated(tx); err != nil {
+			r.acc.lgr.Error("error marking auto-renew in name history", "err", err)
+		}
+	}
+	return nil
+}
+
+// renewalAction decides what to do at height with a name the node last saw
+// renewed at renewal. Names are renewed once they're within blocks of the
+// end of the renewal window, and never within treeInterval blocks of their
+// last renewal.
+func renewalAction(height, renewal, window, blocks, treeInterval int) renewalDecision {
+	if renewal+window-blocks > height {
+		return renewalRefresh
+	}
+	if height < renewal+treeInterval {
+		return renewalWait
+	}
+	return renewalDue
+}
@@ -0,0 +1,120 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNameExpiry(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+
+	expiry, expiresAt := nameExpiry(1000, 1000, 105120, now)
+	require.Equal(t, 106120, expiry)
+	require.EqualValues(t, 1600000000+105120*600, expiresAt)
+
+	expiry, expiresAt = nameExpiry(1000, 106120, 105120, now)
+	require.Equal(t, 106120, expiry)
+	require.EqualValues(t, 1600000000, expiresAt)
+
+	expiry, expiresAt = nameExpiry(1000, 106130, 105120, now)
+	require.Equal(t, 106120, expiry)
+	require.EqualValues(t, 1600000000-10*600, expiresAt)
+}
+
+func TestRenewalAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		height   int
+		renewal  int
+		decision renewalDecision
+	}{
+		{"outside the auto-renew window", 1000, 500, renewalRefresh},
+		{"entering the auto-renew window", 1000, 200, renewalDue},
+		{"past the auto-renew window", 1100, 200, renewalDue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.decision, renewalAction(tt.height, tt.renewal, 1000, 200, 5))
+		})
+	}
+
+	// names renewed within the last tree interval wait for the renewal to
+	// settle
+	require.Equal(t, renewalWait, renewalAction(102, 100, 10, 10, 5))
+	require.Equal(t, renewalDue, renewalAction(105, 100, 10, 10, 5))
+}
+
+func TestGetPendingActionNames(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		entries := []struct {
+			name   string
+			typ    walletdb.NameHistoryType
+			height int
+		}{
+			{"alpha", walletdb.NameActionRenew, -1},
+			{"beta", walletdb.NameActionRenew, 10},
+			{"gamma", walletdb.NameActionUpdate, -1},
+		}
+		for i, entry := range entries {
+			hash := strings.Repeat(hex.EncodeToString([]byte{byte(i + 1)}), 32)
+			_, err := walletdb.UpsertTransaction(tx, "alice", &walletdb.Transaction{
+				Hash:        hash,
+				BlockHeight: entry.height,
+				BlockHash:   hex.EncodeToString(chain.ZeroHash),
+				Raw:         []byte{},
+				Time:        -1,
+			})
+			require.NoError(t, err)
+			hashB, err := hex.DecodeString(hash)
+			require.NoError(t, err)
+			require.NoError(t, walletdb.UpdateNameHistory(tx, &walletdb.NameHistory{
+				AccountID: "alice",
+				Name:      entry.name,
+				Type:      entry.typ,
+				Outpoint:  &chain.Outpoint{Hash: hashB, Index: 0},
+			}))
+		}
+
+		names, err := walletdb.GetPendingActionNames(tx, "alice", walletdb.NameActionRenew)
+		require.NoError(t, err)
+		require.Equal(t, []string{"alpha"}, names)
+
+		// dropped renewals no longer hold up the renewer
+		require.NoError(t, walletdb.DropTransaction(tx, "alice", strings.Repeat("01", 32)))
+		names, err = walletdb.GetPendingActionNames(tx, "alice", walletdb.NameActionRenew)
+		require.NoError(t, err)
+		require.Empty(t, names)
+		return nil
+	}))
+}
+
+func TestGetNamesWithoutRenewalHeight(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		require.NoError(t, walletdb.UpsertName(tx, "alice", "alpha", walletdb.NameStatusOwned))
+		require.NoError(t, walletdb.UpsertName(tx, "alice", "beta", walletdb.NameStatusOwned))
+		require.NoError(t, walletdb.UpsertName(tx, "alice", "gamma", walletdb.NameStatusTransferred))
+		require.NoError(t, walletdb.UpdateNameRenewalHeight(tx, "alice", chain.HashName("beta"), 100))
+
+		names, err := walletdb.GetNamesWithoutRenewalHeight(tx, "alice")
+		require.NoError(t, err)
+		require.Len(t, names, 1)
+		require.Equal(t, "alpha", names[0].Name)
+
+		require.NoError(t, walletdb.UpdateNameRenewalHeight(tx, "alice", chain.HashName("alpha"), 200))
+		names, err = walletdb.GetNamesWithoutRenewalHeight(tx, "alice")
+		require.NoError(t, err)
+		require.Empty(t, names)
+		return nil
+	}))
+}
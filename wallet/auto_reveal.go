@@ -0,0 +1,286 @@
+package wallet
+
+import (
+	"bytes"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/gcrypto"
+	"github.com/kurumiimari/gohan/walletdb"
+)
+
+const (
+	// autoRevealMaxBids caps the number of bids revealed per transaction
+	// to keep reveals well under the maximum transaction weight.
+	autoRevealMaxBids = 200
+
+	// autoRevealMaxFeeMultiplier caps how far reveal fees are raised
+	// above the estimated fee rate as the reveal deadline nears.
+	autoRevealMaxFeeMultiplier = 16
+)
+
+// pendingReveal is an unconfirmed reveal sent by the AutoRevealer. It's
+// read back from the database on every block, so fee escalation carries
+// over across restarts.
+type pendingReveal struct {
+	hash    gcrypto.Hash
+	names   []string
+	feeRate uint64
+}
+
+// revealCandidate is a name in its reveal period with bids left to reveal.
+type revealCandidate struct {
+	name     string
+	bids     int
+	deadline int
+}
+
+// revealBatch is a group of names revealed in one transaction. Its
+// deadline is the earliest of its names' last reveal heights.
+type revealBatch struct {
+	names    []string
+	deadline int
+}
+
+// AutoRevealer reveals an account's bids once their names enter the reveal
+// period. Reveals that remain unconfirmed during the second half of the
+// reveal period are replaced with ones paying twice the fee.
+type AutoRevealer struct {
+	acc *Account
+}
+
+func NewAutoRevealer(acc *Account) *AutoRevealer {
+	return &AutoRevealer{
+		acc: acc,
+	}
+}
+
+func (r *AutoRevealer) Start() error {
+	r.acc.onNewBlocks(r.acc.AutoReveal, func(height int) {
+		if err := r.onBlock(height); err != nil {
+			r.acc.lgr.Error("error auto-revealing bids", "err", err)
+		}
+	})
+	return nil
+}
+
+func (r *AutoRevealer) onBlock(height int) error {
+	baseRate := r.acc.estimateFeeRate()
+	if err := r.bumpPending(height, baseRate); err != nil {
+		return err
+	}
+
+	var names []string
+	err := r.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		names, err = walletdb.GetUnrevealedBidNames(tx, r.acc.id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	infos, err := r.acc.client.BatchGetNameInfo(names)
+	if err != nil {
+		return err
+	}
+
+	var candidates []*revealCandidate
+	for i, info := range infos {
+		if info.Error != nil {
+			r.acc.lgr.Warning("error getting name info for auto-reveal", "name", names[i], "err", info.Error)
+			continue
+		}
+		if info.Info.Info == nil || info.Info.Info.State != "REVEAL" {
+			continue
+		}
+
+		count, err := r.revealableBidCount(names[i], info.Info.Info.Stats.RevealPeriodStart)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+		candidates = append(candidates, &revealCandidate{
+			name:     names[i],
+			bids:     count,
+			deadline: info.Info.Info.Stats.RevealPeriodEnd - 1,
+		})
+	}
+
+	for _, batch := range planReveals(candidates, autoRevealMaxBids) {
+		feeRate := revealFeeRate(baseRate, batch.deadline-height, r.acc.network.RevealPeriod)
+		actions := make([]*BatchAction, len(batch.names))
+		for i, name := range batch.names {
+			actions[i] = &BatchAction{
+				Type: BatchActionReveal,
+				Name: name,
+			}
+		}
+
+		tx, err := r.acc.Batch(actions, feeRate)
+		if err != nil {
+			r.acc.lgr.Error("error sending auto-reveal", "err", err)
+			continue
+		}
+		r.acc.lgr.Info("sent auto-reveal", "hash", tx.IDHex(), "names", len(actions), "fee_rate", feeRate)
+		if err := r.acc.markAutomated(tx); err != nil {
+			r.acc.lgr.Error("error marking auto-reveal in name history", "err", err)
+		}
+	}
+	return nil
+}
+
+func (r *AutoRevealer) revealableBidCount(name string, revealHeight int) (int, error) {
+	var count int
+	err := r.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+		bids, err := walletdb.GetRevealableBids(tx, r.acc.id, name, r.acc.network, revealHeight)
+		count = len(bids)
+		return err
+	})
+	return count, err
+}
+
+func (r *AutoRevealer) bumpPending(height int, baseRate uint64) error {
+	pending, err := r.pendingReveals()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, reveal := range pending {
+		names = append(names, reveal.names...)
+	}
+	infos, err := r.acc.client.BatchGetNameInfo(names)
+	if err != nil {
+		return err
+	}
+	deadlines := make(map[string]int)
+	for i, info := range infos {
+		if info.Error != nil {
+			r.acc.lgr.Warning("error getting name info for auto-reveal bump", "name", names[i], "err", info.Error)
+			continue
+		}
+		if info.Info.Info == nil || info.Info.Info.State != "REVEAL" {
+			continue
+		}
+		deadlines[names[i]] = info.Info.Info.Stats.RevealPeriodEnd - 1
+	}
+
+	for _, reveal := range pending {
+		deadline := -1
+		for _, name := range reveal.names {
+			nameDeadline, ok := deadlines[name]
+			if ok && (deadline == -1 || nameDeadline < deadline) {
+				deadline = nameDeadline
+			}
+		}
+		if deadline == -1 {
+			r.acc.lgr.Warning("auto-reveal missed the reveal deadline", "hash", reveal.hash)
+			continue
+		}
+
+		feeRate, ok := revealBumpRate(reveal.feeRate, baseRate, deadline-height, r.acc.network.RevealPeriod)
+		if !ok {
+			continue
+		}
+		tx, err := r.acc.BumpFee(reveal.hash, BumpMethodRBF, feeRate)
+		if err != nil {
+			r.acc.lgr.Error("error bumping auto-reveal fee", "hash", reveal.hash, "err", err)
+			continue
+		}
+		r.acc.lgr.Info("bumped auto-reveal fee", "old_hash", reveal.hash, "new_hash", tx.IDHex(), "fee_rate", feeRate)
+	}
+	return nil
+}
+
+// pendingReveals returns the account's unconfirmed automated reveals along
+// with the fee rate each one currently pays.
+func (r *AutoRevealer) pendingReveals() ([]*pendingReveal, error) {
+	var pending []*pendingReveal
+	err := r.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+		dbTxs, err := walletdb.GetPendingAutomatedTransactions(tx, r.acc.id, walletdb.NameActionReveal)
+		if err != nil {
+			return err
+		}
+
+		for _, dbTx := range dbTxs {
+			reveal := new(chain.Transaction)
+			if _, err := reveal.ReadFrom(bytes.NewReader(dbTx.Raw)); err != nil {
+				return err
+			}
+			_, fee, err := r.acc.txFee(tx, reveal)
+			if err != nil {
+				return err
+			}
+			names, err := walletdb.GetNamesByTxHash(tx, r.acc.id, dbTx.Hash)
+			if err != nil {
+				return err
+			}
+			pending = append(pending, &pendingReveal{
+				hash:    reveal.ID(),
+				names:   names,
+				feeRate: feeRateCeil(fee, uint64(reveal.VirtualSize())),
+			})
+		}
+		return nil
+	})
+	return pending, err
+}
+
+// planReveals groups candidates into batches of at most maxBids bids. A
+// name with more than maxBids bids is revealed on its own.
+func planReveals(candidates []*revealCandidate, maxBids int) []*revealBatch {
+	var batches []*revealBatch
+	var batch *revealBatch
+	var bids int
+	for _, candidate := range candidates {
+		if batch == nil || bids+candidate.bids > maxBids {
+			batch = &revealBatch{
+				deadline: candidate.deadline,
+			}
+			batches = append(batches, batch)
+			bids = 0
+		}
+		batch.names = append(batch.names, candidate.name)
+		if candidate.deadline < batch.deadline {
+			batch.deadline = candidate.deadline
+		}
+		bids += candidate.bids
+	}
+	return batches
+}
+
+// revealFeeRate raises the fee rate as the reveal deadline approaches.
+func revealFeeRate(baseRate uint64, blocksLeft, revealPeriod int) uint64 {
+	switch {
+	case blocksLeft <= revealPeriod/4:
+		return baseRate * 4
+	case blocksLeft <= revealPeriod/2:
+		return baseRate * 2
+	default:
+		return baseRate
+	}
+}
+
+// revealBumpRate returns the fee rate a pending reveal paying feeRate
+// should be replaced at, and false if it should be left alone. Reveals are
+// only bumped in the second half of the reveal period, and never past
+// autoRevealMaxFeeMultiplier times baseRate.
+func revealBumpRate(feeRate, baseRate uint64, blocksLeft, revealPeriod int) (uint64, bool) {
+	maxRate := baseRate * autoRevealMaxFeeMultiplier
+	if blocksLeft < 0 || blocksLeft > revealPeriod/2 || feeRate >= maxRate {
+		return 0, false
+	}
+
+	feeRate *= 2
+	if feeRate > maxRate {
+		feeRate = maxRate
+	}
+	return feeRate, true
+}
@@ -0,0 +1,139 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/gcrypto"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/tomb.v2"
+	"testing"
+)
+
+func TestRevealFeeRate(t *testing.T) {
+	require.EqualValues(t, 100, revealFeeRate(100, 1000, 1440))
+	require.EqualValues(t, 100, revealFeeRate(100, 721, 1440))
+	require.EqualValues(t, 200, revealFeeRate(100, 720, 1440))
+	require.EqualValues(t, 200, revealFeeRate(100, 361, 1440))
+	require.EqualValues(t, 400, revealFeeRate(100, 360, 1440))
+	require.EqualValues(t, 400, revealFeeRate(100, 0, 1440))
+}
+
+func TestRevealBumpRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		feeRate    uint64
+		blocksLeft int
+		rate       uint64
+		bump       bool
+	}{
+		{"first half of the period", 100, 721, 0, false},
+		{"second half of the period", 100, 720, 200, true},
+		{"last block", 400, 0, 800, true},
+		{"missed deadline", 100, -1, 0, false},
+		{"capped", 1000, 10, 1600, true},
+		{"at the cap", 1600, 10, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, bump := revealBumpRate(tt.feeRate, 100, tt.blocksLeft, 1440)
+			require.Equal(t, tt.bump, bump)
+			require.Equal(t, tt.rate, rate)
+		})
+	}
+}
+
+func TestPlanReveals(t *testing.T) {
+	batches := planReveals([]*revealCandidate{
+		{name: "alpha", bids: 2, deadline: 30},
+		{name: "beta", bids: 1, deadline: 25},
+		{name: "gamma", bids: 2, deadline: 40},
+		{name: "delta", bids: 9, deadline: 50},
+		{name: "epsilon", bids: 1, deadline: 45},
+	}, 5)
+	require.Equal(t, []*revealBatch{
+		{names: []string{"alpha", "beta", "gamma"}, deadline: 25},
+		{names: []string{"delta"}, deadline: 50},
+		{names: []string{"epsilon"}, deadline: 45},
+	}, batches)
+	require.Empty(t, planReveals(nil, 5))
+}
+
+func TestPendingRevealsFromDB(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	ring := NewAccountKeyring(nil, testAccountKey(0).Neuter(), chain.NetworkRegtest)
+	addr := ring.Address(chain.ReceiveBranch, 0)
+	fundHash := gcrypto.Hash(bytes.Repeat([]byte{0x01}, 32))
+	prevout := &chain.Outpoint{Hash: fundHash, Index: 0}
+
+	newReveal := func(value uint64) *chain.Transaction {
+		return &chain.Transaction{
+			Inputs: []*chain.Input{
+				{Prevout: prevout, Sequence: chain.ReplaceableSequence},
+			},
+			Outputs: []*chain.Output{
+				{Value: value, Address: addr, Covenant: chain.EmptyCovenant},
+			},
+			Witnesses: []*chain.Witness{new(chain.Witness)},
+		}
+	}
+	automated := newReveal(900000)
+	manual := newReveal(950000)
+	confirmed := newReveal(980000)
+
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		_, err := walletdb.CreateAddress(tx, "alice", addr, chain.ReceiveBranch, 0)
+		require.NoError(t, err)
+
+		txs := []struct {
+			hash   gcrypto.Hash
+			raw    []byte
+			height int
+		}{
+			{fundHash, []byte{}, 10},
+			{automated.ID(), automated.Bytes(), -1},
+			{manual.ID(), manual.Bytes(), -1},
+			{confirmed.ID(), confirmed.Bytes(), 20},
+		}
+		for _, dbTx := range txs {
+			_, err := walletdb.UpsertTransaction(tx, "alice", &walletdb.Transaction{
+				Hash:        dbTx.hash.String(),
+				BlockHeight: dbTx.height,
+				BlockHash:   hex.EncodeToString(chain.ZeroHash),
+				Raw:         dbTx.raw,
+				Time:        -1,
+			})
+			require.NoError(t, err)
+		}
+		require.NoError(t, walletdb.CreateCoin(tx, "alice", prevout, 1000000, addr, chain.EmptyCovenant, false, walletdb.CoinTypeDefault))
+
+		for _, reveal := range []*chain.Transaction{automated, manual, confirmed} {
+			require.NoError(t, walletdb.UpdateNameHistory(tx, &walletdb.NameHistory{
+				AccountID: "alice",
+				Name:      "alpha",
+				Type:      walletdb.NameActionReveal,
+				Outpoint:  &chain.Outpoint{Hash: reveal.ID(), Index: 0},
+			}))
+		}
+		require.NoError(t, walletdb.MarkNameHistoryAutomated(tx, "alice", automated.IDHex()))
+		require.NoError(t, walletdb.MarkNameHistoryAutomated(tx, "alice", confirmed.IDHex()))
+		return nil
+	}))
+
+	// a fresh revealer, as after a restart, picks up the automated reveal
+	// still waiting to confirm
+	r := NewAutoRevealer(&Account{
+		tmb:    new(tomb.Tomb),
+		engine: engine,
+		id:     "alice",
+	})
+	pending, err := r.pendingReveals()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, automated.ID(), pending[0].hash)
+	require.Equal(t, []string{"alpha"}, pending[0].names)
+	require.Equal(t, feeRateCeil(100000, uint64(automated.VirtualSize())), pending[0].feeRate)
+}
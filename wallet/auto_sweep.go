@@ -0,0 +1,141 @@
+package wallet
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+)
+
+// autoSweepMaxActions caps the number of names swept per transaction.
+const autoSweepMaxActions = 200
+
+// AutoSweeper cleans up after closed auctions. Losing reveals are redeemed
+// so their lockups become spendable again, and won names are registered
+// with the account's auto-register resource.
+type AutoSweeper struct {
+	acc *Account
+}
+
+func NewAutoSweeper(acc *Account) *AutoSweeper {
+	return &AutoSweeper{
+		acc: acc,
+	}
+}
+
+func (s *AutoSweeper) Start() error {
+	s.acc.onNewBlocks(s.acc.AutoSweep, func(height int) {
+		if err := s.onBlock(); err != nil {
+			s.acc.lgr.Error("error sweeping closed auctions", "err", err)
+		}
+	})
+	return nil
+}
+
+func (s *AutoSweeper) onBlock() error {
+	var names []string
+	err := s.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		names, err = walletdb.GetUnspentRevealNames(tx, s.acc.id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	infos, err := s.acc.client.BatchGetNameInfo(names)
+	if err != nil {
+		return err
+	}
+
+	resource := s.acc.AutoRegisterResource()
+	var actions []*BatchAction
+	flush := func() {
+		if len(actions) == 0 {
+			return
+		}
+		tx, err := s.acc.Batch(actions, 0)
+		if err != nil {
+			s.acc.lgr.Error("error sending auto-sweep", "err", err)
+		} else {
+			s.acc.lgr.Info("sent auto-sweep", "hash", tx.IDHex(), "actions", len(actions))
+			if err := s.acc.markAutomated(tx); err != nil {
+				s.acc.lgr.Error("error marking auto-sweep in name history", "err", err)
+			}
+		}
+		actions = nil
+	}
+
+	for i, info := range infos {
+		if info.Error != nil {
+			s.acc.lgr.Warning("error getting name info for auto-sweep", "name", names[i], "err", info.Error)
+			continue
+		}
+		if info.Info.Info == nil || info.Info.Info.State != "CLOSED" {
+			continue
+		}
+
+		owner := &chain.Outpoint{
+			Hash:  info.Info.Info.Owner.Hash,
+			Index: info.Info.Info.Owner.Index,
+		}
+		won, lost, err := s.acc.classifyReveals(names[i], owner)
+		if err != nil {
+			return err
+		}
+
+		nameActions := sweepActions(names[i], won, lost, resource)
+		if len(actions)+len(nameActions) > autoSweepMaxActions {
+			flush()
+		}
+		actions = append(actions, nameActions...)
+	}
+	flush()
+	return nil
+}
+
+// classifyReveals reports whether the account holds the winning reveal for
+// name and whether it holds any losing reveals that can be redeemed.
+func (a *Account) classifyReveals(name string, owner *chain.Outpoint) (bool, bool, error) {
+	var coins []*walletdb.Coin
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		coins, err = walletdb.GetRedeemableReveals(tx, a.id, name)
+		return err
+	})
+	if err != nil {
+		return false, false, err
+	}
+
+	var won, lost bool
+	for _, coin := range coins {
+		if coin.Prevout.Equal(owner) {
+			won = true
+		} else {
+			lost = true
+		}
+	}
+	return won, lost, nil
+}
+
+// sweepActions returns the batch actions that clean up after a closed
+// auction for name. Losing reveals are redeemed and a winning reveal is
+// registered with resource.
+func sweepActions(name string, won, lost bool, resource *chain.Resource) []*BatchAction {
+	var actions []*BatchAction
+	if lost {
+		actions = append(actions, &BatchAction{
+			Type: BatchActionRedeem,
+			Name: name,
+		})
+	}
+	if won {
+		actions = append(actions, &BatchAction{
+			Type:     BatchActionUpdate,
+			Name:     name,
+			Resource: resource,
+		})
+	}
+	return actions
+}
@@ -0,0 +1,94 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/tomb.v2"
+	"testing"
+)
+
+func TestSweepActions(t *testing.T) {
+	resource := &chain.Resource{
+		Records: []chain.Record{
+			&chain.TXTRecord{
+				Entries: []string{"swept"},
+			},
+		},
+	}
+
+	require.Empty(t, sweepActions("alpha", false, false, resource))
+	require.Equal(t, []*BatchAction{
+		{Type: BatchActionRedeem, Name: "alpha"},
+	}, sweepActions("alpha", false, true, resource))
+	require.Equal(t, []*BatchAction{
+		{Type: BatchActionUpdate, Name: "alpha", Resource: resource},
+	}, sweepActions("alpha", true, false, resource))
+	require.Equal(t, []*BatchAction{
+		{Type: BatchActionRedeem, Name: "alpha"},
+		{Type: BatchActionUpdate, Name: "alpha", Resource: resource},
+	}, sweepActions("alpha", true, true, resource))
+}
+
+func TestClassifyReveals(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	ring := NewAccountKeyring(nil, testAccountKey(0).Neuter(), chain.NetworkRegtest)
+	addr := ring.Address(chain.ReceiveBranch, 0)
+	revealHash := bytes.Repeat([]byte{0x01}, 32)
+	winner := &chain.Outpoint{Hash: revealHash, Index: 0}
+	loser := &chain.Outpoint{Hash: revealHash, Index: 1}
+	covenant := func(name string) *chain.Covenant {
+		return &chain.Covenant{
+			Type:  chain.CovenantReveal,
+			Items: [][]byte{chain.HashName(name)},
+		}
+	}
+
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		_, err := walletdb.CreateAddress(tx, "alice", addr, chain.ReceiveBranch, 0)
+		require.NoError(t, err)
+		_, err = walletdb.UpsertTransaction(tx, "alice", &walletdb.Transaction{
+			Hash:        hex.EncodeToString(revealHash),
+			BlockHeight: 20,
+			BlockHash:   hex.EncodeToString(chain.ZeroHash),
+			Raw:         []byte{},
+			Time:        -1,
+		})
+		require.NoError(t, err)
+		require.NoError(t, walletdb.CreateCoin(tx, "alice", winner, 1000000, addr, covenant("alpha"), false, walletdb.CoinTypeDefault))
+		require.NoError(t, walletdb.CreateCoin(tx, "alice", loser, 2000000, addr, covenant("alpha"), false, walletdb.CoinTypeDefault))
+		require.NoError(t, walletdb.CreateCoin(tx, "alice", &chain.Outpoint{Hash: revealHash, Index: 2}, 1000000, addr, covenant("beta"), false, walletdb.CoinTypeDefault))
+		return nil
+	}))
+
+	acc := &Account{
+		tmb:    new(tomb.Tomb),
+		engine: engine,
+		id:     "alice",
+	}
+	other := &chain.Outpoint{Hash: bytes.Repeat([]byte{0x02}, 32), Index: 0}
+
+	won, lost, err := acc.classifyReveals("alpha", winner)
+	require.NoError(t, err)
+	require.True(t, won)
+	require.True(t, lost)
+
+	won, lost, err = acc.classifyReveals("alpha", other)
+	require.NoError(t, err)
+	require.False(t, won)
+	require.True(t, lost)
+
+	won, lost, err = acc.classifyReveals("beta", &chain.Outpoint{Hash: revealHash, Index: 2})
+	require.NoError(t, err)
+	require.True(t, won)
+	require.False(t, lost)
+
+	won, lost, err = acc.classifyReveals("gamma", other)
+	require.NoError(t, err)
+	require.False(t, won)
+	require.False(t, lost)
+}
@@ -0,0 +1,111 @@
+package wallet
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+)
+
+const (
+	BatchActionOpen   = "OPEN"
+	BatchActionBid    = "BID"
+	BatchActionReveal = "REVEAL"
+	BatchActionRedeem = "REDEEM"
+	BatchActionRenew  = "RENEW"
+	BatchActionUpdate = "UPDATE"
+)
+
+type BatchAction struct {
+	Type     string          `json:"type"`
+	Name     string          `json:"name"`
+	Value    uint64          `json:"value,omitempty"`
+	Lockup   uint64          `json:"lockup,omitempty"`
+	Resource *chain.Resource `json:"resource,omitempty"`
+}
+
+// Batch performs several name actions in a single transaction. Each name
+// may only appear once per action type, except for bids since a name can
+// be bid on more than once. Renewals and updates both spend the name's
+// coin, so they can't be combined for the same name.
+func (a *Account) Batch(actions []*BatchAction, feeRate uint64, opts ...TxOption) (*chain.Transaction, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if len(actions) == 0 {
+		return nil, errors.New("must specify at least one action")
+	}
+
+	seen := make(map[string]map[string]bool)
+	for i, action := range actions {
+		if action.Name == "" {
+			return nil, errors.Errorf("action %d: must specify a name", i)
+		}
+		types := seen[action.Name]
+		if types == nil {
+			types = make(map[string]bool)
+			seen[action.Name] = types
+		}
+		if types[action.Type] && action.Type != BatchActionBid {
+			return nil, errors.Errorf("action %d: name %s appears more than once", i, action.Name)
+		}
+		types[action.Type] = true
+		if types[BatchActionRenew] && types[BatchActionUpdate] {
+			return nil, errors.Errorf("action %d: name %s cannot be renewed and updated in the same batch", i, action.Name)
+		}
+	}
+
+	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
+		txb := new(TxBuilder)
+		var bids []*walletdb.NameHistory
+		// Covenants that spend a name's coin must sit at the same index as
+		// the input they spend, so those actions go first. Opens and bids
+		// only add outputs and follow them, which means each bid's outpoint
+		// index is already final when addBid records it.
+		for _, linked := range []bool{true, false} {
+			for i, action := range actions {
+				if isInputLinkedAction(action.Type) != linked {
+					continue
+				}
+
+				var err error
+				switch action.Type {
+				case BatchActionOpen:
+					err = a.addOpen(txb, action.Name)
+				case BatchActionBid:
+					var entry *walletdb.NameHistory
+					entry, err = a.addBid(txb, action.Name, action.Value, action.Lockup)
+					bids = append(bids, entry)
+				case BatchActionReveal:
+					err = a.addReveals(dTx, txb, action.Name)
+				case BatchActionRedeem:
+					err = a.addRedeems(dTx, txb, action.Name)
+				case BatchActionRenew:
+					err = a.addRenewal(dTx, txb, action.Name)
+				case BatchActionUpdate:
+					err = a.addUpdate(dTx, txb, action.Name, action.Resource)
+				default:
+					err = errors.Errorf("unknown action type %s", action.Type)
+				}
+				if err != nil {
+					return nil, errors.Wrapf(err, "action %d (%s %s)", i, action.Type, action.Name)
+				}
+			}
+		}
+
+		if chain.CountOpens(txb.Outputs) > chain.MaxTxOpens {
+			return nil, errors.Errorf("batch exceeds the limit of %d opens per transaction", chain.MaxTxOpens)
+		}
+		if chain.CountUpdates(txb.Outputs) > chain.MaxTxUpdates {
+			return nil, errors.Errorf("batch exceeds the limit of %d updates per transaction", chain.MaxTxUpdates)
+		}
+		if chain.CountRenewals(txb.Outputs) > chain.MaxTxRenewals {
+			return nil, errors.Errorf("batch exceeds the limit of %d renewals per transaction", chain.MaxTxRenewals)
+		}
+
+		return a.sendNameTx(dTx, txb, bids, feeRate, opts...)
+	})
+}
+
+func isInputLinkedAction(actionType string) bool {
+	return actionType != BatchActionOpen && actionType != BatchActionBid
+}
@@ -0,0 +1,138 @@
+package wallet
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+)
+
+type TxOption func(opts *txOptions)
+
+type txOptions struct {
+	coins         []*chain.Outpoint
+	excludeCoins  []*chain.Outpoint
+	coinSelection string
+	replaceable   bool
+	confirmedOnly bool
+}
+
+func newTxOptions(opts []TxOption) *txOptions {
+	txOpts := &txOptions{
+		replaceable: true,
+	}
+	for _, opt := range opts {
+		opt(txOpts)
+	}
+	return txOpts
+}
+
+// WithCoins funds a transaction using exactly the provided coins.
+func WithCoins(outpoints ...*chain.Outpoint) TxOption {
+	return func(opts *txOptions) {
+		opts.coins = append(opts.coins, outpoints...)
+	}
+}
+
+// WithoutCoins prevents the provided coins from being used to fund
+// a transaction.
+func WithoutCoins(outpoints ...*chain.Outpoint) TxOption {
+	return func(opts *txOptions) {
+		opts.excludeCoins = append(opts.excludeCoins, outpoints...)
+	}
+}
+
+// WithCoinSelection sets the strategy used to pick funding coins. See
+// NewCoinSelector for the available strategies.
+func WithCoinSelection(strategy string) TxOption {
+	return func(opts *txOptions) {
+		opts.coinSelection = strategy
+	}
+}
+
+// WithReplaceable controls whether a transaction signals replace-by-fee.
+// Transactions signal it by default so they can be bumped later.
+func WithReplaceable(replaceable bool) TxOption {
+	return func(opts *txOptions) {
+		opts.replaceable = replaceable
+	}
+}
+
+// withConfirmedCoins prevents unconfirmed coins from being used to fund a
+// transaction.
+func withConfirmedCoins() TxOption {
+	return func(opts *txOptions) {
+		opts.confirmedOnly = true
+	}
+}
+
+func (a *Account) FreezeCoins(outpoints []*chain.Outpoint, frozen bool) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	return a.engine.Transaction(func(tx walletdb.Transactor) error {
+		for _, outpoint := range outpoints {
+			if err := walletdb.UpdateCoinFrozen(tx, a.id, outpoint, frozen); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (a *Account) fundingCoins(q walletdb.Querier, txb *TxBuilder, opts *txOptions) ([]*chain.Coin, error) {
+	if len(opts.coins) == 0 {
+		dbCoins, err := walletdb.GetFundingCoins(q, a.id, a.network, a.rescanHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		excluded := make(map[string]bool)
+		for _, outpoint := range opts.excludeCoins {
+			excluded[outpoint.String()] = true
+		}
+
+		var coins []*chain.Coin
+		for _, dbCoin := range dbCoins {
+			if excluded[dbCoin.Prevout.String()] {
+				continue
+			}
+			if opts.confirmedOnly && dbCoin.Height < 0 {
+				continue
+			}
+			coins = append(coins, dbCoin.AsChain())
+		}
+		return coins, nil
+	}
+
+	dbCoins, err := walletdb.GetSpendableCoins(q, a.id, a.network, a.rescanHeight)
+	if err != nil {
+		return nil, err
+	}
+	spendable := make(map[string]*walletdb.Coin)
+	for _, dbCoin := range dbCoins {
+		spendable[dbCoin.Prevout.String()] = dbCoin
+	}
+
+	used := make(map[string]bool)
+	for _, coin := range txb.Coins {
+		used[coin.Prevout.String()] = true
+	}
+
+	// explicitly selected coins are all spent, and nothing
+	// else is added to the transaction besides change
+	for _, outpoint := range opts.coins {
+		if used[outpoint.String()] {
+			continue
+		}
+		dbCoin := spendable[outpoint.String()]
+		if dbCoin == nil {
+			return nil, errors.Errorf("coin %s is not spendable", outpoint)
+		}
+		if dbCoin.Frozen {
+			return nil, errors.Errorf("coin %s is frozen", outpoint)
+		}
+		txb.AddCoin(dbCoin.AsChain())
+		used[outpoint.String()] = true
+	}
+	return nil, nil
+}
@@ -0,0 +1,197 @@
+package wallet
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+const (
+	CoinSelectionBranchAndBound = "branch_and_bound"
+	CoinSelectionLargestFirst   = "largest_first"
+	CoinSelectionSmallestFirst  = "smallest_first"
+	CoinSelectionOldestFirst    = "oldest_first"
+
+	bnbMaxTries = 100000
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrMaxMoney is returned when a transaction's outputs add up to more than
+// chain.MaxMoney.
+var ErrMaxMoney = errors.New("total output value exceeds the maximum supply")
+
+// EffectiveCoin is a funding coin along with its value net of the
+// fee required to spend it.
+type EffectiveCoin struct {
+	*chain.Coin
+	EffectiveValue uint64
+}
+
+// CoinSelector picks coins whose combined effective value covers target.
+// costOfChange is the fee for adding a change output; selections that
+// overshoot target by less than costOfChange are left without change.
+type CoinSelector interface {
+	SelectCoins(candidates []*EffectiveCoin, target, costOfChange uint64) ([]*EffectiveCoin, error)
+}
+
+func NewCoinSelector(strategy string) (CoinSelector, error) {
+	switch strategy {
+	case "", CoinSelectionBranchAndBound:
+		return &BranchAndBoundSelector{
+			Fallback: SmallestFirstSelector,
+		}, nil
+	case CoinSelectionLargestFirst:
+		return LargestFirstSelector, nil
+	case CoinSelectionSmallestFirst:
+		return SmallestFirstSelector, nil
+	case CoinSelectionOldestFirst:
+		return OldestFirstSelector, nil
+	default:
+		return nil, errors.Errorf("unknown coin selection strategy %s", strategy)
+	}
+}
+
+// OrderedSelector greedily adds coins in the order defined by Less.
+type OrderedSelector struct {
+	Less func(a, b *chain.Coin) bool
+}
+
+var LargestFirstSelector = &OrderedSelector{
+	Less: func(a, b *chain.Coin) bool {
+		return a.Value > b.Value
+	},
+}
+
+var SmallestFirstSelector = &OrderedSelector{
+	Less: func(a, b *chain.Coin) bool {
+		return a.Value < b.Value
+	},
+}
+
+var OldestFirstSelector = &OrderedSelector{
+	Less: func(a, b *chain.Coin) bool {
+		// unconfirmed coins have a negative height
+		if a.Height < 0 || b.Height < 0 {
+			return a.Height >= 0 && b.Height < 0
+		}
+		return a.Height < b.Height
+	},
+}
+
+func (s *OrderedSelector) SelectCoins(candidates []*EffectiveCoin, target, costOfChange uint64) ([]*EffectiveCoin, error) {
+	sorted := make([]*EffectiveCoin, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return s.Less(sorted[i].Coin, sorted[j].Coin)
+	})
+
+	var selected []*EffectiveCoin
+	var total uint64
+	for _, coin := range sorted {
+		if total >= target {
+			excess := total - target
+			// keep going if the leftover can't pay for its own change output
+			if excess == 0 || excess >= costOfChange {
+				return selected, nil
+			}
+		}
+		selected = append(selected, coin)
+		total += coin.EffectiveValue
+	}
+	if total < target {
+		return nil, ErrInsufficientFunds
+	}
+	return selected, nil
+}
+
+// BranchAndBoundSelector searches for a set of coins that covers target
+// without needing a change output. If no such set exists, selection is
+// delegated to Fallback.
+type BranchAndBoundSelector struct {
+	Fallback CoinSelector
+}
+
+func (s *BranchAndBoundSelector) SelectCoins(candidates []*EffectiveCoin, target, costOfChange uint64) ([]*EffectiveCoin, error) {
+	if selected := branchAndBound(candidates, target, costOfChange); selected != nil {
+		return selected, nil
+	}
+	if s.Fallback == nil {
+		return nil, ErrInsufficientFunds
+	}
+	return s.Fallback.SelectCoins(candidates, target, costOfChange)
+}
+
+func branchAndBound(candidates []*EffectiveCoin, target, costOfChange uint64) []*EffectiveCoin {
+	sorted := make([]*EffectiveCoin, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].EffectiveValue > sorted[j].EffectiveValue
+	})
+
+	var available uint64
+	for _, coin := range sorted {
+		available += coin.EffectiveValue
+	}
+	if available < target {
+		return nil
+	}
+
+	upper := target + costOfChange
+	var best []bool
+	var bestWaste uint64
+	var total uint64
+	included := make([]bool, len(sorted))
+	depth := 0
+
+	for tries := 0; tries < bnbMaxTries; tries++ {
+		backtrack := false
+		if total+available < target || total > upper {
+			backtrack = true
+		} else if total >= target {
+			waste := total - target
+			if best == nil || waste < bestWaste {
+				best = make([]bool, len(included))
+				copy(best, included[:depth])
+				bestWaste = waste
+				if waste == 0 {
+					break
+				}
+			}
+			backtrack = true
+		}
+
+		if backtrack {
+			// walk back to the last included coin and exclude it instead
+			for depth > 0 && !included[depth-1] {
+				depth--
+				available += sorted[depth].EffectiveValue
+			}
+			if depth == 0 {
+				break
+			}
+			included[depth-1] = false
+			total -= sorted[depth-1].EffectiveValue
+			continue
+		}
+
+		if depth == len(sorted) {
+			break
+		}
+		available -= sorted[depth].EffectiveValue
+		included[depth] = true
+		total += sorted[depth].EffectiveValue
+		depth++
+	}
+
+	if best == nil {
+		return nil
+	}
+	var selected []*EffectiveCoin
+	for i, ok := range best {
+		if ok {
+			selected = append(selected, sorted[i])
+		}
+	}
+	return selected
+}
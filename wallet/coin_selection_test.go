@@ -0,0 +1,192 @@
+package wallet
+
+import (
+	"bytes"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestCoinSelectors(t *testing.T) {
+	candidates := []*EffectiveCoin{
+		testEffectiveCoin(1, 5000, 10),
+		testEffectiveCoin(2, 1000, 30),
+		testEffectiveCoin(3, 3000, -1),
+		testEffectiveCoin(4, 2000, 20),
+	}
+
+	tests := []struct {
+		name         string
+		strategy     string
+		target       uint64
+		costOfChange uint64
+		expected     []byte
+		err          error
+	}{
+		{
+			"largest first",
+			CoinSelectionLargestFirst,
+			5500,
+			0,
+			[]byte{1, 3},
+			nil,
+		},
+		{
+			"smallest first",
+			CoinSelectionSmallestFirst,
+			2500,
+			0,
+			[]byte{2, 4},
+			nil,
+		},
+		{
+			"smallest first adds coins to pay for change",
+			CoinSelectionSmallestFirst,
+			2500,
+			1000,
+			[]byte{2, 4, 3},
+			nil,
+		},
+		{
+			"oldest first skips unconfirmed coins",
+			CoinSelectionOldestFirst,
+			6500,
+			0,
+			[]byte{1, 4},
+			nil,
+		},
+		{
+			"branch and bound finds exact match",
+			CoinSelectionBranchAndBound,
+			6000,
+			0,
+			[]byte{1, 2},
+			nil,
+		},
+		{
+			"branch and bound within cost of change",
+			CoinSelectionBranchAndBound,
+			3900,
+			150,
+			[]byte{3, 2},
+			nil,
+		},
+		{
+			"branch and bound falls back",
+			CoinSelectionBranchAndBound,
+			10500,
+			100,
+			[]byte{2, 4, 3, 1},
+			nil,
+		},
+		{
+			"insufficient funds",
+			CoinSelectionLargestFirst,
+			12000,
+			0,
+			nil,
+			ErrInsufficientFunds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := NewCoinSelector(tt.strategy)
+			require.NoError(t, err)
+			selected, err := selector.SelectCoins(candidates, tt.target, tt.costOfChange)
+			if tt.err != nil {
+				require.Equal(t, tt.err, err)
+				return
+			}
+			require.NoError(t, err)
+			var actual []byte
+			for _, coin := range selected {
+				actual = append(actual, coin.Prevout.Hash[0])
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	_, err := NewCoinSelector("random")
+	require.Error(t, err)
+}
+
+func TestTxBuilderFund(t *testing.T) {
+	key := testAccountKey(0)
+	ring := NewAccountKeyring(NewEKPrivateKeyer(key), key.Neuter(), chain.NetworkRegtest)
+	coins := []*chain.Coin{
+		{
+			Value:      2000000000,
+			Address:    ring.Address(chain.ReceiveBranch, 0),
+			Covenant:   chain.EmptyCovenant,
+			Prevout:    &chain.Outpoint{Hash: bytes.Repeat([]byte{0x01}, 32), Index: 0},
+			Derivation: chain.Derivation{chain.ReceiveBranch, 0},
+		},
+	}
+
+	txb := new(TxBuilder)
+	txb.AddOutput(&chain.Output{
+		Value:    1000000,
+		Address:  ring.Address(chain.ReceiveBranch, 1),
+		Covenant: chain.EmptyCovenant,
+	})
+	require.NoError(t, txb.Fund(coins, ring.Address(chain.ChangeBranch, 0), 100))
+	require.Len(t, txb.Coins, 1)
+	require.Len(t, txb.Outputs, 2)
+	require.EqualValues(t, 1998986000, txb.Outputs[1].Value)
+
+	estimate := txb.EstimateSize()
+	require.NoError(t, txb.Sign(ring))
+	tx := txb.Build()
+	var witSize int
+	for _, wit := range tx.Witnesses {
+		witSize += wit.Size()
+	}
+	total := len(tx.Bytes())
+	vsize := ((total-witSize)*chain.WitnessScaleFactor + witSize + chain.WitnessScaleFactor - 1) / chain.WitnessScaleFactor
+	require.Equal(t, vsize, estimate)
+
+	t.Run("changeless", func(t *testing.T) {
+		txb := new(TxBuilder)
+		txb.AddOutput(&chain.Output{
+			Value:    1999988000,
+			Address:  ring.Address(chain.ReceiveBranch, 1),
+			Covenant: chain.EmptyCovenant,
+		})
+		require.NoError(t, txb.Fund(coins, ring.Address(chain.ChangeBranch, 0), 100))
+		require.Len(t, txb.Outputs, 1)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		txb := new(TxBuilder)
+		txb.AddOutput(&chain.Output{
+			Value:    2000000000,
+			Address:  ring.Address(chain.ReceiveBranch, 1),
+			Covenant: chain.EmptyCovenant,
+		})
+		require.Equal(t, ErrInsufficientFunds, txb.Fund(coins, ring.Address(chain.ChangeBranch, 0), 100))
+	})
+
+	t.Run("outputs exceed max money", func(t *testing.T) {
+		txb := new(TxBuilder)
+		for _, value := range []uint64{math.MaxUint64, 2} {
+			txb.AddOutput(&chain.Output{
+				Value:    value,
+				Address:  ring.Address(chain.ReceiveBranch, 1),
+				Covenant: chain.EmptyCovenant,
+			})
+		}
+		require.Equal(t, ErrMaxMoney, txb.Fund(coins, ring.Address(chain.ChangeBranch, 0), 100))
+	})
+}
+
+func testEffectiveCoin(id byte, value uint64, height int) *EffectiveCoin {
+	return &EffectiveCoin{
+		Coin: &chain.Coin{
+			Value:   value,
+			Height:  height,
+			Prevout: &chain.Outpoint{Hash: bytes.Repeat([]byte{id}, 32)},
+		},
+		EffectiveValue: value,
+	}
+}
@@ -0,0 +1,226 @@
+package wallet
+
+import (
+	"database/sql"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+// eventBufferSize is how many events a subscriber can fall behind by
+// before it's disconnected.
+const eventBufferSize = 128
+
+type EventType string
+
+const (
+	EventTransaction    EventType = "transaction"
+	EventConfirmation   EventType = "confirmation"
+	EventBalance        EventType = "balance"
+	EventNameStatus     EventType = "name_status"
+	EventRollback       EventType = "rollback"
+	EventRescanProgress EventType = "rescan_progress"
+	EventLock           EventType = "lock"
+)
+
+type Event struct {
+	Type      EventType   `json:"type"`
+	AccountID string      `json:"account_id"`
+	Data      interface{} `json:"data"`
+}
+
+type TransactionEvent struct {
+	Hash   string `json:"hash"`
+	Height int    `json:"height"`
+}
+
+type BalanceEvent struct {
+	Balances *walletdb.Balances `json:"balances"`
+}
+
+type NameStatusEvent struct {
+	Name   string             `json:"name"`
+	Status walletdb.NameState `json:"status"`
+}
+
+type RollbackEvent struct {
+	Height int `json:"height"`
+}
+
+type RescanProgressEvent struct {
+	Height      int `json:"height"`
+	ChainHeight int `json:"chain_height"`
+}
+
+type LockEvent struct {
+	Locked bool `json:"locked"`
+}
+
+// EventBus fans an account's events out to its subscribers. Publishing
+// never blocks: a subscriber that falls too far behind has its channel
+// closed, and is expected to resubscribe and refresh its state.
+type EventBus struct {
+	subs map[chan *Event]bool
+	mtx  sync.Mutex
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subs: make(map[chan *Event]bool),
+	}
+}
+
+// Subscribe returns a channel of events along with a function that
+// unsubscribes from them.
+func (b *EventBus) Subscribe() (<-chan *Event, func()) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	ch := make(chan *Event, eventBufferSize)
+	b.subs[ch] = true
+	return ch, func() {
+		b.mtx.Lock()
+		defer b.mtx.Unlock()
+		if b.subs[ch] {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (b *EventBus) HasSubscribers() bool {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return len(b.subs) > 0
+}
+
+func (b *EventBus) Publish(evt *Event) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- evt:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// eventSnapshot captures the parts of an account's state that events are
+// derived from, so that changes can be published once they're made.
+type eventSnapshot struct {
+	names    map[string]walletdb.NameState
+	webhooks bool
+}
+
+// Subscribe returns a channel of the account's events along with a
+// function that unsubscribes from them.
+func (a *Account) Subscribe() (<-chan *Event, func()) {
+	return a.events.Subscribe()
+}
+
+// publish sends events to subscribers. It must only be called once the
+// changes the events describe are committed.
+func (a *Account) publish(events ...*Event) {
+	for _, evt := range events {
+		if bal, ok := evt.Data.(*BalanceEvent); ok {
+			a.lastBalances = bal.Balances
+		}
+		evt.AccountID = a.id
+		a.events.Publish(evt)
+	}
+}
+
+// queueEvents holds events until the txTransactor call in progress commits.
+func (a *Account) queueEvents(events ...*Event) {
+	a.queuedEvents = append(a.queuedEvents, events...)
+}
+
+// snapshotEvents returns nil when nobody is subscribed and the account has
+// no webhooks, which skips the extra queries needed to compute events.
+func (a *Account) snapshotEvents(q walletdb.Transactor) (*eventSnapshot, error) {
+	webhooks, err := walletdb.HasWebhooks(q, a.id)
+	if err != nil {
+		return nil, err
+	}
+	if !webhooks && !a.events.HasSubscribers() {
+		a.lastBalances = nil
+		return nil, nil
+	}
+
+	names, err := walletdb.GetNameStatuses(q, a.id)
+	if err != nil {
+		return nil, err
+	}
+	return &eventSnapshot{
+		names:    names,
+		webhooks: webhooks,
+	}, nil
+}
+
+// stateEvents returns events for any name status and balance changes made
+// since snap was taken.
+func (a *Account) stateEvents(q walletdb.Transactor, snap *eventSnapshot) ([]*Event, error) {
+	if snap == nil {
+		return nil, nil
+	}
+
+	names, err := walletdb.GetNameStatuses(q, a.id)
+	if err != nil {
+		return nil, err
+	}
+	var events []*Event
+	for name, status := range names {
+		if snap.names[name] == status {
+			continue
+		}
+		events = append(events, &Event{
+			Type: EventNameStatus,
+			Data: &NameStatusEvent{
+				Name:   name,
+				Status: status,
+			},
+		})
+	}
+
+	balances, err := walletdb.GetBalances(q, a.id, a.network, a.rescanHeight)
+	if err != nil {
+		return nil, err
+	}
+	if a.lastBalances == nil || *a.lastBalances != *balances {
+		events = append(events, &Event{
+			Type: EventBalance,
+			Data: &BalanceEvent{
+				Balances: balances,
+			},
+		})
+	}
+	return events, nil
+}
+
+// txEvent returns the event for a transaction found in a block at height,
+// or nil if the account already knows it was confirmed.
+func (a *Account) txEvent(q walletdb.Transactor, tx *chain.Transaction, height int) (*Event, error) {
+	dbTx, err := walletdb.GetTransactionByOutpoint(q, a.id, tx.ID())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	evtType := EventTransaction
+	if dbTx != nil {
+		if dbTx.BlockHeight != -1 {
+			return nil, nil
+		}
+		evtType = EventConfirmation
+	}
+	return &Event{
+		Type: evtType,
+		Data: &TransactionEvent{
+			Hash:   tx.IDHex(),
+			Height: height,
+		},
+	}, nil
+}
@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEventBus(t *testing.T) {
+	bus := NewEventBus()
+	require.False(t, bus.HasSubscribers())
+
+	first, unsubFirst := bus.Subscribe()
+	second, unsubSecond := bus.Subscribe()
+	require.True(t, bus.HasSubscribers())
+
+	evt := &Event{Type: EventLock}
+	bus.Publish(evt)
+	require.Equal(t, evt, <-first)
+	require.Equal(t, evt, <-second)
+
+	unsubFirst()
+	unsubFirst()
+	_, ok := <-first
+	require.False(t, ok)
+
+	// subscribers that fall behind are disconnected
+	for i := 0; i <= eventBufferSize; i++ {
+		bus.Publish(evt)
+	}
+	for i := 0; i < eventBufferSize; i++ {
+		<-second
+	}
+	_, ok = <-second
+	require.False(t, ok)
+	require.False(t, bus.HasSubscribers())
+	unsubSecond()
+}
+
+func TestTxTransactorPublishesAfterCommit(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	acc := &Account{
+		engine: engine,
+		id:     "alice",
+		events: NewEventBus(),
+	}
+	evts, unsub := acc.Subscribe()
+	defer unsub()
+	balances := &walletdb.Balances{Available: 100}
+	balanceEvent := func() *Event {
+		return &Event{
+			Type: EventBalance,
+			Data: &BalanceEvent{Balances: balances},
+		}
+	}
+
+	// events from a rolled back transaction are discarded
+	_, err := acc.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
+		acc.queueEvents(balanceEvent())
+		return nil, errors.New("rolled back")
+	})
+	require.Error(t, err)
+	require.Empty(t, evts)
+	require.Nil(t, acc.lastBalances)
+
+	_, err = acc.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
+		acc.queueEvents(balanceEvent())
+		require.Empty(t, evts)
+		return nil, nil
+	})
+	require.NoError(t, err)
+	evt := <-evts
+	require.Equal(t, EventBalance, evt.Type)
+	require.Equal(t, "alice", evt.AccountID)
+	require.Equal(t, balances, acc.lastBalances)
+	require.Empty(t, evts)
+}
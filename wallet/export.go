@@ -0,0 +1,199 @@
+package wallet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const exportPageSize = 500
+
+// ExportRow summarizes one transaction's effect on an account for
+// bookkeeping. Values are in dollarydoos. Fee is only set when the account
+// funded the entire transaction, since otherwise someone else paid it.
+type ExportRow struct {
+	Time           int      `json:"time"`
+	Height         int      `json:"height"`
+	Hash           string   `json:"hash"`
+	Status         string   `json:"status"`
+	NetValue       int64    `json:"net_value"`
+	Fee            uint64   `json:"fee"`
+	Balance        int64    `json:"balance"`
+	Counterparties []string `json:"counterparties"`
+	Actions        []string `json:"actions"`
+	Names          []string `json:"names"`
+}
+
+var exportCSVHeader = []string{
+	"time",
+	"height",
+	"hash",
+	"status",
+	"net_value",
+	"fee",
+	"balance",
+	"counterparties",
+	"actions",
+	"names",
+}
+
+// Export returns every confirmed and pending transaction in the order it
+// affected the account's balance, oldest first. Dropped transactions are
+// left out since they never moved any funds.
+func (a *Account) Export() ([]*ExportRow, error) {
+	rows := make([]*ExportRow, 0)
+	err := a.engine.Transaction(func(q walletdb.Transactor) error {
+		var confirmed []*walletdb.RichTransaction
+		var pending []*walletdb.RichTransaction
+		filter := &walletdb.TransactionFilter{
+			Ascending: true,
+			Count:     exportPageSize,
+		}
+		for {
+			txs, next, err := walletdb.SearchTransactions(q, a.id, filter)
+			if err != nil {
+				return err
+			}
+			for _, tx := range txs {
+				switch tx.Status {
+				case walletdb.TxStatusConfirmed:
+					confirmed = append(confirmed, tx)
+				case walletdb.TxStatusPending:
+					pending = append(pending, tx)
+				}
+			}
+			if next == nil {
+				break
+			}
+			filter.Cursor = next
+		}
+
+		var balance int64
+		for _, tx := range append(confirmed, pending...) {
+			names, err := walletdb.GetNamesByTxHash(q, a.id, tx.Hash.String())
+			if err != nil {
+				return err
+			}
+			row := newExportRow(tx, names)
+			balance += row.NetValue
+			row.Balance = balance
+			rows = append(rows, row)
+		}
+		return nil
+	})
+	return rows, err
+}
+
+func newExportRow(tx *walletdb.RichTransaction, names []string) *ExportRow {
+	row := &ExportRow{
+		Time:           tx.Time,
+		Height:         tx.Height,
+		Hash:           tx.Hash.String(),
+		Status:         tx.Status,
+		NetValue:       int64(tx.Value),
+		Fee:            tx.Fee,
+		Counterparties: make([]string, 0),
+		Actions:        make([]string, 0),
+		Names:          names,
+	}
+	if tx.Direction == walletdb.TxDirectionOut {
+		row.NetValue = -row.NetValue
+	}
+	if row.Names == nil {
+		row.Names = make([]string, 0)
+	}
+
+	seen := make(map[string]bool)
+	addCounterparty := func(addr string) {
+		if seen[addr] {
+			return
+		}
+		seen[addr] = true
+		row.Counterparties = append(row.Counterparties, addr)
+	}
+	for _, in := range tx.Inputs {
+		if in.Coin != nil {
+			continue
+		}
+		if addr := witnessAddress(in.Witness); addr != nil {
+			addCounterparty(addr.String())
+		}
+	}
+	for _, out := range tx.Outputs {
+		if !out.Address.Own {
+			addCounterparty(out.Address.Address.String())
+		}
+	}
+
+	seenActions := make(map[chain.CovenantType]bool)
+	for _, out := range tx.Outputs {
+		covType := out.Covenant.Type
+		if covType == chain.CovenantNone || seenActions[covType] {
+			continue
+		}
+		seenActions[covType] = true
+		row.Actions = append(row.Actions, covType.String())
+	}
+	return row
+}
+
+// witnessAddress recovers the address spent by a pay-to-pubkey-hash input,
+// or returns nil for any other kind of witness.
+func witnessAddress(witness *chain.Witness) *chain.Address {
+	if witness == nil || len(witness.Items) != 2 || len(witness.Items[1]) != 33 {
+		return nil
+	}
+	pub, err := btcec.ParsePubKey(witness.Items[1], btcec.S256())
+	if err != nil {
+		return nil
+	}
+	return chain.NewAddressFromPubkey(pub)
+}
+
+// WriteExportCSV writes rows as CSV with a header. Values are in whole HNS
+// and times are in UTC. List columns are separated by spaces.
+func WriteExportCSV(w io.Writer, rows []*ExportRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVHeader); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, row := range rows {
+		var ts string
+		if row.Time > 0 {
+			ts = time.Unix(int64(row.Time), 0).UTC().Format(time.RFC3339)
+		}
+		err := cw.Write([]string{
+			ts,
+			strconv.Itoa(row.Height),
+			row.Hash,
+			row.Status,
+			formatHNS(row.NetValue),
+			formatHNS(int64(row.Fee)),
+			formatHNS(row.Balance),
+			strings.Join(row.Counterparties, " "),
+			strings.Join(row.Actions, " "),
+			strings.Join(row.Names, " "),
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	cw.Flush()
+	return errors.WithStack(cw.Error())
+}
+
+func formatHNS(value int64) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%06d", sign, value/1000000, value%1000000)
+}
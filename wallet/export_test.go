@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"bytes"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/gcrypto"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewExportRow(t *testing.T) {
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	require.NoError(t, err)
+	sender := chain.NewAddressFromPubkey(priv.PubKey())
+	recipient := chain.NewAddressFromHash(make([]byte, 20))
+	own := chain.NewAddressFromHash(bytes.Repeat([]byte{0x01}, 20))
+
+	tx := &walletdb.RichTransaction{
+		Hash:      gcrypto.Hash(make([]byte, 32)),
+		Height:    10,
+		Time:      1600000000,
+		Status:    walletdb.TxStatusConfirmed,
+		Direction: walletdb.TxDirectionOut,
+		Value:     1500,
+		Fee:       500,
+		Inputs: []*walletdb.RichInput{
+			{
+				Witness: &chain.Witness{Items: [][]byte{make([]byte, 65), priv.PubKey().SerializeCompressed()}},
+			},
+			{
+				Witness: &chain.Witness{Items: [][]byte{make([]byte, 65), make([]byte, 33)}},
+				Coin:    &walletdb.RichCoin{Value: 2000},
+			},
+		},
+		Outputs: []*walletdb.RichOutput{
+			{
+				Value:    1000,
+				Address:  &walletdb.RichAddress{Address: recipient},
+				Covenant: &chain.Covenant{Type: chain.CovenantNone},
+			},
+			{
+				Value:    500,
+				Address:  &walletdb.RichAddress{Address: own, Own: true},
+				Covenant: &chain.Covenant{Type: chain.CovenantBid},
+			},
+			{
+				Value:    0,
+				Address:  &walletdb.RichAddress{Address: own, Own: true},
+				Covenant: &chain.Covenant{Type: chain.CovenantBid},
+			},
+		},
+	}
+
+	row := newExportRow(tx, []string{"foo"})
+	require.Equal(t, &ExportRow{
+		Time:           1600000000,
+		Height:         10,
+		Hash:           tx.Hash.String(),
+		Status:         walletdb.TxStatusConfirmed,
+		NetValue:       -1500,
+		Fee:            500,
+		Counterparties: []string{sender.String(), recipient.String()},
+		Actions:        []string{"BID"},
+		Names:          []string{"foo"},
+	}, row)
+
+	row.Balance = 2500
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteExportCSV(buf, []*ExportRow{row}))
+	require.Equal(
+		t,
+		"time,height,hash,status,net_value,fee,balance,counterparties,actions,names\n"+
+			"2020-09-13T12:26:40Z,10,"+tx.Hash.String()+",CONFIRMED,-0.001500,0.000500,0.002500,"+
+			sender.String()+" "+recipient.String()+",BID,foo\n",
+		buf.String(),
+	)
+}
+
+func TestFormatHNS(t *testing.T) {
+	require.Equal(t, "0.000000", formatHNS(0))
+	require.Equal(t, "1.500000", formatHNS(1500000))
+	require.Equal(t, "-0.000001", formatHNS(-1))
+	require.Equal(t, "-12.345678", formatHNS(-12345678))
+}
@@ -0,0 +1,217 @@
+package wallet
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/pkg/errors"
+	"io"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ExternalSignerExec = "exec"
+	ExternalSignerUnix = "unix"
+
+	externalSignerTimeout = 2 * time.Minute
+)
+
+type ExternalSignerRequest struct {
+	Method     string     `json:"method"`
+	SigHash    string     `json:"sighash"`
+	Derivation []uint32   `json:"derivation"`
+	Tx         *TxSummary `json:"tx,omitempty"`
+	Message    string     `json:"message,omitempty"`
+}
+
+type ExternalSignerResponse struct {
+	Signature string `json:"signature"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ExternalSigner delegates signing to another process speaking
+// newline-delimited JSON, either over a child process' stdin/stdout
+// or a Unix socket.
+type ExternalSigner struct {
+	transport string
+	target    string
+	args      []string
+	mtx       sync.Mutex
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	stdout    *bufio.Reader
+}
+
+// NewExternalSigner parses an exec:<path> or unix:<path> signer URI. Exec
+// signers run the executable at path directly, without any arguments or
+// shell interpretation.
+func NewExternalSigner(uri string) (*ExternalSigner, error) {
+	splits := strings.SplitN(uri, ":", 2)
+	if len(splits) != 2 || splits[1] == "" {
+		return nil, errors.New("external signer must be of the form exec:<path> or unix:<path>")
+	}
+
+	switch splits[0] {
+	case ExternalSignerExec, ExternalSignerUnix:
+	default:
+		return nil, errors.Errorf("unknown external signer transport %s", splits[0])
+	}
+
+	return &ExternalSigner{
+		transport: splits[0],
+		target:    splits[1],
+	}, nil
+}
+
+// ParseExternalSigners parses name=<uri> signer definitions, as passed to
+// gohan start, into a map of signer names to URIs.
+func ParseExternalSigners(defs []string) (map[string]string, error) {
+	signers := make(map[string]string)
+	for _, def := range defs {
+		splits := strings.SplitN(def, "=", 2)
+		if len(splits) != 2 || splits[0] == "" {
+			return nil, errors.Errorf("external signer %s must be of the form <name>=<uri>", def)
+		}
+		if _, ok := signers[splits[0]]; ok {
+			return nil, errors.Errorf("duplicate external signer %s", splits[0])
+		}
+		if _, err := NewExternalSigner(splits[1]); err != nil {
+			return nil, err
+		}
+		signers[splits[0]] = splits[1]
+	}
+	return signers, nil
+}
+
+// unconfiguredSigner stands in for an external signer that an account
+// refers to but that the daemon wasn't started with.
+type unconfiguredSigner string
+
+func (u unconfiguredSigner) Sign(req *SignRequest) (*btcec.Signature, error) {
+	return nil, errors.Errorf("external signer %s is not configured", string(u))
+}
+
+func (e *ExternalSigner) Sign(req *SignRequest) (*btcec.Signature, error) {
+	extReq := &ExternalSignerRequest{
+		Method:     "sign",
+		SigHash:    hex.EncodeToString(req.SigHash),
+		Derivation: req.Derivation,
+		Tx:         req.Tx,
+	}
+	if req.Message != nil {
+		extReq.Method = "sign_message"
+		extReq.Message = hex.EncodeToString(req.Message)
+	}
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	var res *ExternalSignerResponse
+	var err error
+	if e.transport == ExternalSignerExec {
+		res, err = e.execRoundTrip(extReq)
+	} else {
+		res, err = e.unixRoundTrip(extReq)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error communicating with external signer")
+	}
+	if res.Error != "" {
+		return nil, errors.Errorf("external signer error: %s", res.Error)
+	}
+
+	sigB, err := hex.DecodeString(res.Signature)
+	if err != nil {
+		return nil, errors.Wrap(err, "external signer returned invalid signature hex")
+	}
+	return chain.DeserializeSignature(sigB)
+}
+
+func (e *ExternalSigner) Close() error {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return e.stopProcess()
+}
+
+func (e *ExternalSigner) execRoundTrip(req *ExternalSignerRequest) (*ExternalSignerResponse, error) {
+	if e.cmd == nil {
+		if err := e.startProcess(); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := writeReadSignerJSON(e.stdin, e.stdout, req)
+	if err != nil {
+		// the process is in an unknown state, so
+		// restart it on the next request
+		e.stopProcess()
+		return nil, err
+	}
+	return res, nil
+}
+
+func (e *ExternalSigner) startProcess() error {
+	cmd := exec.Command(e.target, e.args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "error starting external signer")
+	}
+
+	e.cmd = cmd
+	e.stdin = stdin
+	e.stdout = bufio.NewReader(stdout)
+	return nil
+}
+
+func (e *ExternalSigner) stopProcess() error {
+	if e.cmd == nil {
+		return nil
+	}
+	e.stdin.Close()
+	err := e.cmd.Process.Kill()
+	e.cmd.Wait()
+	e.cmd = nil
+	e.stdin = nil
+	e.stdout = nil
+	return err
+}
+
+func (e *ExternalSigner) unixRoundTrip(req *ExternalSignerRequest) (*ExternalSignerResponse, error) {
+	conn, err := net.DialTimeout("unix", e.target, 5*time.Second)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(externalSignerTimeout)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return writeReadSignerJSON(conn, bufio.NewReader(conn), req)
+}
+
+func writeReadSignerJSON(w io.Writer, r *bufio.Reader, req *ExternalSignerRequest) (*ExternalSignerResponse, error) {
+	if err := json.NewEncoder(w).Encode(req); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := new(ExternalSignerResponse)
+	if err := json.Unmarshal(line, res); err != nil {
+		return nil, errors.Wrap(err, "invalid external signer response")
+	}
+	return res, nil
+}
@@ -0,0 +1,203 @@
+package wallet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/txscript"
+	"github.com/stretchr/testify/require"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestExternalSigner(t *testing.T) {
+	derived := testAccountKey(0)
+	sockPath := filepath.Join(t.TempDir(), "signer.sock")
+	lis, err := net.Listen("unix", sockPath)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	var mtx sync.Mutex
+	var lastReq *ExternalSignerRequest
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			line, _ := r.ReadBytes('\n')
+			mtx.Lock()
+			lastReq = new(ExternalSignerRequest)
+			json.Unmarshal(line, lastReq)
+			mtx.Unlock()
+			conn.Write(stubSign(derived, line))
+			conn.Close()
+		}
+	}()
+
+	unixSigner, err := NewExternalSigner("unix:" + sockPath)
+	require.NoError(t, err)
+	execSigner := &ExternalSigner{
+		transport: ExternalSignerExec,
+		target:    os.Args[0],
+		args:      []string{"-test.run=TestExternalSignerHelperProcess"},
+	}
+
+	tests := []struct {
+		name   string
+		signer *ExternalSigner
+		setup  func()
+	}{
+		{
+			"unix socket",
+			unixSigner,
+			func() {},
+		},
+		{
+			"exec",
+			execSigner,
+			func() {
+				os.Setenv("GOHAN_TEST_SIGNER_PROCESS", "1")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+			defer os.Unsetenv("GOHAN_TEST_SIGNER_PROCESS")
+			signer := tt.signer
+			defer signer.Close()
+
+			ring := NewAccountKeyring(signer, derived.Neuter(), chain.NetworkRegtest)
+			txb := testSpendBuilder(ring)
+			require.NoError(t, txb.Sign(ring))
+			tx := txb.Build()
+			require.NoError(t, txscript.EngineStandardVerify(tx, 0, txb.Coins[0].Address, txb.Coins[0].Value))
+		})
+	}
+
+	mtx.Lock()
+	req := lastReq
+	mtx.Unlock()
+	require.NotNil(t, req)
+	require.Equal(t, "sign", req.Method)
+	require.Equal(t, []uint32{chain.ReceiveBranch, 0}, req.Derivation)
+	require.Equal(t, 0, req.Tx.InputIndex)
+	require.Equal(t, uint64(1000000), req.Tx.Value)
+	require.Len(t, req.Tx.Outputs, 1)
+	require.Equal(t, "NONE", req.Tx.Outputs[0].Covenant.Type)
+
+	t.Run("rejects signatures from the wrong key", func(t *testing.T) {
+		signer, err := NewExternalSigner("unix:" + sockPath)
+		require.NoError(t, err)
+		other := testAccountKey(1)
+		ring := NewAccountKeyring(signer, other.Neuter(), chain.NetworkRegtest)
+		txb := testSpendBuilder(ring)
+		require.Error(t, txb.Sign(ring))
+	})
+
+	t.Run("invalid uri", func(t *testing.T) {
+		_, err := NewExternalSigner("http://localhost")
+		require.Error(t, err)
+		_, err = NewExternalSigner("unix:")
+		require.Error(t, err)
+	})
+
+	t.Run("exec targets are not split", func(t *testing.T) {
+		signer, err := NewExternalSigner(fmt.Sprintf("exec:%s -test.run=TestExternalSignerHelperProcess", os.Args[0]))
+		require.NoError(t, err)
+		ring := NewAccountKeyring(signer, derived.Neuter(), chain.NetworkRegtest)
+		txb := testSpendBuilder(ring)
+		require.Error(t, txb.Sign(ring))
+	})
+}
+
+func TestParseExternalSigners(t *testing.T) {
+	signers, err := ParseExternalSigners([]string{
+		"ledger=exec:/usr/local/bin/ledger-signer",
+		"hsm=unix:/run/hsm.sock",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"ledger": "exec:/usr/local/bin/ledger-signer",
+		"hsm":    "unix:/run/hsm.sock",
+	}, signers)
+
+	for _, defs := range [][]string{
+		{"exec:/usr/local/bin/ledger-signer"},
+		{"=exec:/usr/local/bin/ledger-signer"},
+		{"ledger=http://localhost"},
+		{"ledger=unix:/run/a.sock", "ledger=unix:/run/b.sock"},
+	} {
+		_, err := ParseExternalSigners(defs)
+		require.Error(t, err, defs)
+	}
+}
+
+func TestExternalSignerHelperProcess(t *testing.T) {
+	if os.Getenv("GOHAN_TEST_SIGNER_PROCESS") != "1" {
+		return
+	}
+
+	derived := testAccountKey(0)
+	r := bufio.NewReader(os.Stdin)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		os.Stdout.Write(stubSign(derived, line))
+	}
+}
+
+func stubSign(ek chain.ExtendedKey, line []byte) []byte {
+	req := new(ExternalSignerRequest)
+	res := new(ExternalSignerResponse)
+	if err := json.Unmarshal(line, req); err != nil {
+		res.Error = err.Error()
+	} else {
+		hash, _ := hex.DecodeString(req.SigHash)
+		key, _ := chain.DeriveExtendedKey(ek, req.Derivation...).PrivateKey()
+		sig, err := key.Sign(hash)
+		if err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Signature = hex.EncodeToString(chain.SerializeSignature(sig))
+		}
+	}
+	out, _ := json.Marshal(res)
+	return append(out, '\n')
+}
+
+func testAccountKey(index uint32) chain.ExtendedKey {
+	mk := chain.NewMasterExtendedKeyFromMnemonic(Mnemonic, "", chain.NetworkRegtest)
+	return chain.DeriveExtendedKey(mk, chain.Derivation{
+		chain.HardenNode(chain.CoinPurpose),
+		chain.HardenNode(chain.NetworkRegtest.KeyPrefix.CoinType),
+		chain.HardenNode(index),
+	}...)
+}
+
+func testSpendBuilder(ring Keyring) *TxBuilder {
+	txb := new(TxBuilder)
+	txb.AddCoin(&chain.Coin{
+		Value:      1000000,
+		Address:    ring.Address(chain.ReceiveBranch, 0),
+		Covenant:   chain.EmptyCovenant,
+		Prevout:    &chain.Outpoint{Hash: bytes.Repeat([]byte{0x01}, 32), Index: 0},
+		Derivation: chain.Derivation{chain.ReceiveBranch, 0},
+	})
+	txb.AddOutput(&chain.Output{
+		Value:    900000,
+		Address:  ring.Address(chain.ChangeBranch, 0),
+		Covenant: chain.EmptyCovenant,
+	})
+	return txb
+}
@@ -0,0 +1,301 @@
+package wallet
+
+import (
+	"bytes"
+	"database/sql"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/gcrypto"
+	"github.com/kurumiimari/gohan/shakedex"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+)
+
+const (
+	BumpMethodRBF  = "rbf"
+	BumpMethodCPFP = "cpfp"
+
+	// MinRelayFeeRate is the lowest fee rate the wallet pays. It's also the
+	// incremental rate a replacement must pay on its own size on top of the
+	// fee of the transaction it replaces.
+	MinRelayFeeRate = 100
+)
+
+// BumpFee speeds up an unconfirmed transaction. With RBF the transaction
+// is replaced by one spending the same inputs at feeRate, and the original
+// is dropped from the wallet once the replacement is signed and broadcast.
+// With CPFP one of the transaction's outputs is spent back to the wallet
+// with a fee high enough for the pair to pay feeRate. A zero feeRate
+// doubles the original transaction's fee rate, but never goes below
+// MinRelayFeeRate.
+func (a *Account) BumpFee(hash gcrypto.Hash, method string, feeRate uint64) (*chain.Transaction, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if method == "" {
+		method = BumpMethodRBF
+	}
+	if method != BumpMethodRBF && method != BumpMethodCPFP {
+		return nil, errors.Errorf("unknown fee bump method %s", method)
+	}
+
+	return a.txTransactor(func(dTx walletdb.Transactor) (*chain.Transaction, error) {
+		dbTx, err := walletdb.GetTransactionByOutpoint(dTx, a.id, hash)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("transaction not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if dbTx.BlockHeight != -1 {
+			return nil, errors.New("transaction is already confirmed")
+		}
+		if dbTx.Dropped {
+			return nil, errors.New("transaction was dropped")
+		}
+
+		parent := new(chain.Transaction)
+		if _, err := parent.ReadFrom(bytes.NewReader(dbTx.Raw)); err != nil {
+			return nil, err
+		}
+
+		coins, parentFee, err := a.txFee(dTx, parent)
+		if err != nil {
+			return nil, err
+		}
+		parentSize := uint64(parent.VirtualSize())
+		parentRate := feeRateCeil(parentFee, parentSize)
+		if feeRate == 0 {
+			feeRate = parentRate * 2
+			if feeRate < MinRelayFeeRate {
+				feeRate = MinRelayFeeRate
+			}
+		}
+		if feeRate <= parentRate {
+			return nil, errors.Errorf("fee rate must exceed the transaction's current fee rate of %d", parentRate)
+		}
+
+		if method == BumpMethodRBF {
+			return a.replaceByFee(dTx, parent, coins, parentFee, feeRate)
+		}
+		return a.childPaysForParent(dTx, parent, parentFee, parentSize, feeRate)
+	})
+}
+
+// txFee returns the coins spent by tx and the fee it pays. Every input
+// must belong to the account.
+func (a *Account) txFee(q walletdb.Querier, tx *chain.Transaction) ([]*chain.Coin, uint64, error) {
+	var totalIn uint64
+	coins := make([]*chain.Coin, len(tx.Inputs))
+	for i, input := range tx.Inputs {
+		coin, err := walletdb.GetCoinByPrevout(q, a.id, input.Prevout)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, 0, errors.New("cannot bump transactions that spend coins belonging to other wallets")
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		coins[i] = coin.AsChain()
+		totalIn += coin.Value
+	}
+	var totalOut uint64
+	for _, out := range tx.Outputs {
+		totalOut += out.Value
+	}
+	return coins, totalIn - totalOut, nil
+}
+
+func (a *Account) replaceByFee(dTx walletdb.Transactor, parent *chain.Transaction, coins []*chain.Coin, parentFee, feeRate uint64) (*chain.Transaction, error) {
+	var replaceable bool
+	for _, input := range parent.Inputs {
+		if input.Sequence <= chain.ReplaceableSequence {
+			replaceable = true
+			break
+		}
+	}
+	if !replaceable {
+		return nil, errors.New("transaction does not signal replaceability, use cpfp instead")
+	}
+
+	parentHash := parent.IDHex()
+	spent, err := walletdb.HasSpentOutputs(dTx, a.id, parentHash)
+	if err != nil {
+		return nil, err
+	}
+	if spent {
+		return nil, errors.New("transaction has unconfirmed descendants")
+	}
+
+	txb := new(TxBuilder)
+	txb.Version = parent.Version
+	txb.Locktime = parent.LockTime
+	for _, coin := range coins {
+		if len(coin.Derivation) > 0 && coin.Derivation[0] == shakedex.AddressBranch {
+			return nil, errors.New("cannot replace dutch auction transactions, use cpfp instead")
+		}
+		txb.AddCoin(coin)
+	}
+
+	// the replacement's change output is appended by fundTx, so the
+	// original change output must be last to keep name history and
+	// auction records pointing at the right outputs
+	parentOutpoints := make([]*chain.Outpoint, len(parent.Outputs))
+	for i, out := range parent.Outputs {
+		parentOutpoints[i] = &chain.Outpoint{
+			Hash:  parent.ID(),
+			Index: uint32(i),
+		}
+
+		isChange, err := a.isChangeOutput(dTx, out)
+		if err != nil {
+			return nil, err
+		}
+		if !isChange {
+			txb.AddOutput(out)
+			continue
+		}
+		if i != len(parent.Outputs)-1 {
+			return nil, errors.New("cannot replace transaction with change in an unexpected position")
+		}
+	}
+
+	// replacements may not add unconfirmed inputs (BIP125 rule 2)
+	tx, err := a.fundTx(dTx, txb, feeRate, WithoutCoins(parentOutpoints...), withConfirmedCoins())
+	if err != nil {
+		return nil, err
+	}
+
+	// replacements must pay for their own relay on top of the
+	// original's fee (BIP125 rule 4)
+	var totalIn, totalOut uint64
+	for _, coin := range txb.Coins {
+		totalIn += coin.Value
+	}
+	for _, out := range tx.Outputs {
+		totalOut += out.Value
+	}
+	minFee := parentFee + MinRelayFeeRate*uint64(a.estimateTxSize(txb.Coins, tx.Outputs))
+	if totalIn-totalOut < minFee {
+		return nil, errors.Errorf("replacement must pay a fee of at least %d, use a higher fee rate", minFee)
+	}
+
+	// replacements that still need signatures are kept aside so the
+	// original stays in the wallet until the replacement is finalized
+	if !IsTxComplete(tx) {
+		err := walletdb.CreatePendingReplacement(dTx, a.id, &walletdb.PendingReplacement{
+			Hash:         tx.IDHex(),
+			ReplacesHash: parentHash,
+			Raw:          tx.Bytes(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		a.lgr.Info("created replacement awaiting signatures", "old_hash", parentHash, "new_hash", tx.IDHex())
+		return tx, nil
+	}
+
+	if err := a.commitReplacement(dTx, parentHash, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// commitReplacement swaps a signed replacement in for the transaction it
+// replaces and broadcasts it.
+func (a *Account) commitReplacement(dTx walletdb.Transactor, parentHash string, tx *chain.Transaction) error {
+	if err := walletdb.ReplaceTransactionHash(dTx, a.id, parentHash, tx.IDHex()); err != nil {
+		return err
+	}
+	if err := walletdb.DeleteTransaction(dTx, a.id, parentHash); err != nil {
+		return err
+	}
+	if err := a.sendTx(dTx, tx); err != nil {
+		return err
+	}
+	a.lgr.Info("replaced transaction", "old_hash", parentHash, "new_hash", tx.IDHex())
+	return nil
+}
+
+func (a *Account) childPaysForParent(dTx walletdb.Transactor, parent *chain.Transaction, parentFee, parentSize, feeRate uint64) (*chain.Transaction, error) {
+	var coin *walletdb.Coin
+	for i, out := range parent.Outputs {
+		if out.Covenant.Type != chain.CovenantNone {
+			continue
+		}
+		dbCoin, err := walletdb.GetCoinByPrevout(dTx, a.id, &chain.Outpoint{
+			Hash:  parent.ID(),
+			Index: uint32(i),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if dbCoin.Spent || dbCoin.Frozen {
+			continue
+		}
+		if coin == nil || dbCoin.Value > coin.Value {
+			coin = dbCoin
+		}
+	}
+	if coin == nil {
+		return nil, errors.New("transaction has no spendable outputs belonging to this account, use rbf instead")
+	}
+
+	txb := new(TxBuilder)
+	txb.AddCoin(coin.AsChain())
+	childSize := uint64(a.estimateTxSize(txb.Coins, []*chain.Output{
+		{
+			Address:  a.changeMgr.Address(),
+			Covenant: chain.EmptyCovenant,
+		},
+	}))
+	required := feeRate * (parentSize + childSize)
+	if required <= parentFee {
+		return nil, errors.New("transaction already pays the requested fee rate")
+	}
+	childRate := (required - parentFee + childSize - 1) / childSize
+
+	tx, err := a.fundTx(dTx, txb, childRate)
+	if err != nil {
+		return nil, err
+	}
+	if len(tx.Outputs) == 0 {
+		return nil, errors.New("output is too small to fund a child transaction")
+	}
+	if err := a.sendTx(dTx, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (a *Account) isChangeOutput(q walletdb.Querier, out *chain.Output) (bool, error) {
+	if out.Covenant.Type != chain.CovenantNone {
+		return false, nil
+	}
+	addr, err := walletdb.GetAddress(q, a.id, out.Address)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(addr.Derivation) > 0 && addr.Derivation[0] == chain.ChangeBranch, nil
+}
+
+// feeRateCeil rounds up so that a transaction paying fee is never treated
+// as paying less than its actual rate.
+func feeRateCeil(fee, size uint64) uint64 {
+	return (fee + size - 1) / size
+}
+
+func (a *Account) estimateTxSize(coins []*chain.Coin, outputs []*chain.Output) int {
+	txb := &TxBuilder{
+		Coins:   coins,
+		Outputs: outputs,
+	}
+	if a.msRing != nil {
+		txb.WitnessSize = a.msRing.WitnessSize
+	}
+	return txb.EstimateSize()
+}
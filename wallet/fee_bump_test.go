@@ -0,0 +1,44 @@
+package wallet
+
+import (
+	"database/sql"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestPendingReplacementStorage(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	parentHash := strings.Repeat("ab", 32)
+	replacement := &walletdb.PendingReplacement{
+		Hash:         strings.Repeat("cd", 32),
+		ReplacesHash: parentHash,
+		Raw:          []byte{0x01, 0x02},
+	}
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		require.NoError(t, walletdb.CreatePendingReplacement(tx, "alice", replacement))
+
+		stored, err := walletdb.GetPendingReplacement(tx, "alice", replacement.Hash)
+		require.NoError(t, err)
+		require.Equal(t, replacement, stored)
+		_, err = walletdb.GetPendingReplacement(tx, "bob", replacement.Hash)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		// removing the original discards replacements that were never
+		// finalized
+		require.NoError(t, walletdb.DeleteTransaction(tx, "alice", parentHash))
+		_, err = walletdb.GetPendingReplacement(tx, "alice", replacement.Hash)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		return nil
+	}))
+}
+
+func TestFeeRateCeil(t *testing.T) {
+	require.EqualValues(t, 0, feeRateCeil(0, 250))
+	require.EqualValues(t, 1, feeRateCeil(1, 250))
+	require.EqualValues(t, 100, feeRateCeil(25000, 250))
+	require.EqualValues(t, 101, feeRateCeil(25001, 250))
+}
@@ -20,7 +20,7 @@ type EKPrivateKeyer struct {
 	ek chain.ExtendedKey
 }
 
-func NewEKPrivateKeyer(ek chain.ExtendedKey) PrivateKeyer {
+func NewEKPrivateKeyer(ek chain.ExtendedKey) *EKPrivateKeyer {
 	return &EKPrivateKeyer{ek: ek}
 }
 
@@ -28,11 +28,19 @@ func (e EKPrivateKeyer) PrivateKey(path ...uint32) (*btcec.PrivateKey, error) {
 	return chain.DeriveExtendedKey(e.ek, path...).PrivateKey()
 }
 
+func (e EKPrivateKeyer) Sign(req *SignRequest) (*btcec.Signature, error) {
+	return signWithPrivateKeyer(e, req)
+}
+
 type KeyLocker struct {
-	box     SecretBox
-	ek      chain.ExtendedKey
-	mtx     sync.Mutex
-	network *chain.Network
+	box SecretBox
+	// mnemonicBox holds the mnemonic entropy. It's nil if the key wasn't
+	// created from a mnemonic.
+	mnemonicBox SecretBox
+	boxMtx      sync.Mutex
+	ek          chain.ExtendedKey
+	mtx         sync.Mutex
+	network     *chain.Network
 }
 
 func NewKeyLocker(box SecretBox, network *chain.Network) *KeyLocker {
@@ -45,16 +53,92 @@ func NewKeyLocker(box SecretBox, network *chain.Network) *KeyLocker {
 func (k *KeyLocker) Unlock(password string) error {
 	k.mtx.Lock()
 	defer k.mtx.Unlock()
-	priv, err := k.box.Decrypt(password)
+	ek, err := k.open(password)
+	if err != nil {
+		return err
+	}
+	k.ek = ek
+	return nil
+}
+
+// open decrypts the key without unlocking the locker.
+func (k *KeyLocker) open(password string) (chain.ExtendedKey, error) {
+	priv, err := k.secretBox().Decrypt(password)
 	if err != nil {
-		return ErrInvalidPassword
+		return nil, ErrInvalidPassword
 	}
 
-	k.ek, err = chain.NewMasterExtendedKeyFromString(string(priv), k.network)
+	ek, err := chain.NewMasterExtendedKeyFromString(string(priv), k.network)
 	if err != nil {
 		panic(err)
 	}
-	return nil
+	return ek, nil
+}
+
+// mnemonic decrypts the mnemonic the key was created from.
+func (k *KeyLocker) mnemonic(password string) (string, error) {
+	_, mnemonicBox := k.secretBoxes()
+	if mnemonicBox == nil {
+		return "", errors.New("no mnemonic is stored for this key")
+	}
+	return decryptMnemonic(mnemonicBox, password)
+}
+
+// rekey encrypts the key, and the mnemonic if there is one, with a new
+// password. The locker keeps its current boxes until setBoxes is called.
+func (k *KeyLocker) rekey(oldPassword, newPassword string, params KDFParams) (SecretBox, SecretBox, error) {
+	box, mnemonicBox := k.secretBoxes()
+	pt, err := box.Decrypt(oldPassword)
+	if err != nil {
+		return nil, nil, ErrInvalidPassword
+	}
+	box, err = NewArgon2AESGCM256SecretBox(pt, newPassword, params)
+	if err != nil || mnemonicBox == nil {
+		return box, nil, err
+	}
+
+	entropy, err := mnemonicBox.Decrypt(oldPassword)
+	if err != nil {
+		return nil, nil, ErrInvalidPassword
+	}
+	mnemonicBox, err = NewArgon2AESGCM256SecretBox(entropy, newPassword, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return box, mnemonicBox, nil
+}
+
+func (k *KeyLocker) secretBox() SecretBox {
+	box, _ := k.secretBoxes()
+	return box
+}
+
+func (k *KeyLocker) secretBoxes() (SecretBox, SecretBox) {
+	k.boxMtx.Lock()
+	defer k.boxMtx.Unlock()
+	return k.box, k.mnemonicBox
+}
+
+func (k *KeyLocker) setBoxes(box, mnemonicBox SecretBox) {
+	k.boxMtx.Lock()
+	defer k.boxMtx.Unlock()
+	k.box = box
+	k.mnemonicBox = mnemonicBox
+}
+
+func (k *KeyLocker) unlockWithKey(ek chain.ExtendedKey) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	k.ek = ek
+}
+
+func (k *KeyLocker) extendedKey() (chain.ExtendedKey, error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+	if k.ek == nil {
+		return nil, ErrLocked
+	}
+	return k.ek, nil
 }
 
 func (k *KeyLocker) Lock() {
@@ -79,8 +163,13 @@ func (k *KeyLocker) PrivateKey(path ...uint32) (*btcec.PrivateKey, error) {
 	return chain.DeriveExtendedKey(k.ek, path...).PrivateKey()
 }
 
+func (k *KeyLocker) Sign(req *SignRequest) (*btcec.Signature, error) {
+	return signWithPrivateKeyer(k, req)
+}
+
 type Keyring interface {
 	PrivateKeyer
+	Signer
 	IsPrivate() bool
 	PublicEK(path ...uint32) chain.ExtendedKey
 	Address(path ...uint32) *chain.Address
@@ -89,25 +178,25 @@ type Keyring interface {
 }
 
 type AccountKeyring struct {
-	priv    PrivateKeyer
+	signer  Signer
 	pub     chain.ExtendedKey
 	network *chain.Network
 }
 
 func NewAccountKeyring(
-	priv PrivateKeyer,
+	signer Signer,
 	pub chain.ExtendedKey,
 	network *chain.Network,
 ) *AccountKeyring {
 	return &AccountKeyring{
-		priv:    priv,
+		signer:  signer,
 		pub:     pub,
 		network: network,
 	}
 }
 
 func (k *AccountKeyring) IsPrivate() bool {
-	return k.priv != nil
+	return k.signer != nil
 }
 
 func (k *AccountKeyring) PublicEK(path ...uint32) chain.ExtendedKey {
@@ -119,7 +208,27 @@ func (k *AccountKeyring) PublicKey(path ...uint32) *btcec.PublicKey {
 }
 
 func (k *AccountKeyring) PrivateKey(path ...uint32) (*btcec.PrivateKey, error) {
-	return k.priv.PrivateKey(path...)
+	priv, ok := k.signer.(PrivateKeyer)
+	if !ok {
+		return nil, ErrNoPrivateKeys
+	}
+	return priv.PrivateKey(path...)
+}
+
+func (k *AccountKeyring) Sign(req *SignRequest) (*btcec.Signature, error) {
+	if k.signer == nil {
+		return nil, errors.New("keyring cannot sign")
+	}
+	sig, err := k.signer.Sign(req)
+	if err != nil {
+		return nil, err
+	}
+	// external signers are untrusted, so make sure
+	// the signature is valid before using it
+	if !sig.Verify(req.SigHash, k.PublicKey(req.Derivation...)) {
+		return nil, errors.New("signer returned an invalid signature")
+	}
+	return sig, nil
 }
 
 func (k *AccountKeyring) XPub(path ...uint32) string {
@@ -0,0 +1,218 @@
+package wallet
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxLabelLen       = 256
+	maxContactNameLen = 64
+)
+
+// SetLabel attaches a label to one of the account's addresses, transactions,
+// or coins, replacing any existing label.
+func (a *Account) SetLabel(targetType, target, label string) (*walletdb.Label, error) {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return nil, errors.New("label must not be empty")
+	}
+	if len(label) > maxLabelLen {
+		return nil, errors.Errorf("label must be at most %d characters", maxLabelLen)
+	}
+
+	var out *walletdb.Label
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		normalized, err := a.labelTarget(tx, targetType, target)
+		if err != nil {
+			return err
+		}
+		out, err = walletdb.SetLabel(tx, a.id, targetType, normalized, label, time.Now().Unix())
+		return err
+	})
+	return out, err
+}
+
+func (a *Account) Labels(targetType string) ([]*walletdb.Label, error) {
+	if targetType != "" {
+		if err := validateLabelType(targetType); err != nil {
+			return nil, err
+		}
+	}
+
+	var labels []*walletdb.Label
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		labels, err = walletdb.GetLabels(tx, a.id, targetType)
+		return err
+	})
+	return labels, err
+}
+
+func (a *Account) RemoveLabel(targetType, target string) error {
+	if err := validateLabelType(targetType); err != nil {
+		return err
+	}
+	normalized, err := normalizeLabelTarget(targetType, target)
+	if err != nil {
+		return err
+	}
+
+	err = a.engine.Transaction(func(tx walletdb.Transactor) error {
+		return walletdb.DeleteLabel(tx, a.id, targetType, normalized)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("label not found")
+	}
+	return err
+}
+
+func (a *Account) AddContact(name, address string) (*walletdb.Contact, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("contact name must not be empty")
+	}
+	if len(name) > maxContactNameLen {
+		return nil, errors.Errorf("contact name must be at most %d characters", maxContactNameLen)
+	}
+	addr, err := chain.NewAddressFromBech32(address)
+	if err != nil {
+		return nil, errors.New("invalid address")
+	}
+
+	var contact *walletdb.Contact
+	err = a.engine.Transaction(func(tx walletdb.Transactor) error {
+		if _, err := walletdb.GetContact(tx, a.id, name); err == nil {
+			return errors.New("a contact with that name already exists")
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		contacts, err := walletdb.GetContacts(tx, a.id)
+		if err != nil {
+			return err
+		}
+		for _, c := range contacts {
+			if c.Address == addr.String() {
+				return errors.Errorf("address already belongs to contact %s", c.Name)
+			}
+		}
+
+		contact, err = walletdb.CreateContact(tx, a.id, name, addr.String(), time.Now().Unix())
+		return err
+	})
+	return contact, err
+}
+
+func (a *Account) Contacts() ([]*walletdb.Contact, error) {
+	var contacts []*walletdb.Contact
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		contacts, err = walletdb.GetContacts(tx, a.id)
+		return err
+	})
+	return contacts, err
+}
+
+func (a *Account) RemoveContact(name string) error {
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		return walletdb.DeleteContact(tx, a.id, name)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("contact not found")
+	}
+	return err
+}
+
+// labelTarget normalizes target and checks that it belongs to the account.
+func (a *Account) labelTarget(q walletdb.Querier, targetType, target string) (string, error) {
+	if err := validateLabelType(targetType); err != nil {
+		return "", err
+	}
+	normalized, err := normalizeLabelTarget(targetType, target)
+	if err != nil {
+		return "", err
+	}
+
+	switch targetType {
+	case walletdb.LabelTypeAddress:
+		addr, _ := chain.NewAddressFromBech32(normalized)
+		_, err = walletdb.GetAddress(q, a.id, addr)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errors.New("address does not belong to this account, add it to the address book instead")
+		}
+	case walletdb.LabelTypeTransaction:
+		hash, _ := hex.DecodeString(normalized)
+		_, err = walletdb.GetTransactionByOutpoint(q, a.id, hash)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errors.New("transaction not found")
+		}
+	case walletdb.LabelTypeCoin:
+		prevout, _ := parseOutpoint(normalized)
+		_, err = walletdb.GetCoinByPrevout(q, a.id, prevout)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errors.New("coin not found")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+func validateLabelType(targetType string) error {
+	switch targetType {
+	case walletdb.LabelTypeAddress, walletdb.LabelTypeTransaction, walletdb.LabelTypeCoin:
+		return nil
+	default:
+		return errors.New("label type must be address, transaction, or coin")
+	}
+}
+
+func normalizeLabelTarget(targetType, target string) (string, error) {
+	switch targetType {
+	case walletdb.LabelTypeAddress:
+		addr, err := chain.NewAddressFromBech32(target)
+		if err != nil {
+			return "", errors.New("invalid address")
+		}
+		return addr.String(), nil
+	case walletdb.LabelTypeTransaction:
+		hash, err := hex.DecodeString(target)
+		if err != nil || len(hash) != 32 {
+			return "", errors.New("invalid transaction hash")
+		}
+		return hex.EncodeToString(hash), nil
+	default:
+		prevout, err := parseOutpoint(target)
+		if err != nil {
+			return "", err
+		}
+		return prevout.String(), nil
+	}
+}
+
+func parseOutpoint(in string) (*chain.Outpoint, error) {
+	splits := strings.Split(in, "/")
+	if len(splits) != 2 {
+		return nil, errors.New("invalid coin, must be of the form hash/index")
+	}
+	hash, err := hex.DecodeString(splits[0])
+	if err != nil || len(hash) != 32 {
+		return nil, errors.New("invalid coin hash")
+	}
+	index, err := strconv.ParseUint(splits[1], 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid coin index")
+	}
+	return &chain.Outpoint{
+		Hash:  hash,
+		Index: uint32(index),
+	}, nil
+}
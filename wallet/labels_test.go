@@ -0,0 +1,87 @@
+package wallet
+
+import (
+	"database/sql"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLabelTarget(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+
+	target, err := normalizeLabelTarget(walletdb.LabelTypeTransaction, strings.ToUpper(hash))
+	require.NoError(t, err)
+	require.Equal(t, hash, target)
+
+	target, err = normalizeLabelTarget(walletdb.LabelTypeCoin, hash+"/1")
+	require.NoError(t, err)
+	require.Equal(t, hash+"/1", target)
+
+	_, err = normalizeLabelTarget(walletdb.LabelTypeTransaction, "abcd")
+	require.Error(t, err)
+	_, err = normalizeLabelTarget(walletdb.LabelTypeCoin, hash)
+	require.Error(t, err)
+	_, err = normalizeLabelTarget(walletdb.LabelTypeAddress, "not an address")
+	require.Error(t, err)
+	require.Error(t, validateLabelType("name"))
+}
+
+func TestLabelStorage(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	own := "rs1qfvgr7evz60yrvavlwmhhgs3sh6rg5qdtslzny2"
+	other := "rs1q39ly7mn4473wc28jjcj8xr0vmzej3w3z7u8ds8"
+	coin := strings.Repeat("ab", 32) + "/0"
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		_, err := walletdb.SetLabel(tx, "alice", walletdb.LabelTypeAddress, own, "invoice #1", 1)
+		require.NoError(t, err)
+		label, err := walletdb.SetLabel(tx, "alice", walletdb.LabelTypeAddress, own, "invoice #123", 2)
+		require.NoError(t, err)
+		require.Equal(t, &walletdb.Label{
+			Type:      walletdb.LabelTypeAddress,
+			Target:    own,
+			Label:     "invoice #123",
+			CreatedAt: 1,
+		}, label)
+		_, err = walletdb.CreateContact(tx, "alice", "bob", other, 3)
+		require.NoError(t, err)
+
+		addrLabel, err := walletdb.GetAddressLabel(tx, "alice", own)
+		require.NoError(t, err)
+		require.Equal(t, "invoice #123", addrLabel)
+		addrLabel, err = walletdb.GetAddressLabel(tx, "alice", other)
+		require.NoError(t, err)
+		require.Equal(t, "bob", addrLabel)
+		addrLabel, err = walletdb.GetAddressLabel(tx, "bob", own)
+		require.NoError(t, err)
+		require.Equal(t, "", addrLabel)
+
+		coinLabel, err := walletdb.GetCoinLabel(tx, "alice", coin, own)
+		require.NoError(t, err)
+		require.Equal(t, "invoice #123", coinLabel)
+		_, err = walletdb.SetLabel(tx, "alice", walletdb.LabelTypeCoin, coin, "cold storage", 4)
+		require.NoError(t, err)
+		coinLabel, err = walletdb.GetCoinLabel(tx, "alice", coin, own)
+		require.NoError(t, err)
+		require.Equal(t, "cold storage", coinLabel)
+
+		labels, err := walletdb.GetLabels(tx, "alice", walletdb.LabelTypeCoin)
+		require.NoError(t, err)
+		require.Len(t, labels, 1)
+		labels, err = walletdb.GetLabels(tx, "alice", "")
+		require.NoError(t, err)
+		require.Len(t, labels, 2)
+
+		require.NoError(t, walletdb.DeleteLabel(tx, "alice", walletdb.LabelTypeCoin, coin))
+		require.ErrorIs(t, walletdb.DeleteLabel(tx, "alice", walletdb.LabelTypeCoin, coin), sql.ErrNoRows)
+		require.NoError(t, walletdb.DeleteContact(tx, "alice", "bob"))
+		require.ErrorIs(t, walletdb.DeleteContact(tx, "alice", "bob"), sql.ErrNoRows)
+		contacts, err := walletdb.GetContacts(tx, "alice")
+		require.NoError(t, err)
+		require.Empty(t, contacts)
+		return nil
+	}))
+}
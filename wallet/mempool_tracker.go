@@ -0,0 +1,120 @@
+package wallet
+
+import (
+	"bytes"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"time"
+)
+
+const (
+	// DefaultDropTimeout is how long a pending transaction may be missing
+	// from the node's mempool before it's marked dropped.
+	DefaultDropTimeout = 24 * time.Hour
+
+	mempoolCheckInterval = time.Minute
+)
+
+// MempoolTracker makes sure an account's pending transactions stay in the
+// node's mempool. Transactions that go missing are rebroadcast, and ones
+// that stay missing for longer than the drop timeout are marked dropped so
+// that the coins they spend can be used again. Transactions still awaiting
+// signatures are left alone.
+type MempoolTracker struct {
+	acc          *Account
+	timeout      time.Duration
+	missingSince map[string]time.Time
+}
+
+func NewMempoolTracker(acc *Account, timeout time.Duration) *MempoolTracker {
+	return &MempoolTracker{
+		acc:          acc,
+		timeout:      timeout,
+		missingSince: make(map[string]time.Time),
+	}
+}
+
+func (m *MempoolTracker) Start() error {
+	m.acc.tmb.Go(func() error {
+		tick := time.NewTicker(mempoolCheckInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-m.acc.tmb.Dying():
+				return nil
+			case <-tick.C:
+				if err := m.check(time.Now()); err != nil {
+					m.acc.lgr.Error("error checking pending transactions", "err", err)
+				}
+			}
+		}
+	})
+	return nil
+}
+
+func (m *MempoolTracker) check(now time.Time) error {
+	// a transaction that was just mined looks the same as one that was
+	// evicted until the block is indexed
+	if m.acc.RescanHeight() < m.acc.bm.LastHeight() {
+		return nil
+	}
+
+	var pending []*walletdb.Transaction
+	err := m.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		pending, err = walletdb.GetPendingTransactions(tx, m.acc.id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		m.missingSince = make(map[string]time.Time)
+		return nil
+	}
+
+	mempool, err := m.acc.client.GetRawMempool()
+	if err != nil {
+		return err
+	}
+	inMempool := make(map[string]bool)
+	for _, hash := range mempool {
+		inMempool[hash] = true
+	}
+
+	missingSince := make(map[string]time.Time)
+	for _, dbTx := range pending {
+		if inMempool[dbTx.Hash] {
+			continue
+		}
+
+		tx := new(chain.Transaction)
+		if _, err := tx.ReadFrom(bytes.NewReader(dbTx.Raw)); err != nil {
+			return err
+		}
+		if !IsTxComplete(tx) {
+			continue
+		}
+
+		since, ok := m.missingSince[dbTx.Hash]
+		if !ok {
+			since = now
+		}
+		if now.Sub(since) >= m.timeout {
+			if err := m.acc.dropTx(dbTx.Hash); err != nil {
+				return err
+			}
+			m.acc.lgr.Warning("dropped pending transaction", "hash", dbTx.Hash, "missing_for", now.Sub(since))
+			continue
+		}
+		missingSince[dbTx.Hash] = since
+
+		if _, err := m.acc.client.SendRawTransaction(dbTx.Raw); err != nil {
+			m.acc.lgr.Warning("error rebroadcasting transaction", "hash", dbTx.Hash, "err", err)
+			continue
+		}
+		m.acc.lgr.Info("rebroadcast pending transaction", "hash", dbTx.Hash)
+	}
+	m.missingSince = missingSince
+	return nil
+}
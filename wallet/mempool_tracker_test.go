@@ -0,0 +1,83 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDropTransactionRestoresNameStatus(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	registerHash := bytes.Repeat([]byte{0x01}, 32)
+	transferHash := bytes.Repeat([]byte{0x02}, 32)
+	finalizeHash := bytes.Repeat([]byte{0x03}, 32)
+	addr := testAccountKey(0).Neuter()
+	ring := NewAccountKeyring(nil, addr, chain.NetworkRegtest)
+
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		for i, hash := range [][]byte{registerHash, transferHash, finalizeHash} {
+			height := -1
+			if i == 0 {
+				height = 10
+			}
+			_, err := walletdb.UpsertTransaction(tx, "alice", &walletdb.Transaction{
+				Hash:        hex.EncodeToString(hash),
+				Idx:         0,
+				BlockHeight: height,
+				BlockHash:   hex.EncodeToString(chain.ZeroHash),
+				Raw:         []byte{},
+				Time:        -1,
+			})
+			require.NoError(t, err)
+		}
+
+		entries := []struct {
+			name  string
+			typ   walletdb.NameHistoryType
+			hash  []byte
+			state walletdb.NameState
+		}{
+			{"alpha", walletdb.NameActionRegister, registerHash, walletdb.NameStatusOwned},
+			{"alpha", walletdb.NameActionTransfer, transferHash, walletdb.NameStatusTransferring},
+			{"beta", walletdb.NameActionFinalizeIn, finalizeHash, walletdb.NameStatusOwned},
+		}
+		for _, entry := range entries {
+			require.NoError(t, walletdb.UpsertName(tx, "alice", entry.name, entry.state))
+			require.NoError(t, walletdb.UpdateNameHistory(tx, &walletdb.NameHistory{
+				AccountID: "alice",
+				Name:      entry.name,
+				Type:      entry.typ,
+				Outpoint:  &chain.Outpoint{Hash: entry.hash, Index: 0},
+			}))
+		}
+
+		// the finalize spends the transfer's change, so dropping the
+		// transfer drops it as well
+		changeOutpoint := &chain.Outpoint{Hash: transferHash, Index: 1}
+		require.NoError(t, walletdb.CreateCoin(
+			tx,
+			"alice",
+			changeOutpoint,
+			1000,
+			ring.Address(chain.ChangeBranch, 0),
+			chain.EmptyCovenant,
+			false,
+			walletdb.CoinTypeDefault,
+		))
+		require.NoError(t, walletdb.UpdateCoinSpent(tx, changeOutpoint, finalizeHash))
+
+		require.NoError(t, walletdb.DropTransaction(tx, "alice", hex.EncodeToString(transferHash)))
+
+		alpha, err := walletdb.GetName(tx, "alice", "alpha")
+		require.NoError(t, err)
+		require.Equal(t, walletdb.NameStatusOwned, alpha.Status)
+		_, err = walletdb.GetName(tx, "alice", "beta")
+		require.Error(t, err)
+		return nil
+	}))
+}
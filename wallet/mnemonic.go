@@ -0,0 +1,46 @@
+package wallet
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// encryptMnemonic encrypts the mnemonic's entropy, which is all that's
+// needed to show the same words again later. The BIP39 passphrase is never
+// stored.
+func encryptMnemonic(mnemonic, password string, params KDFParams) (SecretBox, error) {
+	entropy, err := bip39.EntropyFromMnemonic(mnemonic)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid mnemonic")
+	}
+	return NewArgon2AESGCM256SecretBox(entropy, password, params)
+}
+
+func decryptMnemonic(box SecretBox, password string) (string, error) {
+	entropy, err := box.Decrypt(password)
+	if err != nil {
+		return "", ErrInvalidPassword
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// marshalSecretBox encodes box for storage. A nil box encodes to an empty
+// string.
+func marshalSecretBox(box SecretBox) (string, error) {
+	if box == nil {
+		return "", nil
+	}
+	out, err := json.Marshal(box)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(out), nil
+}
+
+func unmarshalOptionalSecretBox(in string) (SecretBox, error) {
+	if in == "" {
+		return nil, nil
+	}
+	return UnmarshalSecretBox([]byte(in))
+}
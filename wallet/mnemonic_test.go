@@ -0,0 +1,31 @@
+package wallet
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMnemonicBox(t *testing.T) {
+	box, err := encryptMnemonic(Mnemonic, "password", DefaultKDFParams)
+	require.NoError(t, err)
+	enc, err := marshalSecretBox(box)
+	require.NoError(t, err)
+	require.NotContains(t, enc, "few derive")
+
+	box, err = unmarshalOptionalSecretBox(enc)
+	require.NoError(t, err)
+	mnemonic, err := decryptMnemonic(box, "password")
+	require.NoError(t, err)
+	require.Equal(t, Mnemonic, mnemonic)
+	_, err = decryptMnemonic(box, "wrong")
+	require.ErrorIs(t, err, ErrInvalidPassword)
+
+	_, err = encryptMnemonic("not a mnemonic", "password", DefaultKDFParams)
+	require.Error(t, err)
+	box, err = unmarshalOptionalSecretBox("")
+	require.NoError(t, err)
+	require.Nil(t, box)
+	enc, err = marshalSecretBox(nil)
+	require.NoError(t, err)
+	require.Empty(t, enc)
+}
@@ -0,0 +1,165 @@
+package wallet
+
+import (
+	"bytes"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/txscript"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+var ErrMultisigUnsupported = errors.New("operation not supported by multisig accounts")
+
+type MultisigKeyring struct {
+	*AccountKeyring
+	cosigners []chain.ExtendedKey
+	threshold int
+}
+
+func NewMultisigKeyring(ring *AccountKeyring, cosigners []chain.ExtendedKey, threshold int) *MultisigKeyring {
+	return &MultisigKeyring{
+		AccountKeyring: ring,
+		cosigners:      cosigners,
+		threshold:      threshold,
+	}
+}
+
+func (k *MultisigKeyring) Threshold() int {
+	return k.threshold
+}
+
+func (k *MultisigKeyring) Cosigners() []string {
+	out := make([]string, len(k.cosigners))
+	for i, cosigner := range k.cosigners {
+		out[i] = cosigner.PublicString()
+	}
+	return out
+}
+
+func (k *MultisigKeyring) PublicKeys(path ...uint32) [][]byte {
+	pubs := [][]byte{
+		k.AccountKeyring.PublicKey(path...).SerializeCompressed(),
+	}
+	for _, cosigner := range k.cosigners {
+		pubs = append(pubs, chain.DeriveExtendedKey(cosigner, path...).PublicKey().SerializeCompressed())
+	}
+	sort.Slice(pubs, func(i, j int) bool {
+		return bytes.Compare(pubs[i], pubs[j]) < 0
+	})
+	return pubs
+}
+
+func (k *MultisigKeyring) Script(path ...uint32) []byte {
+	script, err := txscript.NewMultisigScript(k.threshold, k.PublicKeys(path...))
+	if err != nil {
+		panic(err)
+	}
+	return script
+}
+
+func (k *MultisigKeyring) Address(path ...uint32) *chain.Address {
+	return chain.NewAddressFromScript(k.Script(path...))
+}
+
+func (k *MultisigKeyring) Witness(path ...uint32) *chain.Witness {
+	script := k.Script(path...)
+	items := make([][]byte, len(k.cosigners)+3)
+	for i := 0; i < len(items)-1; i++ {
+		items[i] = []byte{}
+	}
+	items[len(items)-1] = script
+	return &chain.Witness{Items: items}
+}
+
+// WitnessSize returns the size of a finalized witness spending from
+// one of the keyring's addresses.
+func (k *MultisigKeyring) WitnessSize(coin *chain.Coin) int {
+	script := k.Script(coin.Derivation...)
+	wit := &chain.Witness{
+		Items: [][]byte{{}},
+	}
+	for i := 0; i < k.threshold; i++ {
+		wit.Items = append(wit.Items, make([]byte, 65))
+	}
+	wit.Items = append(wit.Items, script)
+	return wit.Size()
+}
+
+func (k *MultisigKeyring) SignWitness(tx *chain.Transaction, idx int, coin *chain.Coin, wit *chain.Witness) (bool, error) {
+	script := wit.Items[len(wit.Items)-1]
+	_, pubs, err := txscript.ParseMultisigScript(script)
+	if err != nil {
+		return false, err
+	}
+	if len(wit.Items) != len(pubs)+2 {
+		return false, errors.New("multisig witness is already finalized")
+	}
+
+	ownPub := k.AccountKeyring.PublicKey(coin.Derivation...).SerializeCompressed()
+	slot := -1
+	for i, pub := range pubs {
+		if bytes.Equal(pub, ownPub) {
+			slot = i + 1
+			break
+		}
+	}
+	if slot == -1 || len(wit.Items[slot]) != 0 {
+		return false, nil
+	}
+
+	sig, err := SignInput(k.AccountKeyring, tx, idx, coin, script, txscript.SigHashAll)
+	if err != nil {
+		return false, err
+	}
+	wit.Items[slot] = sig
+	return true, nil
+}
+
+func IsTxComplete(tx *chain.Transaction) bool {
+	for _, wit := range tx.Witnesses {
+		if !isWitnessComplete(wit) {
+			return false
+		}
+	}
+	return true
+}
+
+func FinalizeMultisigWitnesses(tx *chain.Transaction) {
+	for i, wit := range tx.Witnesses {
+		threshold, sigs, ok := multisigWitnessSigs(wit)
+		if !ok || len(sigs) < threshold {
+			continue
+		}
+
+		items := [][]byte{{}}
+		items = append(items, sigs[:threshold]...)
+		items = append(items, wit.Items[len(wit.Items)-1])
+		tx.Witnesses[i] = &chain.Witness{Items: items}
+	}
+}
+
+func isWitnessComplete(wit *chain.Witness) bool {
+	if len(wit.Items) == 0 {
+		return false
+	}
+	threshold, sigs, ok := multisigWitnessSigs(wit)
+	return !ok || len(sigs) >= threshold
+}
+
+func multisigWitnessSigs(wit *chain.Witness) (int, [][]byte, bool) {
+	if len(wit.Items) < 3 || len(wit.Items[0]) != 0 {
+		return 0, nil, false
+	}
+	threshold, _, err := txscript.ParseMultisigScript(wit.Items[len(wit.Items)-1])
+	if err != nil {
+		return 0, nil, false
+	}
+
+	var sigs [][]byte
+	for _, item := range wit.Items[1 : len(wit.Items)-1] {
+		if len(item) != 0 {
+			sigs = append(sigs, item)
+		}
+	}
+	return threshold, sigs, true
+}
@@ -0,0 +1,76 @@
+package wallet
+
+import (
+	"bytes"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMultisigKeyring(t *testing.T) {
+	mk := chain.NewMasterExtendedKeyFromMnemonic(Mnemonic, "", chain.NetworkRegtest)
+	var keys []chain.ExtendedKey
+	for i := uint32(0); i < 3; i++ {
+		keys = append(keys, chain.DeriveExtendedKey(mk, chain.Derivation{
+			chain.HardenNode(chain.CoinPurpose),
+			chain.HardenNode(chain.NetworkRegtest.KeyPrefix.CoinType),
+			chain.HardenNode(i),
+		}...))
+	}
+
+	var rings []*MultisigKeyring
+	for i, key := range keys {
+		var cosigners []chain.ExtendedKey
+		for j, other := range keys {
+			if i != j {
+				cosigners = append(cosigners, other.Neuter())
+			}
+		}
+		ring := NewAccountKeyring(NewEKPrivateKeyer(key), key.Neuter(), chain.NetworkRegtest)
+		rings = append(rings, NewMultisigKeyring(ring, cosigners, 2))
+	}
+
+	addr := rings[0].Address(chain.ReceiveBranch, 0)
+	require.True(t, addr.IsScriptHash())
+	for _, ring := range rings[1:] {
+		require.True(t, addr.Equal(ring.Address(chain.ReceiveBranch, 0)))
+	}
+
+	txb := new(TxBuilder)
+	txb.AddCoin(&chain.Coin{
+		Value:      1000000,
+		Address:    addr,
+		Covenant:   chain.EmptyCovenant,
+		Prevout:    &chain.Outpoint{Hash: bytes.Repeat([]byte{0x01}, 32), Index: 0},
+		Derivation: chain.Derivation{chain.ReceiveBranch, 0},
+	})
+	txb.AddOutput(&chain.Output{
+		Value:    900000,
+		Address:  rings[0].Address(chain.ChangeBranch, 0),
+		Covenant: chain.EmptyCovenant,
+	})
+	require.NoError(t, txb.SignMultisig(rings[0], true))
+
+	ptx := NewPartialTx(txb.Build(), txb.Coins)
+	require.False(t, ptx.Complete())
+
+	signed, err := ptx.Sign(rings[0])
+	require.NoError(t, err)
+	require.Equal(t, 0, signed)
+	require.False(t, ptx.Complete())
+
+	decoded := new(PartialTx)
+	_, err = decoded.ReadFrom(bytes.NewReader(ptx.Bytes()))
+	require.NoError(t, err)
+	signed, err = decoded.Sign(rings[2])
+	require.NoError(t, err)
+	require.Equal(t, 1, signed)
+	require.True(t, decoded.Complete())
+
+	decoded.Finalize()
+	require.Len(t, decoded.Tx.Witnesses[0].Items, 4)
+	require.NoError(t, decoded.Verify())
+
+	decoded.Tx.Outputs[0].Value = 800000
+	require.Error(t, decoded.Verify())
+}
@@ -1,27 +1,136 @@
 package wallet
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/btcsuite/btcutil/hdkeychain"
 	"github.com/kurumiimari/gohan/chain"
 	"github.com/kurumiimari/gohan/client"
 	"github.com/kurumiimari/gohan/shakedex"
+	"github.com/kurumiimari/gohan/txscript"
 	"github.com/kurumiimari/gohan/walletdb"
 	"github.com/pkg/errors"
 	"github.com/tyler-smith/go-bip39"
 	"gopkg.in/tomb.v2"
 	"runtime"
+	"sort"
 	"sync"
+	"time"
 )
 
 type Node struct {
-	tmb      *tomb.Tomb
-	network  *chain.Network
-	engine   *walletdb.Engine
-	client   *client.NodeRPCClient
-	bm       *BlockMonitor
-	accounts map[string]*Account
-	wMtx     sync.Mutex
+	tmb         *tomb.Tomb
+	network     *chain.Network
+	engine      *walletdb.Engine
+	client      *client.NodeRPCClient
+	bm          *BlockMonitor
+	accounts    map[string]*Account
+	wallets     map[string]*Wallet
+	dropTimeout time.Duration
+	signers     map[string]string
+	wMtx        sync.Mutex
+}
+
+type NodeOption func(n *Node)
+
+// WithDropTimeout sets how long a pending transaction may be missing from
+// the node's mempool before it's marked dropped.
+func WithDropTimeout(timeout time.Duration) NodeOption {
+	return func(n *Node) {
+		n.dropTimeout = timeout
+	}
+}
+
+// WithExternalSigners sets the external signers accounts may use, keyed by
+// name. Signers can only be configured when the daemon starts, so API
+// clients can't make it run arbitrary commands.
+func WithExternalSigners(signers map[string]string) NodeOption {
+	return func(n *Node) {
+		n.signers = signers
+	}
+}
+
+type CreateOption func(opts *walletdb.AccountOpts) error
+
+func WithMultisig(threshold int, cosigners ...string) CreateOption {
+	return func(opts *walletdb.AccountOpts) error {
+		if len(cosigners) == 0 {
+			return errors.New("multisig accounts require at least one cosigner")
+		}
+		if len(cosigners)+1 > txscript.MaxPubKeysPerMultiSig {
+			return errors.Errorf("multisig accounts support at most %d keys", txscript.MaxPubKeysPerMultiSig)
+		}
+		if threshold < 1 || threshold > len(cosigners)+1 {
+			return errors.Errorf("threshold must be between 1 and %d", len(cosigners)+1)
+		}
+
+		seen := map[string]bool{
+			opts.XPub.PublicString(): true,
+		}
+		for _, xPubStr := range cosigners {
+			cosigner, err := chain.NewMasterExtendedKeyFromXPub(xPubStr, chain.GetCurrNetwork())
+			if err != nil {
+				return errors.Wrap(err, "error parsing cosigner xpub")
+			}
+			if seen[cosigner.PublicString()] {
+				return errors.New("duplicate cosigner xpub")
+			}
+			seen[cosigner.PublicString()] = true
+			opts.Cosigners = append(opts.Cosigners, cosigner)
+		}
+		opts.Threshold = threshold
+		return nil
+	}
+}
+
+func inWallet(walletID string) CreateOption {
+	return func(opts *walletdb.AccountOpts) error {
+		opts.WalletID = walletID
+		return nil
+	}
+}
+
+// withMnemonic stores the mnemonic, encrypted with password, so that it can be
+// exported later.
+func withMnemonic(mnemonic, password string) CreateOption {
+	return func(opts *walletdb.AccountOpts) error {
+		box, err := encryptMnemonic(mnemonic, password, DefaultKDFParams)
+		if err != nil {
+			return err
+		}
+		opts.Mnemonic, err = marshalSecretBox(box)
+		return err
+	}
+}
+
+// WithExternalSigner signs with one of the external signers the node was
+// started with, referred to by name.
+func WithExternalSigner(name string) CreateOption {
+	return func(opts *walletdb.AccountOpts) error {
+		if !opts.WatchOnly {
+			return errors.New("external signers can only be used with xpub imports")
+		}
+		opts.ExternalSigner = name
+		opts.WatchOnly = false
+		return nil
+	}
+}
+
+// WithGapLimits sets how many unused addresses past the last used one the
+// account watches on each branch. Zero keeps the default for that branch.
+func WithGapLimits(recv, change, dutchAuction uint32) CreateOption {
+	return func(opts *walletdb.AccountOpts) error {
+		for _, limit := range []uint32{recv, change, dutchAuction} {
+			if limit > MaxGapLimit {
+				return errors.Errorf("gap limit must be at most %d", MaxGapLimit)
+			}
+		}
+		opts.RecvGapLimit = recv
+		opts.ChangeGapLimit = change
+		opts.DutchAuctionGapLimit = dutchAuction
+		return nil
+	}
 }
 
 type NodeStatus struct {
@@ -37,15 +146,22 @@ func NewNode(
 	engine *walletdb.Engine,
 	client *client.NodeRPCClient,
 	bm *BlockMonitor,
+	opts ...NodeOption,
 ) *Node {
-	return &Node{
-		tmb:      tmb,
-		network:  network,
-		engine:   engine,
-		client:   client,
-		bm:       bm,
-		accounts: make(map[string]*Account),
+	n := &Node{
+		tmb:         tmb,
+		network:     network,
+		engine:      engine,
+		client:      client,
+		bm:          bm,
+		accounts:    make(map[string]*Account),
+		wallets:     make(map[string]*Wallet),
+		dropTimeout: DefaultDropTimeout,
 	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
 }
 
 func (s *Node) Status() *NodeStatus {
@@ -63,9 +179,42 @@ func (s *Node) PollBlock() error {
 	return s.bm.Poll()
 }
 
+// VerifyMessageWithName checks a message signature against the address
+// holding name's current owner output, which it returns.
+func (s *Node) VerifyMessageWithName(name string, msg []byte, sig []byte) (*chain.Address, bool, error) {
+	info, err := s.client.GetNameInfo(name)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "error getting name info")
+	}
+	if info.Info == nil || info.Info.Owner.Hash == nil || bytes.Equal(info.Info.Owner.Hash, chain.ZeroHash) {
+		return nil, false, errors.New("name has no owner")
+	}
+	if info.Info.Expired {
+		return nil, false, errors.New("name is expired")
+	}
+
+	coin, err := s.client.GetCoinByOutpoint(info.Info.Owner.Hash.String(), int(info.Info.Owner.Index))
+	if err != nil {
+		return nil, false, errors.Wrap(err, "error getting owner coin")
+	}
+	addr, err := chain.NewAddressFromBech32(coin.Address)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "invalid owner address")
+	}
+
+	ok, err := chain.VerifyMessage(addr, msg, sig)
+	return addr, ok, err
+}
+
 func (s *Node) Start() error {
+	var wallets []*walletdb.WalletOpts
 	var accounts []*walletdb.AccountOpts
 	err := s.engine.Transaction(func(tx walletdb.Transactor) error {
+		w, err := walletdb.GetAllWallets(tx)
+		if err != nil {
+			return err
+		}
+		wallets = w
 		a, err := walletdb.GetAllAccounts(tx)
 		if err != nil {
 			return err
@@ -78,6 +227,12 @@ func (s *Node) Start() error {
 	}
 
 	s.wMtx.Lock()
+	for _, w := range wallets {
+		s.wallets[w.ID], err = NewWallet(s.network, s.engine, w)
+		if err != nil {
+			return err
+		}
+	}
 	for _, acc := range accounts {
 		s.accounts[acc.ID], err = NewAccount(
 			s.tmb,
@@ -86,57 +241,199 @@ func (s *Node) Start() error {
 			s.client,
 			s.bm,
 			acc,
+			s.externalSigner(acc.ExternalSigner),
 		)
 		if err != nil {
 			return err
 		}
+		if w := s.wallets[acc.WalletID]; w != nil {
+			w.addAccount(s.accounts[acc.ID])
+		}
 	}
 	s.wMtx.Unlock()
 
 	for name, a := range s.accounts {
-		if err := a.Start(); err != nil {
+		if err := s.startAccount(a); err != nil {
 			return errors.Wrap(err, fmt.Sprintf("account %s failed to start", name))
 		}
 	}
 	return nil
 }
 
-func (s *Node) ImportMnemonic(id, password, mnemonic string, index uint32) (*Account, error) {
+func (s *Node) startAccount(a *Account) error {
+	if err := a.Start(); err != nil {
+		return err
+	}
+	return NewMempoolTracker(a, s.dropTimeout).Start()
+}
+
+// ImportMnemonic creates an account from mnemonic. passphrase is the optional
+// BIP39 passphrase, sometimes called the 25th word.
+func (s *Node) ImportMnemonic(id, password, mnemonic, passphrase string, index uint32, createOpts ...CreateOption) (*Account, error) {
 	if !bip39.IsMnemonicValid(mnemonic) {
 		return nil, errors.New("invalid mnemonic")
 	}
 
-	ek := chain.NewMasterExtendedKeyFromMnemonic(mnemonic, "", s.network)
-	wallet, err := s.create(id, password, ek, index)
+	ek := chain.NewMasterExtendedKeyFromMnemonic(mnemonic, passphrase, s.network)
+	wallet, err := s.create(id, password, DefaultKDFParams, ek, index, append(createOpts, withMnemonic(mnemonic, password))...)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating wallet")
 	}
 	return wallet, nil
 }
 
-func (s *Node) ImportXPub(id, password, xPubStr string, index uint32) (*Account, error) {
+func (s *Node) ImportXPub(id, password, xPubStr string, index uint32, createOpts ...CreateOption) (*Account, error) {
 	ek, err := chain.NewMasterExtendedKeyFromXPub(xPubStr, s.network)
 	if err != nil {
 		return nil, errors.Wrap(err, "error parsing xpub")
 	}
 
-	wallet, err := s.create(id, password, ek, index)
+	wallet, err := s.create(id, password, DefaultKDFParams, ek, index, createOpts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating wallet")
 	}
 	return wallet, nil
 }
 
-func (s *Node) CreateWallet(name, password string, index uint32) (*Account, string, error) {
-	seed, mnemonic := chain.GenerateRandomSeed("")
+func (s *Node) CreateWallet(name, password, passphrase string, index uint32, createOpts ...CreateOption) (*Account, string, error) {
+	seed, mnemonic := chain.GenerateRandomSeed(passphrase)
 	ek := chain.NewMasterExtendedKey(seed, s.network)
-	wallet, err := s.create(name, password, ek, index)
+	wallet, err := s.create(name, password, DefaultKDFParams, ek, index, append(createOpts, withMnemonic(mnemonic, password))...)
 	if err != nil {
 		return nil, "", errors.Wrap(err, "error creating wallet")
 	}
 	return wallet, mnemonic, nil
 }
 
+// CreateHDWallet creates a wallet from mnemonic, or from a new random
+// mnemonic if it's empty, along with its first account. The account shares
+// the wallet's ID and is derived at index 0. passphrase is the optional BIP39
+// passphrase.
+func (s *Node) CreateHDWallet(id, password, mnemonic, passphrase string, createOpts ...CreateOption) (*Wallet, string, error) {
+	if err := ValidateAccountID(id); err != nil {
+		return nil, "", errors.Wrap(err, "invalid wallet ID")
+	}
+	if mnemonic == "" {
+		_, mnemonic = chain.GenerateRandomSeed("")
+	} else if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, "", errors.New("invalid mnemonic")
+	}
+	if _, err := s.Wallet(id); err == nil {
+		return nil, "", errors.New("a wallet with that ID already exists")
+	}
+
+	master := chain.NewMasterExtendedKeyFromMnemonic(mnemonic, passphrase, s.network)
+	box, err := EncryptDefault([]byte(master.PrivateString()), password)
+	if err != nil {
+		panic(err)
+	}
+	seed, err := json.Marshal(box)
+	if err != nil {
+		panic(err)
+	}
+	mnemonicBox, err := encryptMnemonic(mnemonic, password, DefaultKDFParams)
+	if err != nil {
+		return nil, "", err
+	}
+	encMnemonic, err := marshalSecretBox(mnemonicBox)
+	if err != nil {
+		return nil, "", err
+	}
+	opts := &walletdb.WalletOpts{
+		ID:        id,
+		Seed:      string(seed),
+		Mnemonic:  encMnemonic,
+		CreatedAt: time.Now().Unix(),
+	}
+	w, err := NewWallet(s.network, s.engine, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	err = s.engine.Transaction(func(tx walletdb.Transactor) error {
+		return walletdb.CreateWallet(tx, opts)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	acc, err := s.create(id, password, DefaultKDFParams, master, 0, append(createOpts, inWallet(id))...)
+	if err != nil {
+		// don't leave a wallet without any accounts behind
+		delErr := s.engine.Transaction(func(tx walletdb.Transactor) error {
+			return walletdb.DeleteWallet(tx, id)
+		})
+		if delErr != nil {
+			walletLogger.Error("error cleaning up wallet", "wid", id, "err", delErr)
+		}
+		return nil, "", errors.Wrap(err, "error creating wallet")
+	}
+	w.addAccount(acc)
+
+	s.wMtx.Lock()
+	s.wallets[id] = w
+	s.wMtx.Unlock()
+	return w, mnemonic, nil
+}
+
+// CreateWalletAccount derives a new account from a wallet's seed. Pass a
+// negative index to use the next unused one. If the wallet is unlocked, so is
+// the new account.
+func (s *Node) CreateWalletAccount(walletID, accountID, password string, index int64, createOpts ...CreateOption) (*Account, error) {
+	w, err := s.Wallet(walletID)
+	if err != nil {
+		return nil, err
+	}
+	w.seedMtx.Lock()
+	defer w.seedMtx.Unlock()
+	master, err := w.keyLocker.open(password)
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 {
+		index = int64(w.nextIndex())
+	}
+	if index >= hdkeychain.HardenedKeyStart {
+		return nil, errors.New("account index is too large")
+	}
+	for _, acc := range w.Accounts() {
+		if acc.Index() == uint32(index) {
+			return nil, errors.Errorf("account %s already uses index %d", acc.ID(), index)
+		}
+	}
+
+	acc, err := s.create(accountID, password, w.keyLocker.secretBox().KDFParams(), master, uint32(index), append(createOpts, inWallet(walletID))...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating account")
+	}
+	w.addAccount(acc)
+	if !w.Locked() {
+		acc.unlockWithKey(deriveAccountKey(master, s.network, acc.Index()))
+	}
+	return acc, nil
+}
+
+func (s *Node) Wallet(id string) (*Wallet, error) {
+	s.wMtx.Lock()
+	defer s.wMtx.Unlock()
+	w := s.wallets[id]
+	if w == nil {
+		return nil, errors.New("wallet not found")
+	}
+	return w, nil
+}
+
+func (s *Node) Wallets() []string {
+	s.wMtx.Lock()
+	defer s.wMtx.Unlock()
+	var wallets []string
+	for id := range s.wallets {
+		wallets = append(wallets, id)
+	}
+	sort.Strings(wallets)
+	return wallets
+}
+
 func (s *Node) Account(id string) (*Account, error) {
 	s.wMtx.Lock()
 	defer s.wMtx.Unlock()
@@ -157,7 +454,7 @@ func (s *Node) Accounts() []string {
 	return accounts
 }
 
-func (s *Node) create(id, password string, ek chain.ExtendedKey, index uint32) (*Account, error) {
+func (s *Node) create(id, password string, kdf KDFParams, ek chain.ExtendedKey, index uint32, createOpts ...CreateOption) (*Account, error) {
 	s.wMtx.Lock()
 	defer s.wMtx.Unlock()
 
@@ -168,19 +465,13 @@ func (s *Node) create(id, password string, ek chain.ExtendedKey, index uint32) (
 	var err error
 	var accountKey chain.ExtendedKey
 	if ek.IsPrivate() {
-		accountKey = chain.DeriveExtendedKey(
-			ek,
-			chain.HardenNode(chain.CoinPurpose),
-			chain.HardenNode(s.network.KeyPrefix.CoinType),
-			chain.HardenNode(index),
-		)
+		accountKey = deriveAccountKey(ek, s.network, index)
 	} else {
 		accountKey = ek
 	}
 
 	bloom := NewAddressBloom()
-	ring := NewAccountKeyring(nil, accountKey, s.network)
-	dec, err := EncryptDefault([]byte(accountKey.PrivateString()), password)
+	dec, err := NewArgon2AESGCM256SecretBox([]byte(accountKey.PrivateString()), password, kdf)
 	if err != nil {
 		panic(err)
 	}
@@ -197,24 +488,43 @@ func (s *Node) create(id, password string, ek chain.ExtendedKey, index uint32) (
 		XPub:          accountKey.Neuter(),
 		OutpointBloom: NewOutpointBloomFromOutpoints(nil).Bytes(),
 	}
+	for _, opt := range createOpts {
+		if err := opt(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.ExternalSigner != "" && s.signers[opts.ExternalSigner] == "" {
+		return nil, errors.Errorf("external signer %s is not configured", opts.ExternalSigner)
+	}
+
+	opts.RecvGapLimit = gapLimitOrDefault(opts.RecvGapLimit, AddrLookahead)
+	opts.ChangeGapLimit = gapLimitOrDefault(opts.ChangeGapLimit, AddrLookahead)
+	opts.DutchAuctionGapLimit = gapLimitOrDefault(opts.DutchAuctionGapLimit, DutchAuctionLookahead)
+
+	var ring Keyring = NewAccountKeyring(nil, accountKey, s.network)
+	if len(opts.Cosigners) > 0 {
+		ring = NewMultisigKeyring(ring.(*AccountKeyring), opts.Cosigners, opts.Threshold)
+	}
 
 	err = s.engine.Transaction(func(tx walletdb.Transactor) error {
-		for i := uint32(0); i <= AddrLookahead; i++ {
+		for i := uint32(0); i <= opts.RecvGapLimit; i++ {
 			recv := ring.Address(chain.ReceiveBranch, i)
-			change := ring.Address(chain.ChangeBranch, i)
 			if _, err := walletdb.CreateAddress(tx, opts.ID, recv, chain.ReceiveBranch, i); err != nil {
 				return err
 			}
+			bloom.Update([]*chain.Address{recv})
+		}
+
+		for i := uint32(0); i <= opts.ChangeGapLimit; i++ {
+			change := ring.Address(chain.ChangeBranch, i)
 			if _, err := walletdb.CreateAddress(tx, opts.ID, change, chain.ChangeBranch, i); err != nil {
 				return err
 			}
-			bloom.Update([]*chain.Address{
-				recv,
-				change,
-			})
+			bloom.Update([]*chain.Address{change})
 		}
 
-		for i := uint32(0); i <= 10; i++ {
+		for i := uint32(0); i <= opts.DutchAuctionGapLimit; i++ {
 			dutch := HIP1AddressMaker(ring, shakedex.AddressBranch, i)
 			if _, err := walletdb.CreateAddress(tx, opts.ID, dutch, shakedex.AddressBranch, i); err != nil {
 				return err
@@ -243,13 +553,51 @@ func (s *Node) create(id, password string, ek chain.ExtendedKey, index uint32) (
 		s.client,
 		s.bm,
 		opts,
+		s.externalSigner(opts.ExternalSigner),
 	)
 	if err != nil {
 		return nil, err
 	}
-	if err := acc.Start(); err != nil {
+	if err := s.startAccount(acc); err != nil {
 		return nil, errors.Wrap(err, "error opening wallet")
 	}
 	s.accounts[id] = acc
 	return acc, nil
 }
+
+// externalSigner returns the signer for an account's external signer name,
+// or nil if it doesn't use one. Accounts created before signers were
+// configured by name stored the signer's URI, so those are matched against
+// the configured URIs. Accounts whose signer isn't configured still load,
+// but can't sign.
+func (s *Node) externalSigner(name string) Signer {
+	if name == "" {
+		return nil
+	}
+	uri := s.signers[name]
+	if uri == "" {
+		for _, configured := range s.signers {
+			if configured == name {
+				uri = configured
+				break
+			}
+		}
+	}
+	if uri == "" {
+		return unconfiguredSigner(name)
+	}
+	signer, err := NewExternalSigner(uri)
+	if err != nil {
+		return unconfiguredSigner(name)
+	}
+	return signer
+}
+
+func deriveAccountKey(master chain.ExtendedKey, network *chain.Network, index uint32) chain.ExtendedKey {
+	return chain.DeriveExtendedKey(
+		master,
+		chain.HardenNode(chain.CoinPurpose),
+		chain.HardenNode(network.KeyPrefix.CoinType),
+		chain.HardenNode(index),
+	)
+}
@@ -0,0 +1,198 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/kurumiimari/gohan/bio"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/txscript"
+	"github.com/pkg/errors"
+	"io"
+)
+
+const (
+	PartialTxVersion = 0
+)
+
+var partialTxMagic = []byte("hpst")
+
+type PartialTx struct {
+	Tx    *chain.Transaction
+	Coins []*chain.Coin
+}
+
+func NewPartialTx(tx *chain.Transaction, coins []*chain.Coin) *PartialTx {
+	if len(tx.Witnesses) < len(tx.Inputs) {
+		witnesses := make([]*chain.Witness, len(tx.Inputs))
+		copy(witnesses, tx.Witnesses)
+		for i := len(tx.Witnesses); i < len(witnesses); i++ {
+			witnesses[i] = new(chain.Witness)
+		}
+		tx.Witnesses = witnesses
+	}
+
+	return &PartialTx{
+		Tx:    tx,
+		Coins: coins,
+	}
+}
+
+func (p *PartialTx) Complete() bool {
+	return IsTxComplete(p.Tx)
+}
+
+func (p *PartialTx) Finalize() {
+	FinalizeMultisigWitnesses(p.Tx)
+}
+
+func (p *PartialTx) Sign(ring Keyring) (int, error) {
+	var signed int
+	for i, coin := range p.Coins {
+		if coin == nil {
+			continue
+		}
+		if !ring.Address(coin.Derivation...).Equal(coin.Address) {
+			continue
+		}
+
+		if msRing, ok := ring.(*MultisigKeyring); ok {
+			if len(p.Tx.Witnesses[i].Items) == 0 {
+				p.Tx.Witnesses[i] = msRing.Witness(coin.Derivation...)
+			}
+			didSign, err := msRing.SignWitness(p.Tx, i, coin, p.Tx.Witnesses[i])
+			if err != nil {
+				return signed, err
+			}
+			if didSign {
+				signed++
+			}
+			continue
+		}
+
+		if len(p.Tx.Witnesses[i].Items) != 0 {
+			continue
+		}
+
+		wit, err := P2PKHWitness(ring, p.Tx, i, coin)
+		if err != nil {
+			return signed, err
+		}
+		p.Tx.Witnesses[i] = wit
+		signed++
+	}
+	return signed, nil
+}
+
+func (p *PartialTx) Verify() error {
+	for i, coin := range p.Coins {
+		if coin == nil {
+			continue
+		}
+		if err := txscript.EngineStandardVerify(p.Tx, i, coin.Address, coin.Value); err != nil {
+			return errors.Wrapf(err, "invalid witness for input %d", i)
+		}
+	}
+	return nil
+}
+
+func (p *PartialTx) WriteTo(w io.Writer) (int64, error) {
+	g := bio.NewGuardWriter(w)
+	bio.WriteRawBytes(g, partialTxMagic)
+	bio.WriteByte(g, PartialTxVersion)
+	p.Tx.WriteTo(g)
+	for _, coin := range p.Coins {
+		if coin == nil {
+			bio.WriteByte(g, 0)
+			continue
+		}
+
+		bio.WriteByte(g, 1)
+		out := &chain.Output{
+			Value:    coin.Value,
+			Address:  coin.Address,
+			Covenant: coin.Covenant,
+		}
+		out.WriteTo(g)
+		bio.WriteVarint(g, uint64(len(coin.Derivation)))
+		for _, node := range coin.Derivation {
+			bio.WriteUint32LE(g, node)
+		}
+	}
+	return g.N, errors.Wrap(g.Err, "error writing partial transaction")
+}
+
+func (p *PartialTx) ReadFrom(r io.Reader) (int64, error) {
+	g := bio.NewGuardReader(r)
+	magic, _ := bio.ReadFixedBytes(g, len(partialTxMagic))
+	version, _ := bio.ReadByte(g)
+	if g.Err != nil {
+		return g.N, errors.Wrap(g.Err, "error reading partial transaction")
+	}
+	if !bytes.Equal(magic, partialTxMagic) {
+		return g.N, errors.New("invalid partial transaction magic")
+	}
+	if version != PartialTxVersion {
+		return g.N, errors.Errorf("unsupported partial transaction version %d", version)
+	}
+
+	tx := new(chain.Transaction)
+	tx.ReadFrom(g)
+	coins := make([]*chain.Coin, len(tx.Inputs))
+	for i := range coins {
+		hasCoin, _ := bio.ReadByte(g)
+		if hasCoin == 0 {
+			continue
+		}
+
+		out := new(chain.Output)
+		out.ReadFrom(g)
+		derivLen, _ := bio.ReadVarint(g)
+		if derivLen > 255 {
+			return g.N, errors.New("derivation path too long")
+		}
+		deriv := make(chain.Derivation, derivLen)
+		for j := range deriv {
+			deriv[j], _ = bio.ReadUint32LE(g)
+		}
+		coins[i] = &chain.Coin{
+			Value:      out.Value,
+			Address:    out.Address,
+			Covenant:   out.Covenant,
+			Prevout:    tx.Inputs[i].Prevout,
+			Derivation: deriv,
+		}
+	}
+	if g.Err != nil {
+		return g.N, errors.Wrap(g.Err, "error reading partial transaction")
+	}
+
+	p.Tx = tx
+	p.Coins = coins
+	return g.N, nil
+}
+
+func (p *PartialTx) Bytes() []byte {
+	buf := new(bytes.Buffer)
+	if _, err := p.WriteTo(buf); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func (p *PartialTx) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(p.Bytes()))
+}
+
+func (p *PartialTx) UnmarshalJSON(b []byte) error {
+	var data string
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	raw, err := hex.DecodeString(data)
+	if err != nil {
+		return errors.Wrap(err, "invalid partial transaction hex")
+	}
+	_, err = p.ReadFrom(bytes.NewReader(raw))
+	return err
+}
@@ -0,0 +1,70 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPartialTx(t *testing.T) {
+	mk := chain.NewMasterExtendedKeyFromMnemonic(Mnemonic, "", chain.NetworkRegtest)
+	derived := chain.DeriveExtendedKey(mk, chain.Derivation{
+		chain.HardenNode(chain.CoinPurpose),
+		chain.HardenNode(chain.NetworkRegtest.KeyPrefix.CoinType),
+		chain.HardenNode(0),
+	}...)
+	ring := NewAccountKeyring(NewEKPrivateKeyer(derived), derived, chain.NetworkRegtest)
+	watchRing := NewAccountKeyring(nil, derived.Neuter(), chain.NetworkRegtest)
+
+	txb := new(TxBuilder)
+	for i := uint32(0); i < 2; i++ {
+		txb.AddCoin(&chain.Coin{
+			Value:      1000000,
+			Address:    watchRing.Address(chain.ReceiveBranch, i),
+			Covenant:   chain.EmptyCovenant,
+			Prevout:    &chain.Outpoint{Hash: bytes.Repeat([]byte{byte(i + 1)}, 32), Index: i},
+			Derivation: chain.Derivation{chain.ReceiveBranch, i},
+		})
+	}
+	txb.AddOutput(&chain.Output{
+		Value:    1500000,
+		Address:  watchRing.Address(chain.ChangeBranch, 0),
+		Covenant: chain.EmptyCovenant,
+	})
+
+	ptx := NewPartialTx(txb.Build(), txb.Coins)
+	require.False(t, ptx.Complete())
+
+	decoded := new(PartialTx)
+	_, err := decoded.ReadFrom(bytes.NewReader(ptx.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, ptx.Tx.IDHex(), decoded.Tx.IDHex())
+	require.Len(t, decoded.Coins, 2)
+	for i, coin := range decoded.Coins {
+		require.True(t, coin.Address.Equal(txb.Coins[i].Address))
+		require.Equal(t, txb.Coins[i].Value, coin.Value)
+		require.Equal(t, txb.Coins[i].Derivation, coin.Derivation)
+	}
+
+	signed, err := decoded.Sign(ring)
+	require.NoError(t, err)
+	require.Equal(t, 2, signed)
+	require.True(t, decoded.Complete())
+	require.NoError(t, decoded.Verify())
+
+	signed, err = decoded.Sign(ring)
+	require.NoError(t, err)
+	require.Equal(t, 0, signed)
+
+	data, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	fromJSON := new(PartialTx)
+	require.NoError(t, json.Unmarshal(data, fromJSON))
+	require.Equal(t, decoded.Tx.Bytes(), fromJSON.Tx.Bytes())
+	require.NoError(t, fromJSON.Verify())
+
+	fromJSON.Tx.Outputs[0].Value = 1400000
+	require.Error(t, fromJSON.Verify())
+}
@@ -10,42 +10,76 @@ import (
 
 const (
 	Argon2IDAESGCM256DecryptorType = "argon2id-aes-gcm-256"
+	// Argon2IDAESGCM256V2DecryptorType boxes record the Argon2 thread count.
+	// Boxes of the original type were always derived with 4 threads.
+	Argon2IDAESGCM256V2DecryptorType = "argon2id-aes-gcm-256-v2"
+
+	MaxKDFTime    = 64
+	MinKDFMemory  = 8 * 1024
+	MaxKDFMemory  = 4 * 1024 * 1024
+	legacyThreads = 4
 )
 
+// KDFParams configures Argon2id. Memory is in KiB.
+type KDFParams struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+var DefaultKDFParams = KDFParams{
+	Time:    1,
+	Memory:  64 * 1024,
+	Threads: 4,
+}
+
+func (p KDFParams) Validate() error {
+	if p.Time == 0 || p.Time > MaxKDFTime {
+		return errors.Errorf("kdf time must be between 1 and %d", MaxKDFTime)
+	}
+	if p.Memory < MinKDFMemory || p.Memory > MaxKDFMemory {
+		return errors.Errorf("kdf memory must be between %d and %d KiB", MinKDFMemory, MaxKDFMemory)
+	}
+	if p.Threads == 0 {
+		return errors.New("kdf threads must be at least 1")
+	}
+	return nil
+}
+
 type SecretBox interface {
 	Decrypt(password string) ([]byte, error)
+	KDFParams() KDFParams
 }
 
 type Argon2AESGCM256SecretBox struct {
-	Time   uint32 `json:"time"`
-	Memory uint32 `json:"memory"`
-	Salt   []byte `json:"salt"`
-	KeyLen uint32 `json:"key_len"`
-	Nonce  []byte `json:"nonce"`
-	Tag    []byte `json:"tag"`
-	CT     []byte `json:"ct"`
-	Type   string `json:"type"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads,omitempty"`
+	Salt    []byte `json:"salt"`
+	KeyLen  uint32 `json:"key_len"`
+	Nonce   []byte `json:"nonce"`
+	Tag     []byte `json:"tag"`
+	CT      []byte `json:"ct"`
+	Type    string `json:"type"`
 }
 
-func NewArgon2AESGCM256SecretBox(pt []byte, password string) (SecretBox, error) {
+func NewArgon2AESGCM256SecretBox(pt []byte, password string, params KDFParams) (SecretBox, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
 	c := &Argon2AESGCM256SecretBox{
-		Time:   1,
-		Memory: 64 * 1024,
-		Salt:   RandBytes(32),
-		KeyLen: 32,
-		Nonce:  RandBytes(12),
-		Tag:    RandBytes(32),
-		Type:   Argon2IDAESGCM256DecryptorType,
+		Time:    params.Time,
+		Memory:  params.Memory,
+		Threads: params.Threads,
+		Salt:    RandBytes(32),
+		KeyLen:  32,
+		Nonce:   RandBytes(12),
+		Tag:     RandBytes(32),
+		Type:    Argon2IDAESGCM256V2DecryptorType,
 	}
 
-	key := argon2.IDKey(
-		[]byte(password),
-		c.Salt,
-		c.Time,
-		c.Memory,
-		4,
-		c.KeyLen,
-	)
+	key := c.deriveKey(password)
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
@@ -61,14 +95,7 @@ func NewArgon2AESGCM256SecretBox(pt []byte, password string) (SecretBox, error)
 }
 
 func (c *Argon2AESGCM256SecretBox) Decrypt(password string) ([]byte, error) {
-	key := argon2.IDKey(
-		[]byte(password),
-		c.Salt,
-		c.Time,
-		c.Memory,
-		4,
-		c.KeyLen,
-	)
+	key := c.deriveKey(password)
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
@@ -82,8 +109,32 @@ func (c *Argon2AESGCM256SecretBox) Decrypt(password string) ([]byte, error) {
 	return gcm.Open(nil, c.Nonce, c.CT, c.Tag)
 }
 
+func (c *Argon2AESGCM256SecretBox) KDFParams() KDFParams {
+	threads := c.Threads
+	if c.Type == Argon2IDAESGCM256DecryptorType {
+		threads = legacyThreads
+	}
+	return KDFParams{
+		Time:    c.Time,
+		Memory:  c.Memory,
+		Threads: threads,
+	}
+}
+
+func (c *Argon2AESGCM256SecretBox) deriveKey(password string) []byte {
+	params := c.KDFParams()
+	return argon2.IDKey(
+		[]byte(password),
+		c.Salt,
+		params.Time,
+		params.Memory,
+		params.Threads,
+		c.KeyLen,
+	)
+}
+
 func EncryptDefault(pt []byte, password string) (SecretBox, error) {
-	return NewArgon2AESGCM256SecretBox(pt, password)
+	return NewArgon2AESGCM256SecretBox(pt, password, DefaultKDFParams)
 }
 
 func UnmarshalSecretBox(in []byte) (SecretBox, error) {
@@ -97,7 +148,7 @@ func UnmarshalSecretBox(in []byte) (SecretBox, error) {
 
 	var dec SecretBox
 	switch tmp.Type {
-	case Argon2IDAESGCM256DecryptorType:
+	case Argon2IDAESGCM256DecryptorType, Argon2IDAESGCM256V2DecryptorType:
 		dec = &Argon2AESGCM256SecretBox{}
 	default:
 		return nil, errors.New("unknown decryptor type")
@@ -0,0 +1,67 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"testing"
+)
+
+func TestSecretBox(t *testing.T) {
+	params := KDFParams{
+		Time:    2,
+		Memory:  MinKDFMemory,
+		Threads: 2,
+	}
+	box, err := NewArgon2AESGCM256SecretBox([]byte("secret"), "password", params)
+	require.NoError(t, err)
+	require.Equal(t, params, box.KDFParams())
+
+	data, err := json.Marshal(box)
+	require.NoError(t, err)
+	box, err = UnmarshalSecretBox(data)
+	require.NoError(t, err)
+	require.Equal(t, Argon2IDAESGCM256V2DecryptorType, box.(*Argon2AESGCM256SecretBox).Type)
+	pt, err := box.Decrypt("password")
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), pt)
+	_, err = box.Decrypt("wrong")
+	require.Error(t, err)
+
+	_, err = NewArgon2AESGCM256SecretBox([]byte("secret"), "password", KDFParams{Time: 1, Memory: 1024, Threads: 1})
+	require.Error(t, err)
+	_, err = NewArgon2AESGCM256SecretBox([]byte("secret"), "password", KDFParams{Time: 0, Memory: MinKDFMemory, Threads: 1})
+	require.Error(t, err)
+	_, err = NewArgon2AESGCM256SecretBox([]byte("secret"), "password", KDFParams{Time: 1, Memory: MinKDFMemory})
+	require.Error(t, err)
+}
+
+func TestSecretBoxLegacy(t *testing.T) {
+	legacy := &Argon2AESGCM256SecretBox{
+		Time:   1,
+		Memory: MinKDFMemory,
+		Salt:   RandBytes(32),
+		KeyLen: 32,
+		Nonce:  RandBytes(12),
+		Tag:    RandBytes(32),
+		Type:   Argon2IDAESGCM256DecryptorType,
+	}
+	key := argon2.IDKey([]byte("password"), legacy.Salt, legacy.Time, legacy.Memory, 4, legacy.KeyLen)
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	legacy.CT = gcm.Seal(nil, legacy.Nonce, []byte("secret"), legacy.Tag)
+
+	data, err := json.Marshal(legacy)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "threads")
+	box, err := UnmarshalSecretBox(data)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, box.KDFParams().Threads)
+	pt, err := box.Decrypt("password")
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), pt)
+}
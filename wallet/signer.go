@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/txscript"
+	"github.com/pkg/errors"
+)
+
+var ErrNoPrivateKeys = errors.New("signer does not expose private keys")
+
+type Signer interface {
+	Sign(req *SignRequest) (*btcec.Signature, error)
+}
+
+type SignRequest struct {
+	SigHash    []byte
+	Derivation chain.Derivation
+	Tx         *TxSummary
+	Message    []byte
+}
+
+type TxSummary struct {
+	Hash       string          `json:"hash"`
+	InputIndex int             `json:"input_index"`
+	Value      uint64          `json:"value"`
+	Inputs     []*TxSummaryIn  `json:"inputs"`
+	Outputs    []*TxSummaryOut `json:"outputs"`
+	LockTime   uint32          `json:"lock_time"`
+	Covenant   *TxSummaryCov   `json:"covenant"`
+}
+
+type TxSummaryIn struct {
+	PrevHash  string `json:"prev_hash"`
+	PrevIndex uint32 `json:"prev_index"`
+}
+
+type TxSummaryOut struct {
+	Value    uint64        `json:"value"`
+	Address  string        `json:"address"`
+	Covenant *TxSummaryCov `json:"covenant"`
+}
+
+type TxSummaryCov struct {
+	Type  string   `json:"type"`
+	Items []string `json:"items"`
+}
+
+func NewTxSummary(tx *chain.Transaction, idx int, coin *chain.Coin) *TxSummary {
+	summary := &TxSummary{
+		Hash:       tx.IDHex(),
+		InputIndex: idx,
+		Value:      coin.Value,
+		LockTime:   tx.LockTime,
+		Covenant:   newTxSummaryCov(coin.Covenant),
+	}
+	for _, in := range tx.Inputs {
+		summary.Inputs = append(summary.Inputs, &TxSummaryIn{
+			PrevHash:  in.Prevout.Hash.String(),
+			PrevIndex: in.Prevout.Index,
+		})
+	}
+	for _, out := range tx.Outputs {
+		summary.Outputs = append(summary.Outputs, &TxSummaryOut{
+			Value:    out.Value,
+			Address:  out.Address.String(),
+			Covenant: newTxSummaryCov(out.Covenant),
+		})
+	}
+	return summary
+}
+
+func newTxSummaryCov(cov *chain.Covenant) *TxSummaryCov {
+	if cov == nil {
+		cov = chain.EmptyCovenant
+	}
+	items := make([]string, len(cov.Items))
+	for i, item := range cov.Items {
+		items[i] = hex.EncodeToString(item)
+	}
+	return &TxSummaryCov{
+		Type:  cov.Type.String(),
+		Items: items,
+	}
+}
+
+func SignInput(signer Signer, tx *chain.Transaction, idx int, coin *chain.Coin, script []byte, hashType txscript.SigHashType) ([]byte, error) {
+	hash, err := txscript.CalcWitnessSigHash(script, txscript.NewTxSigHashes(tx), hashType, tx, idx, coin.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "error calculating sighash")
+	}
+
+	sig, err := signer.Sign(&SignRequest{
+		SigHash:    hash,
+		Derivation: coin.Derivation,
+		Tx:         NewTxSummary(tx, idx, coin),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(chain.SerializeSignature(sig), byte(hashType)), nil
+}
+
+func P2PKHWitness(ring Keyring, tx *chain.Transaction, idx int, coin *chain.Coin) (*chain.Witness, error) {
+	pub := ring.PublicKey(coin.Derivation...).SerializeCompressed()
+	script, err := txscript.NewP2PKHScript(ring.PublicEK(coin.Derivation...).Address().Hash)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := SignInput(ring, tx, idx, coin, script, txscript.SigHashAll)
+	if err != nil {
+		return nil, err
+	}
+	return &chain.Witness{
+		Items: [][]byte{
+			sig,
+			pub,
+		},
+	}, nil
+}
+
+func signWithPrivateKeyer(priv PrivateKeyer, req *SignRequest) (*btcec.Signature, error) {
+	key, err := priv.PrivateKey(req.Derivation...)
+	if err != nil {
+		return nil, err
+	}
+	return key.Sign(req.SigHash)
+}
@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"testing"
+)
+
+func TestSearchTransactionsOrder(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	ring := NewAccountKeyring(nil, testAccountKey(0).Neuter(), chain.NetworkRegtest)
+	var sameHeight []string
+	var top string
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		for i := 0; i < 4; i++ {
+			chainTx := &chain.Transaction{
+				Inputs: []*chain.Input{
+					{
+						Prevout:  &chain.Outpoint{Hash: bytes.Repeat([]byte{byte(i + 1)}, 32)},
+						Sequence: chain.DefaultSequence,
+					},
+				},
+				Outputs: []*chain.Output{
+					{Value: 1000, Address: ring.Address(chain.ReceiveBranch, 0), Covenant: chain.EmptyCovenant},
+				},
+				Witnesses: []*chain.Witness{new(chain.Witness)},
+			}
+			height := 10
+			if i == 3 {
+				height = 11
+				top = chainTx.IDHex()
+			} else {
+				sameHeight = append(sameHeight, chainTx.IDHex())
+			}
+			_, err := walletdb.UpsertTransaction(tx, "alice", &walletdb.Transaction{
+				Hash:        chainTx.IDHex(),
+				Idx:         i,
+				BlockHeight: height,
+				BlockHash:   hex.EncodeToString(chain.ZeroHash),
+				Raw:         chainTx.Bytes(),
+				Time:        1600000000,
+			})
+			require.NoError(t, err)
+		}
+		return nil
+	}))
+	sort.Strings(sameHeight)
+
+	search := func(filter *walletdb.TransactionFilter) ([]string, *walletdb.TransactionCursor) {
+		var hashes []string
+		var cursor *walletdb.TransactionCursor
+		require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+			txs, next, err := walletdb.SearchTransactions(tx, "alice", filter)
+			require.NoError(t, err)
+			for _, rtx := range txs {
+				hashes = append(hashes, rtx.Hash.String())
+			}
+			cursor = next
+			return nil
+		}))
+		return hashes, cursor
+	}
+
+	// transactions in the same block are listed by hash
+	hashes, _ := search(&walletdb.TransactionFilter{Count: 10})
+	require.Equal(t, append([]string{top}, sameHeight...), hashes)
+
+	hashes, _ = search(&walletdb.TransactionFilter{Count: 10, Ascending: true})
+	require.Equal(t, []string{sameHeight[2], sameHeight[1], sameHeight[0], top}, hashes)
+
+	hashes, cursor := search(&walletdb.TransactionFilter{Count: 2})
+	require.Equal(t, []string{top, sameHeight[0]}, hashes)
+	require.NotNil(t, cursor)
+	hashes, cursor = search(&walletdb.TransactionFilter{Count: 2, Cursor: cursor})
+	require.Equal(t, sameHeight[1:], hashes)
+	require.NotNil(t, cursor)
+	hashes, cursor = search(&walletdb.TransactionFilter{Count: 2, Cursor: cursor})
+	require.Empty(t, hashes)
+	require.Nil(t, cursor)
+}
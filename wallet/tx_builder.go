@@ -1,21 +1,31 @@
 package wallet
 
 import (
-	"fmt"
 	"github.com/kurumiimari/gohan/bio"
 	"github.com/kurumiimari/gohan/chain"
-	"github.com/kurumiimari/gohan/txscript"
-	"github.com/pkg/errors"
 )
 
 type WitnessFactory func(coins []*chain.Coin, tx *chain.Transaction) (*chain.Witness, error)
 
+const (
+	// prevout plus sequence
+	inputBaseSize = 40
+
+	// signature with sighash type plus compressed public key
+	P2PKHWitnessSize = 1 + 1 + 65 + 1 + 33
+)
+
 type TxBuilder struct {
-	Coins     []*chain.Coin
-	Outputs   []*chain.Output
-	Witnesses []*chain.Witness
-	Version   uint32
-	Locktime  uint32
+	Coins        []*chain.Coin
+	Outputs      []*chain.Output
+	Witnesses    []*chain.Witness
+	Version      uint32
+	Locktime     uint32
+	CoinSelector CoinSelector
+	WitnessSize  func(coin *chain.Coin) int
+
+	replaceable     bool
+	replaceableFrom int
 }
 
 func (b *TxBuilder) AddCoin(coin *chain.Coin) {
@@ -26,6 +36,14 @@ func (b *TxBuilder) AddOutput(output *chain.Output) {
 	b.Outputs = append(b.Outputs, output)
 }
 
+// SetReplaceable signals replace-by-fee on every input that doesn't
+// already have a witness. Pre-signed inputs keep the default sequence
+// so their signatures remain valid.
+func (b *TxBuilder) SetReplaceable() {
+	b.replaceable = true
+	b.replaceableFrom = len(b.Witnesses)
+}
+
 func (b *TxBuilder) Sign(ring Keyring) error {
 	tx := b.Build()
 	for i, coin := range b.Coins {
@@ -33,13 +51,27 @@ func (b *TxBuilder) Sign(ring Keyring) error {
 			continue
 		}
 
-		key, err := ring.PrivateKey(coin.Derivation...)
+		wit, err := P2PKHWitness(ring, tx, i, coin)
 		if err != nil {
 			return err
 		}
-		wit, err := txscript.P2PKHWitnessSignature(tx, i, coin.Value, key)
-		if err != nil {
-			return err
+		b.Witnesses = append(b.Witnesses, wit)
+	}
+	return nil
+}
+
+func (b *TxBuilder) SignMultisig(ring *MultisigKeyring, sign bool) error {
+	tx := b.Build()
+	for i, coin := range b.Coins {
+		if i < len(b.Witnesses) {
+			continue
+		}
+
+		wit := ring.Witness(coin.Derivation...)
+		if sign {
+			if _, err := ring.SignWitness(tx, i, coin, wit); err != nil {
+				return err
+			}
 		}
 		b.Witnesses = append(b.Witnesses, wit)
 	}
@@ -50,36 +82,51 @@ func (b *TxBuilder) Build() *chain.Transaction {
 	tx := &chain.Transaction{
 		Version:   b.Version,
 		Inputs:    make([]*chain.Input, len(b.Coins)),
-		Witnesses: make([]*chain.Witness, len(b.Witnesses)),
+		Witnesses: make([]*chain.Witness, len(b.Coins)),
 		Outputs:   b.Outputs,
 		LockTime:  b.Locktime,
 	}
 	for i, coin := range b.Coins {
+		sequence := uint32(chain.DefaultSequence)
+		if b.replaceable && i >= b.replaceableFrom {
+			sequence = chain.ReplaceableSequence
+		}
 		tx.Inputs[i] = &chain.Input{
 			Prevout: &chain.Outpoint{
 				Hash:  coin.Prevout.Hash,
 				Index: coin.Prevout.Index,
 			},
-			Sequence: chain.DefaultSequence,
+			Sequence: sequence,
 		}
 	}
-	for i, wit := range b.Witnesses {
-		tx.Witnesses[i] = wit
+	for i := range tx.Witnesses {
+		if i < len(b.Witnesses) {
+			tx.Witnesses[i] = b.Witnesses[i]
+		} else {
+			tx.Witnesses[i] = new(chain.Witness)
+		}
 	}
 	return tx
 }
 
+// EstimateSize returns the transaction's estimated virtual size, using
+// the real size of any witnesses already present and WitnessSize for
+// the rest.
 func (b *TxBuilder) EstimateSize() int {
-	var est int
-	est += 4
-	est += bio.SizeVarint(len(b.Coins))
-	est += 4
-	est += len(b.Coins) * 150
-	est += bio.SizeVarint(len(b.Outputs))
+	base := 4
+	base += bio.SizeVarint(len(b.Coins))
+	base += len(b.Coins) * inputBaseSize
+	base += bio.SizeVarint(len(b.Outputs))
 	for _, out := range b.Outputs {
-		est += out.Size()
+		base += out.Size()
+	}
+	base += 4
+
+	var wit int
+	for i, coin := range b.Coins {
+		wit += b.witnessSize(i, coin)
 	}
-	return est
+	return (base*chain.WitnessScaleFactor + wit + chain.WitnessScaleFactor - 1) / chain.WitnessScaleFactor
 }
 
 func (b *TxBuilder) Fund(fundingCoins []*chain.Coin, changeAddress *chain.Address, feeRate uint64) error {
@@ -87,77 +134,89 @@ func (b *TxBuilder) Fund(fundingCoins []*chain.Coin, changeAddress *chain.Addres
 		panic("fee rate is zero")
 	}
 
-	size := uint64(b.EstimateSize())
-	fee := size * feeRate
-
 	var totalIn uint64
 	usedCoins := make(map[string]bool)
 	for _, coin := range b.Coins {
 		totalIn += coin.Value
-		usedCoins[fmt.Sprintf("%x%d", coin.Prevout.Hash, coin.Prevout.Index)] = true
+		usedCoins[coin.Prevout.String()] = true
 	}
 
 	var totalOut uint64
 	for _, out := range b.Outputs {
+		if out.Value > chain.MaxMoney-totalOut {
+			return ErrMaxMoney
+		}
 		totalOut += out.Value
 	}
 
-	totalWithFee := totalOut + fee
-	if totalIn >= totalWithFee {
-		if totalIn == totalWithFee {
-			return nil
+	changeOut := &chain.Output{
+		Value:    0,
+		Address:  changeAddress,
+		Covenant: chain.EmptyCovenant,
+	}
+	costOfChange := uint64(changeOut.Size()) * feeRate
+	fee := uint64(b.EstimateSize()) * feeRate
+
+	if totalIn < totalOut+fee {
+		var candidates []*EffectiveCoin
+		for _, coin := range fundingCoins {
+			if usedCoins[coin.Prevout.String()] {
+				continue
+			}
+			inputFee := uint64(b.inputSize(-1, coin)) * feeRate
+			// skip coins that cost more to spend than they're worth
+			if coin.Value <= inputFee {
+				continue
+			}
+			candidates = append(candidates, &EffectiveCoin{
+				Coin:           coin,
+				EffectiveValue: coin.Value - inputFee,
+			})
 		}
 
-		changeOut := &chain.Output{
-			Value:    0,
-			Address:  changeAddress,
-			Covenant: chain.EmptyCovenant,
+		selector := b.CoinSelector
+		if selector == nil {
+			var err error
+			selector, err = NewCoinSelector("")
+			if err != nil {
+				return err
+			}
 		}
-		addlFee := uint64(changeOut.Size()) * feeRate
-		newTotalWithFee := totalWithFee + addlFee
-		if totalIn > newTotalWithFee {
-			changeOut.Value = totalIn - newTotalWithFee
-			b.Outputs = append(b.Outputs, changeOut)
+		selected, err := selector.SelectCoins(candidates, totalOut+fee-totalIn, costOfChange)
+		if err != nil {
+			return err
+		}
+		for _, coin := range selected {
+			b.AddCoin(coin.Coin)
+			totalIn += coin.Value
 		}
 
-		return nil
-	}
-
-	newCoins := make([]*chain.Coin, len(b.Coins))
-	copy(newCoins, b.Coins)
-
-	for _, coin := range fundingCoins {
-		if usedCoins[fmt.Sprintf("%x%d", coin.Prevout.Hash, coin.Prevout.Index)] {
-			continue
+		fee = uint64(b.EstimateSize()) * feeRate
+		if totalIn < totalOut+fee {
+			return ErrInsufficientFunds
 		}
-		totalIn += coin.Value
-		totalWithFee += 150 * feeRate
-		newCoins = append(newCoins, coin)
-
-		if totalIn >= totalWithFee {
-			change := totalIn - totalWithFee
-			if change == 0 {
-				b.Coins = newCoins
-				return nil
-			}
+	}
 
-			changeOut := &chain.Output{
-				Value:    0,
-				Address:  changeAddress,
-				Covenant: chain.EmptyCovenant,
-			}
-			addlFee := uint64(changeOut.Size()) * feeRate
-			newTotalWithFee := totalWithFee + addlFee
-			if totalIn < newTotalWithFee {
-				continue
-			}
+	// leftovers too small to pay for a change output go to the miners
+	if totalIn-totalOut-fee > costOfChange {
+		changeOut.Value = totalIn - totalOut - fee - costOfChange
+		b.Outputs = append(b.Outputs, changeOut)
+	}
+	return nil
+}
 
-			changeOut.Value = change - addlFee
-			b.Coins = newCoins
-			b.Outputs = append(b.Outputs, changeOut)
-			return nil
-		}
+func (b *TxBuilder) witnessSize(i int, coin *chain.Coin) int {
+	if i >= 0 && i < len(b.Witnesses) && len(b.Witnesses[i].Items) > 0 {
+		return b.Witnesses[i].Size()
+	}
+	if b.WitnessSize != nil {
+		return b.WitnessSize(coin)
 	}
+	return P2PKHWitnessSize
+}
 
-	return errors.New("insufficient funds")
+// inputSize returns the virtual size an input adds to the transaction.
+func (b *TxBuilder) inputSize(i int, coin *chain.Coin) int {
+	weight := inputBaseSize*chain.WitnessScaleFactor + b.witnessSize(i, coin)
+	return (weight + chain.WitnessScaleFactor - 1) / chain.WitnessScaleFactor
 }
@@ -4,119 +4,203 @@ import (
 	"bytes"
 	"github.com/kurumiimari/gohan/chain"
 	"github.com/kurumiimari/gohan/client"
+	"github.com/kurumiimari/gohan/log"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+	"sort"
+	"sync"
 )
 
-//var walletLogger = log.ModuleLogger("wallet")
-//
-//type Wallet struct {
-//	tmb       *tomb.Tomb
-//	network   *chain.Network
-//	engine    *walletdb.Engine
-//	client    *client.NodeRPCClient
-//	bm        *BlockMonitor
-//	keyLocker *KeyLocker
-//	id        string
-//	accounts  *sync.Map
-//	watchOnly bool
-//}
-//
-//func NewWallet(
-//	tmb *tomb.Tomb,
-//	network *chain.Network,
-//	engine *walletdb.Engine,
-//	client *client.NodeRPCClient,
-//	bm *BlockMonitor,
-//	keyLocker *KeyLocker,
-//	id string,
-//) *Wallet {
-//	return &Wallet{
-//		tmb:       tmb,
-//		network:   network,
-//		engine:    engine,
-//		client:    client,
-//		bm:        bm,
-//		keyLocker: keyLocker,
-//		accounts:  new(sync.Map),
-//		id:        id,
-//	}
-//}
-//
-//func (w *Wallet) ID() string {
-//	return w.id
-//}
-//
-//func (w *Wallet) Start() error {
-//	walletLogger.Info("opening wallet", "id", w.id)
-//
-//	var accounts []*walletdb.AccountOpts
-//	err := w.engine.Transaction(func(tx walletdb.Transactor) error {
-//		a, err := walletdb.GetAllAccounts(tx, w.id)
-//		if err != nil {
-//			return err
-//		}
-//		accounts = a
-//		return nil
-//	})
-//	if err != nil {
-//		return errors.WithStack(err)
-//	}
-//
-//	for _, dbAcc := range accounts {
-//		xpub, err := chain.NewMasterExtendedKeyFromXPub(dbAcc.XPub, w.network)
-//		if err != nil {
-//			panic(err)
-//		}
-//		ring := NewAccountKeyring(
-//			w.keyLocker,
-//			xpub,
-//			w.network,
-//			dbAcc.Idx,
-//		)
-//		acc := NewAccount(w.tmb, w.network, w.engine, w.client, w.bm, ring, dbAcc)
-//		if err := acc.Start(); err != nil {
-//			return errors.Wrap(err, "error starting account")
-//		}
-//		w.accounts.Store(dbAcc.ID, acc)
-//	}
-//
-//	return nil
-//}
-//
-//func (w *Wallet) Unlock(password string) error {
-//	err := w.keyLocker.Unlock(password)
-//	if err != nil {
-//		walletLogger.Warning("unlock attempt failed", "wid", w.id)
-//		return err
-//	}
-//	walletLogger.Info("wallet unlocked", "wid", w.id)
-//	return nil
-//}
-//
-//func (w *Wallet) Lock() error {
-//	if !w.keyLocker.Locked() {
-//		return errors.New("wallet is already locked")
-//	}
-//	w.keyLocker.Lock()
-//	walletLogger.Info("wallet locked", "wid", w.id)
-//	return nil
-//}
-//
-//func (w *Wallet) Account(accountID string) (*Account, error) {
-//	accIface, ok := w.accounts.Load(accountID)
-//	if !ok {
-//		return nil, fmt.Errorf("account %s not found", accountID)
-//	}
-//	return accIface.(*Account), nil
-//}
-//
-//func (w *Wallet) Accounts() []string {
-//	var accountIds []string
-//	w.accounts.Range(func(key, value interface{}) bool {
-//		accountIds = append(accountIds, key.(string))
-//		return true
-//	})
-//	return accountIds
-//}
+var walletLogger = log.ModuleLogger("wallet")
+
+// Wallet groups the accounts derived from a single seed. Every account is
+// encrypted with the wallet's password, so unlocking the wallet unlocks all
+// of them.
+type Wallet struct {
+	network   *chain.Network
+	engine    *walletdb.Engine
+	keyLocker *KeyLocker
+	id        string
+	accounts  map[string]*Account
+	mtx       sync.RWMutex
+	// seedMtx keeps accounts from being added while the password changes.
+	seedMtx sync.Mutex
+}
+
+func NewWallet(network *chain.Network, engine *walletdb.Engine, opts *walletdb.WalletOpts) (*Wallet, error) {
+	box, err := UnmarshalSecretBox([]byte(opts.Seed))
+	if err != nil {
+		return nil, err
+	}
+	mnemonicBox, err := unmarshalOptionalSecretBox(opts.Mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	keyLocker := NewKeyLocker(box, network)
+	keyLocker.mnemonicBox = mnemonicBox
+	return &Wallet{
+		network:   network,
+		engine:    engine,
+		keyLocker: keyLocker,
+		id:        opts.ID,
+		accounts:  make(map[string]*Account),
+	}, nil
+}
+
+func (w *Wallet) ID() string {
+	return w.id
+}
+
+func (w *Wallet) Locked() bool {
+	return w.keyLocker.Locked()
+}
+
+func (w *Wallet) Unlock(password string) error {
+	if err := w.keyLocker.Unlock(password); err != nil {
+		walletLogger.Warning("unlock attempt failed", "wid", w.id)
+		return err
+	}
+	master, err := w.keyLocker.extendedKey()
+	if err != nil {
+		return err
+	}
+	for _, acc := range w.Accounts() {
+		acc.unlockWithKey(deriveAccountKey(master, w.network, acc.Index()))
+	}
+	walletLogger.Info("wallet unlocked", "wid", w.id)
+	return nil
+}
+
+func (w *Wallet) Lock() {
+	w.keyLocker.Lock()
+	for _, acc := range w.Accounts() {
+		acc.Lock()
+	}
+	walletLogger.Info("wallet locked", "wid", w.id)
+}
+
+// ChangePassword re-encrypts the wallet's seed and mnemonic, and the seeds of
+// all of its accounts, with a new password and KDF parameters in a single
+// transaction.
+func (w *Wallet) ChangePassword(oldPassword, newPassword string, params KDFParams) error {
+	w.seedMtx.Lock()
+	defer w.seedMtx.Unlock()
+
+	box, mnemonicBox, err := w.keyLocker.rekey(oldPassword, newPassword, params)
+	if err != nil {
+		walletLogger.Warning("change password attempt failed", "wid", w.id)
+		return err
+	}
+	accounts := w.Accounts()
+	accountBoxes := make([]SecretBox, len(accounts))
+	for i, acc := range accounts {
+		accountBoxes[i], _, err = acc.keyLocker.rekey(oldPassword, newPassword, params)
+		if err != nil {
+			return errors.Wrapf(err, "error re-encrypting account %s", acc.ID())
+		}
+	}
+
+	err = w.engine.Transaction(func(tx walletdb.Transactor) error {
+		seed, err := marshalSecretBox(box)
+		if err != nil {
+			return err
+		}
+		mnemonic, err := marshalSecretBox(mnemonicBox)
+		if err != nil {
+			return err
+		}
+		if err := walletdb.UpdateWalletSeed(tx, w.id, seed, mnemonic); err != nil {
+			return err
+		}
+		for i, acc := range accounts {
+			seed, err := marshalSecretBox(accountBoxes[i])
+			if err != nil {
+				return err
+			}
+			if err := walletdb.UpdateAccountSeed(tx, acc.ID(), seed, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	w.keyLocker.setBoxes(box, mnemonicBox)
+	for i, acc := range accounts {
+		acc.keyLocker.setBoxes(accountBoxes[i], nil)
+	}
+	walletLogger.Info("wallet password changed", "wid", w.id)
+	return nil
+}
+
+// Mnemonic decrypts the wallet's backup words.
+func (w *Wallet) Mnemonic(password string) (string, error) {
+	mnemonic, err := w.keyLocker.mnemonic(password)
+	if err != nil {
+		walletLogger.Warning("mnemonic export attempt failed", "wid", w.id)
+		return "", err
+	}
+	return mnemonic, nil
+}
+
+func (w *Wallet) Account(accountID string) (*Account, error) {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	acc := w.accounts[accountID]
+	if acc == nil {
+		return nil, errors.New("account not found")
+	}
+	return acc, nil
+}
+
+// Accounts returns the wallet's accounts ordered by derivation index.
+func (w *Wallet) Accounts() []*Account {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	accounts := make([]*Account, 0, len(w.accounts))
+	for _, acc := range w.accounts {
+		accounts = append(accounts, acc)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Index() < accounts[j].Index()
+	})
+	return accounts
+}
+
+// Balances sums the balances of every account in the wallet.
+func (w *Wallet) Balances() (*walletdb.Balances, error) {
+	total := new(walletdb.Balances)
+	for _, acc := range w.Accounts() {
+		bals, err := acc.Balances()
+		if err != nil {
+			return nil, err
+		}
+		total.Available += bals.Available
+		total.Immature += bals.Immature
+		total.BidLocked += bals.BidLocked
+		total.RevealLocked += bals.RevealLocked
+		total.NameLocked += bals.NameLocked
+	}
+	return total, nil
+}
+
+func (w *Wallet) nextIndex() uint32 {
+	var next uint32
+	for _, acc := range w.Accounts() {
+		if acc.Index() >= next {
+			next = acc.Index() + 1
+		}
+	}
+	return next
+}
+
+func (w *Wallet) addAccount(acc *Account) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.accounts[acc.ID()] = acc
+}
 
 func GetRawBlocksConcurrently(client *client.NodeRPCClient, start, count int) ([]*chain.Block, error) {
 	results, err := client.GetRawBlocksBatch(start, count)
@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"encoding/json"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWalletStorage(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	mk := chain.NewMasterExtendedKeyFromMnemonic(Mnemonic, "", chain.NetworkRegtest)
+	box, err := EncryptDefault([]byte(mk.PrivateString()), "password")
+	require.NoError(t, err)
+	seed, err := json.Marshal(box)
+	require.NoError(t, err)
+
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		require.NoError(t, walletdb.CreateWallet(tx, &walletdb.WalletOpts{
+			ID:        "main",
+			Seed:      string(seed),
+			CreatedAt: 1,
+		}))
+		for i, id := range []string{"main", "auctions"} {
+			require.NoError(t, walletdb.CreateAccount(tx, &walletdb.AccountOpts{
+				ID:            id,
+				Seed:          string(seed),
+				Idx:           uint32(i),
+				XPub:          deriveAccountKey(mk, chain.NetworkRegtest, uint32(i)).Neuter(),
+				WalletID:      "main",
+				AddressBloom:  []byte{},
+				OutpointBloom: []byte{},
+			}))
+		}
+		require.NoError(t, walletdb.CreateAccount(tx, &walletdb.AccountOpts{
+			ID:            "standalone",
+			Seed:          string(seed),
+			XPub:          deriveAccountKey(mk, chain.NetworkRegtest, 2).Neuter(),
+			AddressBloom:  []byte{},
+			OutpointBloom: []byte{},
+		}))
+
+		wallets, err := walletdb.GetAllWallets(tx)
+		require.NoError(t, err)
+		require.Len(t, wallets, 1)
+		require.Equal(t, &walletdb.WalletOpts{
+			ID:        "main",
+			Seed:      string(seed),
+			CreatedAt: 1,
+		}, wallets[0])
+
+		for id, walletID := range map[string]string{
+			"main":       "main",
+			"auctions":   "main",
+			"standalone": "",
+		} {
+			acc, err := walletdb.GetAccount(tx, id)
+			require.NoError(t, err)
+			require.Equal(t, walletID, acc.WalletID)
+		}
+		return nil
+	}))
+
+	w, err := NewWallet(chain.NetworkRegtest, engine, &walletdb.WalletOpts{ID: "main", Seed: string(seed)})
+	require.NoError(t, err)
+	require.True(t, w.Locked())
+	require.Error(t, w.Unlock("wrong"))
+	require.NoError(t, w.Unlock("password"))
+	require.False(t, w.Locked())
+	require.EqualValues(t, 0, w.nextIndex())
+	w.Lock()
+	require.True(t, w.Locked())
+}
+
+func TestWalletChangePassword(t *testing.T) {
+	engine, cleanup := setupEngine(t)
+	defer cleanup()
+
+	mk := chain.NewMasterExtendedKeyFromMnemonic(Mnemonic, "", chain.NetworkRegtest)
+	box, err := EncryptDefault([]byte(mk.PrivateString()), "password")
+	require.NoError(t, err)
+	seed, err := json.Marshal(box)
+	require.NoError(t, err)
+	mnemonicBox, err := encryptMnemonic(Mnemonic, "password", DefaultKDFParams)
+	require.NoError(t, err)
+	mnemonic, err := marshalSecretBox(mnemonicBox)
+	require.NoError(t, err)
+	opts := &walletdb.WalletOpts{ID: "main", Seed: string(seed), Mnemonic: mnemonic}
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		return walletdb.CreateWallet(tx, opts)
+	}))
+
+	w, err := NewWallet(chain.NetworkRegtest, engine, opts)
+	require.NoError(t, err)
+	params := KDFParams{Time: 2, Memory: MinKDFMemory, Threads: 1}
+	require.ErrorIs(t, w.ChangePassword("wrong", "new password", params), ErrInvalidPassword)
+	require.Error(t, w.ChangePassword("password", "new password", KDFParams{}))
+	require.NoError(t, w.ChangePassword("password", "new password", params))
+	require.ErrorIs(t, w.Unlock("password"), ErrInvalidPassword)
+	require.NoError(t, w.Unlock("new password"))
+	_, err = w.Mnemonic("password")
+	require.ErrorIs(t, err, ErrInvalidPassword)
+	exported, err := w.Mnemonic("new password")
+	require.NoError(t, err)
+	require.Equal(t, Mnemonic, exported)
+
+	var wallets []*walletdb.WalletOpts
+	require.NoError(t, engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		wallets, err = walletdb.GetAllWallets(tx)
+		return err
+	}))
+	reloaded, err := NewWallet(chain.NetworkRegtest, engine, wallets[0])
+	require.NoError(t, err)
+	require.Equal(t, params, reloaded.keyLocker.secretBox().KDFParams())
+	require.NoError(t, reloaded.Unlock("new password"))
+	exported, err = reloaded.Mnemonic("new password")
+	require.NoError(t, err)
+	require.Equal(t, Mnemonic, exported)
+}
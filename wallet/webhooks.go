@@ -0,0 +1,410 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	WebhookSignatureHeader = "X-Gohan-Signature"
+	WebhookEventHeader     = "X-Gohan-Event"
+	WebhookDeliveryHeader  = "X-Gohan-Delivery"
+
+	webhookPollInterval = 5 * time.Second
+	webhookTimeout      = 10 * time.Second
+	webhookBatchSize    = 50
+
+	// failed deliveries are retried with exponential backoff, giving up
+	// after roughly half a day
+	webhookRetryBase   = 10 * time.Second
+	webhookRetryMax    = time.Hour
+	webhookMaxAttempts = 16
+)
+
+type WebhookEventType string
+
+const (
+	WebhookPaymentReceived WebhookEventType = "payment_received"
+	WebhookSendConfirmed   WebhookEventType = "send_confirmed"
+	WebhookNameWon         WebhookEventType = "name_won"
+	WebhookNameOutbid      WebhookEventType = "name_outbid"
+	WebhookNameTransferred WebhookEventType = "name_transferred"
+	WebhookNameExpiring    WebhookEventType = "name_expiring"
+)
+
+type WebhookPayload struct {
+	ID        string           `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	AccountID string           `json:"account_id"`
+	CreatedAt int64            `json:"created_at"`
+	Data      interface{}      `json:"data"`
+}
+
+type PaymentWebhookData struct {
+	Hash   string `json:"hash"`
+	Height int    `json:"height"`
+	Value  uint64 `json:"value"`
+}
+
+type NameWebhookData struct {
+	Name         string `json:"name"`
+	Height       int    `json:"height,omitempty"`
+	ExpiryHeight int    `json:"expiry_height,omitempty"`
+	ExpiresAt    int64  `json:"expires_at,omitempty"`
+}
+
+// SignWebhookPayload returns the signature header value for a webhook
+// body, which is the hex-encoded HMAC-SHA256 of the body keyed with the
+// webhook's secret.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (a *Account) AddWebhook(rawURL string) (*walletdb.Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("webhook URL must be an absolute http or https URL")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "error generating webhook secret")
+	}
+
+	var hook *walletdb.Webhook
+	err = a.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		hook, err = walletdb.CreateWebhook(tx, a.id, u.String(), hex.EncodeToString(secret), time.Now().Unix())
+		return err
+	})
+	return hook, err
+}
+
+func (a *Account) Webhooks() ([]*walletdb.Webhook, error) {
+	var hooks []*walletdb.Webhook
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		hooks, err = walletdb.GetWebhooks(tx, a.id)
+		return err
+	})
+	return hooks, err
+}
+
+func (a *Account) RemoveWebhook(id int) error {
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		return walletdb.DeleteWebhook(tx, a.id, id)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("webhook not found")
+	}
+	return err
+}
+
+func (a *Account) hasWebhooks() bool {
+	var ok bool
+	err := a.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		ok, err = walletdb.HasWebhooks(tx, a.id)
+		return err
+	})
+	if err != nil {
+		a.lgr.Error("error checking for webhooks", "err", err)
+	}
+	return ok
+}
+
+func (a *Account) wakeWebhooks() {
+	select {
+	case a.webhookC <- struct{}{}:
+	default:
+	}
+}
+
+func (a *Account) queueWebhook(q walletdb.Transactor, key string, evtType WebhookEventType, data interface{}) error {
+	now := time.Now().Unix()
+	payload, err := json.Marshal(&WebhookPayload{
+		ID:        key,
+		Type:      evtType,
+		AccountID: a.id,
+		CreatedAt: now,
+		Data:      data,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return walletdb.QueueWebhookDelivery(q, a.id, key, string(evtType), payload, now)
+}
+
+// queueBlockWebhooks queues webhooks for the events generated while
+// scanning the block at height. incoming holds the hashes of transactions
+// that paid the account without spending any of its coins.
+func (a *Account) queueBlockWebhooks(q walletdb.Transactor, height int, events []*Event, incoming map[string]bool) error {
+	for _, evt := range events {
+		switch evt.Type {
+		case EventTransaction:
+			data := evt.Data.(*TransactionEvent)
+			if !incoming[data.Hash] {
+				continue
+			}
+			value, err := walletdb.GetReceivedValue(q, a.id, data.Hash)
+			if err != nil {
+				return err
+			}
+			if value == 0 {
+				continue
+			}
+			err = a.queueWebhook(q, fmt.Sprintf("%s:%s", WebhookPaymentReceived, data.Hash), WebhookPaymentReceived, &PaymentWebhookData{
+				Hash:   data.Hash,
+				Height: data.Height,
+				Value:  value,
+			})
+			if err != nil {
+				return err
+			}
+		case EventConfirmation:
+			data := evt.Data.(*TransactionEvent)
+			err := a.queueWebhook(q, fmt.Sprintf("%s:%s", WebhookSendConfirmed, data.Hash), WebhookSendConfirmed, data)
+			if err != nil {
+				return err
+			}
+		case EventNameStatus:
+			data := evt.Data.(*NameStatusEvent)
+			if data.Status != walletdb.NameStatusTransferred {
+				continue
+			}
+			err := a.queueWebhook(q, fmt.Sprintf("%s:%s:%d", WebhookNameTransferred, data.Name, height), WebhookNameTransferred, &NameWebhookData{
+				Name:   data.Name,
+				Height: height,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WebhookDispatcher delivers an account's queued webhooks, and queues the
+// ones that depend on the state of the chain rather than on the account's
+// own transactions: auction outcomes and upcoming name expiries. Each
+// delivery is POSTed with a signature header, and is retried with
+// exponential backoff until the endpoint responds with a 2xx status or the
+// attempts run out.
+type WebhookDispatcher struct {
+	acc    *Account
+	client *http.Client
+}
+
+func NewWebhookDispatcher(acc *Account) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		acc: acc,
+		client: &http.Client{
+			Timeout: webhookTimeout,
+		},
+	}
+}
+
+func (d *WebhookDispatcher) Start() error {
+	d.acc.onNewBlocks(d.acc.hasWebhooks, func(height int) {
+		if err := d.onBlock(height); err != nil {
+			d.acc.lgr.Error("error queueing webhooks", "err", err)
+		}
+	})
+
+	d.acc.tmb.Go(func() error {
+		tick := time.NewTicker(webhookPollInterval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-d.acc.tmb.Dying():
+				return nil
+			case <-tick.C:
+			case <-d.acc.webhookC:
+			}
+			if err := d.deliverDue(time.Now()); err != nil {
+				d.acc.lgr.Error("error delivering webhooks", "err", err)
+			}
+		}
+	})
+	return nil
+}
+
+func (d *WebhookDispatcher) onBlock(height int) error {
+	var names []string
+	var expiring []*walletdb.Name
+	window := d.acc.network.RenewalWindow
+	err := d.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+		var err error
+		names, err = walletdb.GetUnspentRevealNames(tx, d.acc.id)
+		if err != nil {
+			return err
+		}
+		expiring, err = walletdb.GetNamesDueForRenewal(tx, d.acc.id, height+d.acc.network.ExpiryWarningBlocks-window)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	type outcome struct {
+		key     string
+		evtType WebhookEventType
+		name    string
+	}
+	var outcomes []*outcome
+	if len(names) > 0 {
+		infos, err := d.acc.client.BatchGetNameInfo(names)
+		if err != nil {
+			return err
+		}
+		for i, info := range infos {
+			if info.Error != nil {
+				d.acc.lgr.Warning("error getting name info for webhooks", "name", names[i], "err", info.Error)
+				continue
+			}
+			if info.Info.Info == nil || info.Info.Info.State != "CLOSED" {
+				continue
+			}
+
+			owner := info.Info.Info.Owner
+			won, lost, err := d.acc.classifyReveals(names[i], &chain.Outpoint{
+				Hash:  owner.Hash,
+				Index: owner.Index,
+			})
+			if err != nil {
+				return err
+			}
+			evtType := WebhookNameWon
+			if !won {
+				if !lost {
+					continue
+				}
+				evtType = WebhookNameOutbid
+			}
+			outcomes = append(outcomes, &outcome{
+				key:     fmt.Sprintf("%s:%s:%s/%d", evtType, names[i], owner.Hash, owner.Index),
+				evtType: evtType,
+				name:    names[i],
+			})
+		}
+	}
+
+	if len(outcomes) == 0 && len(expiring) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	err = d.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+		for _, o := range outcomes {
+			if err := d.acc.queueWebhook(tx, o.key, o.evtType, &NameWebhookData{Name: o.name}); err != nil {
+				return err
+			}
+		}
+		for _, name := range expiring {
+			if name.RenewalHeight == 0 {
+				continue
+			}
+			expiryHeight, expiresAt := nameExpiry(name.RenewalHeight, height, window, now)
+			key := fmt.Sprintf("%s:%s:%d", WebhookNameExpiring, name.Name, name.RenewalHeight)
+			err := d.acc.queueWebhook(tx, key, WebhookNameExpiring, &NameWebhookData{
+				Name:         name.Name,
+				ExpiryHeight: expiryHeight,
+				ExpiresAt:    expiresAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	d.acc.wakeWebhooks()
+	return nil
+}
+
+func (d *WebhookDispatcher) deliverDue(now time.Time) error {
+	for {
+		var due []*walletdb.WebhookDelivery
+		err := d.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+			var err error
+			due, err = walletdb.GetDueWebhookDeliveries(tx, d.acc.id, now.Unix(), webhookBatchSize)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range due {
+			deliveryErr := d.deliver(delivery)
+			err := d.acc.engine.Transaction(func(tx walletdb.Transactor) error {
+				if deliveryErr == nil {
+					return walletdb.MarkWebhookDelivered(tx, delivery.ID, time.Now().Unix())
+				}
+
+				attempts := delivery.Attempts + 1
+				nextAttempt := time.Now().Add(webhookRetryDelay(attempts)).Unix()
+				return walletdb.MarkWebhookAttemptFailed(tx, delivery.ID, deliveryErr.Error(), nextAttempt, attempts >= webhookMaxAttempts)
+			})
+			if err != nil {
+				return err
+			}
+			if deliveryErr != nil {
+				d.acc.lgr.Warning("error delivering webhook", "id", delivery.ID, "url", delivery.URL, "attempts", delivery.Attempts+1, "err", deliveryErr)
+			}
+		}
+
+		if len(due) < webhookBatchSize {
+			return nil
+		}
+	}
+}
+
+func (d *WebhookDispatcher) deliver(delivery *walletdb.WebhookDelivery) error {
+	req, err := http.NewRequest("POST", delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Secret, delivery.Payload))
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, fmt.Sprintf("%d", delivery.ID))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	return nil
+}
+
+// webhookRetryDelay returns how long to wait before retrying a delivery
+// that has failed attempts times.
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookRetryMax {
+			return webhookRetryMax
+		}
+	}
+	return delay
+}
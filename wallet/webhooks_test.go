@@ -0,0 +1,57 @@
+package wallet
+
+import (
+	"github.com/kurumiimari/gohan/walletdb"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookRetryDelay(t *testing.T) {
+	require.Equal(t, 10*time.Second, webhookRetryDelay(1))
+	require.Equal(t, 20*time.Second, webhookRetryDelay(2))
+	require.Equal(t, 80*time.Second, webhookRetryDelay(4))
+	require.Equal(t, time.Hour, webhookRetryDelay(10))
+	require.Equal(t, time.Hour, webhookRetryDelay(webhookMaxAttempts))
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	require.Equal(
+		t,
+		"sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		SignWebhookPayload("key", []byte("The quick brown fox jumps over the lazy dog")),
+	)
+}
+
+func TestWebhookDispatcher_Deliver(t *testing.T) {
+	status := 200
+	var received *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	d := NewWebhookDispatcher(nil)
+	delivery := &walletdb.WebhookDelivery{
+		ID:        7,
+		URL:       srv.URL,
+		Secret:    "secret",
+		EventType: string(WebhookPaymentReceived),
+		Payload:   []byte(`{"type":"payment_received"}`),
+	}
+	require.NoError(t, d.deliver(delivery))
+	require.Equal(t, "POST", received.Method)
+	require.Equal(t, delivery.Payload, body)
+	require.Equal(t, SignWebhookPayload("secret", body), received.Header.Get(WebhookSignatureHeader))
+	require.Equal(t, "payment_received", received.Header.Get(WebhookEventHeader))
+	require.Equal(t, "7", received.Header.Get(WebhookDeliveryHeader))
+
+	status = 500
+	require.Error(t, d.deliver(delivery))
+}
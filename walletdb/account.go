@@ -1,24 +1,40 @@
 package walletdb
 
 import (
+	"database/sql"
+	"encoding/json"
 	"github.com/kurumiimari/gohan/chain"
 	"github.com/kurumiimari/gohan/shakedex"
 	"github.com/pkg/errors"
 )
 
 type AccountOpts struct {
-	ID              string
-	Seed            string
-	WatchOnly       bool
-	Idx             uint32
-	ChangeIdx       uint32
-	RecvIdx         uint32
-	DutchAuctionIdx uint32
-	XPub            chain.ExtendedKey
-	RescanHeight    int
-	AddressBloom    []byte
-	OutpointBloom   []byte
-	LookaheadTips   map[uint32]uint32
+	ID                   string
+	Seed                 string
+	WatchOnly            bool
+	Idx                  uint32
+	ChangeIdx            uint32
+	RecvIdx              uint32
+	DutchAuctionIdx      uint32
+	XPub                 chain.ExtendedKey
+	RescanHeight         int
+	AddressBloom         []byte
+	OutpointBloom        []byte
+	LookaheadTips        map[uint32]uint32
+	Threshold            int
+	Cosigners            []chain.ExtendedKey
+	ExternalSigner       string
+	AutoReveal           bool
+	AutoSweep            bool
+	AutoRegister         *chain.Resource
+	AutoRenewBlocks      int
+	RecvGapLimit         uint32
+	ChangeGapLimit       uint32
+	DutchAuctionGapLimit uint32
+	WalletID             string
+	// Mnemonic is the encrypted mnemonic entropy, if the account was created
+	// from a mnemonic.
+	Mnemonic string
 }
 
 func CreateAccount(
@@ -37,9 +53,16 @@ INSERT INTO accounts (
 	xpub,
 	rescan_height,
 	address_bloom, 
-	outpoint_bloom
+	outpoint_bloom,
+	multisig_threshold,
+	external_signer,
+	recv_gap_limit,
+	change_gap_limit,
+	dutch_auction_gap_limit,
+	wallet_id,
+	mnemonic
 )
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `,
 		opts.ID,
 		opts.Seed,
@@ -52,8 +75,30 @@ VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		opts.RescanHeight,
 		opts.AddressBloom,
 		opts.OutpointBloom,
+		opts.Threshold,
+		opts.ExternalSigner,
+		opts.RecvGapLimit,
+		opts.ChangeGapLimit,
+		opts.DutchAuctionGapLimit,
+		sql.NullString{String: opts.WalletID, Valid: opts.WalletID != ""},
+		sql.NullString{String: opts.Mnemonic, Valid: opts.Mnemonic != ""},
 	)
-	return errors.WithStack(err)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i, cosigner := range opts.Cosigners {
+		_, err := tx.Exec(
+			"INSERT INTO account_cosigners (account_id, idx, xpub) VALUES (?, ?, ?)",
+			opts.ID,
+			i,
+			cosigner.PublicString(),
+		)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
 }
 
 func GetAllAccounts(q Querier) ([]*AccountOpts, error) {
@@ -69,7 +114,18 @@ SELECT
 	xpub,
 	rescan_height,
 	address_bloom, 
-	outpoint_bloom
+	outpoint_bloom,
+	multisig_threshold,
+	external_signer,
+	auto_reveal,
+	auto_sweep,
+	auto_register_resource,
+	auto_renew_blocks,
+	recv_gap_limit,
+	change_gap_limit,
+	dutch_auction_gap_limit,
+	wallet_id,
+	mnemonic
 FROM accounts ORDER BY id
 `,
 	)
@@ -93,6 +149,10 @@ FROM accounts ORDER BY id
 			return nil, errors.WithStack(err)
 		}
 		acc.LookaheadTips = tips
+		acc.Cosigners, err = GetAccountCosigners(q, acc.ID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return out, errors.WithStack(rows.Err())
@@ -111,7 +171,18 @@ SELECT
 	xpub,
 	rescan_height,
 	address_bloom, 
-	outpoint_bloom
+	outpoint_bloom,
+	multisig_threshold,
+	external_signer,
+	auto_reveal,
+	auto_sweep,
+	auto_register_resource,
+	auto_renew_blocks,
+	recv_gap_limit,
+	change_gap_limit,
+	dutch_auction_gap_limit,
+	wallet_id,
+	mnemonic
 FROM accounts
 WHERE id = ?
 `,
@@ -120,7 +191,40 @@ WHERE id = ?
 	if row.Err() != nil {
 		return nil, errors.WithStack(row.Err())
 	}
-	return scanAccountOpts(row)
+	opts, err := scanAccountOpts(row)
+	if err != nil {
+		return nil, err
+	}
+	opts.Cosigners, err = GetAccountCosigners(q, opts.ID)
+	if err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+func GetAccountCosigners(q Querier, accountID string) ([]chain.ExtendedKey, error) {
+	rows, err := q.Query(
+		"SELECT xpub FROM account_cosigners WHERE account_id = ? ORDER BY idx",
+		accountID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	var cosigners []chain.ExtendedKey
+	for rows.Next() {
+		var xPubStr string
+		if err := rows.Scan(&xPubStr); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		cosigner, err := chain.NewMasterExtendedKeyFromXPub(xPubStr, chain.GetCurrNetwork())
+		if err != nil {
+			return nil, err
+		}
+		cosigners = append(cosigners, cosigner)
+	}
+	return cosigners, errors.WithStack(rows.Err())
 }
 
 func UpdateAddressIdx(tx Transactor, accountID string, branch, idx uint32) error {
@@ -158,6 +262,53 @@ func UpdateOutpointBloom(tx Transactor, accountID string, bloom []byte) error {
 	return errors.WithStack(err)
 }
 
+func UpdateAccountSeed(tx Transactor, accountID string, seed string, mnemonic string) error {
+	_, err := tx.Exec(
+		"UPDATE accounts SET seed = ?, mnemonic = ? WHERE id = ?",
+		seed,
+		sql.NullString{String: mnemonic, Valid: mnemonic != ""},
+		accountID,
+	)
+	return errors.WithStack(err)
+}
+
+func UpdateAutoReveal(tx Transactor, accountID string, enabled bool) error {
+	_, err := tx.Exec(
+		"UPDATE accounts SET auto_reveal = ? WHERE id = ?",
+		enabled,
+		accountID,
+	)
+	return errors.WithStack(err)
+}
+
+func UpdateAutoSweep(tx Transactor, accountID string, enabled bool, resource *chain.Resource) error {
+	var encoded *string
+	if resource != nil {
+		b, err := json.Marshal(resource)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		str := string(b)
+		encoded = &str
+	}
+	_, err := tx.Exec(
+		"UPDATE accounts SET auto_sweep = ?, auto_register_resource = ? WHERE id = ?",
+		enabled,
+		encoded,
+		accountID,
+	)
+	return errors.WithStack(err)
+}
+
+func UpdateAutoRenew(tx Transactor, accountID string, blocks int) error {
+	_, err := tx.Exec(
+		"UPDATE accounts SET auto_renew_blocks = ? WHERE id = ?",
+		blocks,
+		accountID,
+	)
+	return errors.WithStack(err)
+}
+
 func UpdateRescanHeight(tx Transactor, accountID string, rescanHeight int) error {
 	_, err := tx.Exec(
 		"UPDATE accounts SET rescan_height = ? WHERE id = ?",
@@ -333,6 +484,9 @@ func scanBalance(tx Transactor, query string, queryArgs ...interface{}) (uint64,
 func scanAccountOpts(scanner Scanner) (*AccountOpts, error) {
 	var err error
 	var xPubStr string
+	var autoRegister sql.NullString
+	var walletID sql.NullString
+	var mnemonic sql.NullString
 
 	opts := new(AccountOpts)
 	err = scanner.Scan(
@@ -347,10 +501,29 @@ func scanAccountOpts(scanner Scanner) (*AccountOpts, error) {
 		&opts.RescanHeight,
 		&opts.AddressBloom,
 		&opts.OutpointBloom,
+		&opts.Threshold,
+		&opts.ExternalSigner,
+		&opts.AutoReveal,
+		&opts.AutoSweep,
+		&autoRegister,
+		&opts.AutoRenewBlocks,
+		&opts.RecvGapLimit,
+		&opts.ChangeGapLimit,
+		&opts.DutchAuctionGapLimit,
+		&walletID,
+		&mnemonic,
 	)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	opts.WalletID = walletID.String
+	opts.Mnemonic = mnemonic.String
+	if autoRegister.Valid {
+		opts.AutoRegister = new(chain.Resource)
+		if err := json.Unmarshal([]byte(autoRegister.String), opts.AutoRegister); err != nil {
+			return nil, errors.Wrap(err, "error decoding auto-register resource")
+		}
+	}
 	opts.XPub, err = chain.NewMasterExtendedKeyFromXPub(xPubStr, chain.GetCurrNetwork())
 	if err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
 package walletdb
 
 import (
+	"database/sql"
 	"github.com/kurumiimari/gohan/chain"
 	"github.com/kurumiimari/gohan/shakedex"
 	"github.com/pkg/errors"
@@ -73,3 +74,107 @@ func GetLookaheadTips(q Querier, accountID string) (map[uint32]uint32, error) {
 	}
 	return tips, nil
 }
+
+// AddressInfo describes how an address has been used. Balance is the value
+// of its unspent coins, and the seen heights cover confirmed transactions
+// that paid to or spent from it.
+type AddressInfo struct {
+	Address         *chain.Address   `json:"address"`
+	Own             bool             `json:"own"`
+	Derivation      chain.Derivation `json:"derivation"`
+	Label           string           `json:"label"`
+	Received        bool             `json:"received"`
+	Balance         uint64           `json:"balance"`
+	FirstSeenHeight *int             `json:"first_seen_height"`
+	LastSeenHeight  *int             `json:"last_seen_height"`
+}
+
+const addressInfoQuery = `
+SELECT
+	a.address,
+	a.branch,
+	a.idx,
+	COALESCE((SELECT l.label FROM labels l WHERE l.account_id = a.account_id AND l.target_type = ? AND l.target = a.address), ''),
+	EXISTS (SELECT 1 FROM coins c WHERE c.account_id = a.account_id AND c.address = a.address),
+	(SELECT COALESCE(SUM(c.value), 0) FROM coins c WHERE c.account_id = a.account_id AND c.address = a.address AND c.spending_tx_hash IS NULL),
+	(SELECT MIN(t.block_height) FROM coins c JOIN transactions t ON t.account_id = c.account_id AND (t.hash = c.tx_hash OR t.hash = c.spending_tx_hash) WHERE c.account_id = a.account_id AND c.address = a.address AND t.block_height != -1),
+	(SELECT MAX(t.block_height) FROM coins c JOIN transactions t ON t.account_id = c.account_id AND (t.hash = c.tx_hash OR t.hash = c.spending_tx_hash) WHERE c.account_id = a.account_id AND c.address = a.address AND t.block_height != -1)
+FROM addresses a
+`
+
+// GetAddressInfos lists an account's addresses by derivation, optionally
+// only those on branch. Pass a negative branch to list every branch.
+func GetAddressInfos(q Querier, accountID string, branch int64, count, offset int) ([]*AddressInfo, error) {
+	rows, err := q.Query(
+		addressInfoQuery+"WHERE a.account_id = ? AND (? < 0 OR a.branch = ?) ORDER BY a.branch ASC, a.idx ASC LIMIT ? OFFSET ?",
+		LabelTypeAddress,
+		accountID,
+		branch,
+		branch,
+		count,
+		offset,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting addresses")
+	}
+	defer rows.Close()
+
+	out := make([]*AddressInfo, 0)
+	for rows.Next() {
+		info, err := scanAddressInfo(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, info)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+// GetAddressInfo returns sql.ErrNoRows if the address doesn't belong to the
+// account.
+func GetAddressInfo(q Querier, accountID string, address *chain.Address) (*AddressInfo, error) {
+	row := q.QueryRow(
+		addressInfoQuery+"WHERE a.account_id = ? AND a.address = ?",
+		LabelTypeAddress,
+		accountID,
+		address,
+	)
+	if row.Err() != nil {
+		return nil, errors.WithStack(row.Err())
+	}
+	return scanAddressInfo(row)
+}
+
+func scanAddressInfo(s Scanner) (*AddressInfo, error) {
+	info := &AddressInfo{
+		Address: new(chain.Address),
+		Own:     true,
+	}
+	var branch uint32
+	var idx uint32
+	var firstSeen sql.NullInt64
+	var lastSeen sql.NullInt64
+	err := s.Scan(
+		info.Address,
+		&branch,
+		&idx,
+		&info.Label,
+		&info.Received,
+		&info.Balance,
+		&firstSeen,
+		&lastSeen,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	info.Derivation = chain.Derivation{branch, idx}
+	if firstSeen.Valid {
+		height := int(firstSeen.Int64)
+		info.FirstSeenHeight = &height
+	}
+	if lastSeen.Valid {
+		height := int(lastSeen.Int64)
+		info.LastSeenHeight = &height
+	}
+	return info, nil
+}
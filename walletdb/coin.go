@@ -30,7 +30,9 @@ type Coin struct {
 	Covenant   *chain.Covenant
 	Prevout    *chain.Outpoint
 	Coinbase   bool
+	Frozen     bool
 	Derivation chain.Derivation
+	Label      string
 }
 
 func (c *Coin) AsChain() *chain.Coin {
@@ -127,8 +129,8 @@ INSERT INTO coins(
 	return errors.WithStack(err)
 }
 
-func GetCoinByPrevout(tx Transactor, accountID string, prevout *chain.Outpoint) (*Coin, error) {
-	row := tx.QueryRow(
+func GetCoinByPrevout(q Querier, accountID string, prevout *chain.Outpoint) (*Coin, error) {
+	row := q.QueryRow(
 		coinQuery("WHERE coins.account_id = ? AND tx_hash = ? AND out_idx = ?"),
 		accountID,
 		prevout.Hash.String(),
@@ -155,6 +157,7 @@ SELECT
 	coins.tx_hash AS tx_hash, 
 	coins.out_idx AS out_idx,
 	coins.coinbase AS coinbase,
+	coins.frozen AS frozen,
 	0 as branch,
 	0 as idx
 FROM coins
@@ -189,8 +192,16 @@ func UpdateCoinSpent(tx Transactor, outpoint *chain.Outpoint, spendHash gcrypto.
 	return errors.WithStack(err)
 }
 
-func GetFundingCoins(tx Transactor, accountID string, network *chain.Network, height int) ([]*Coin, error) {
-	rows, err := tx.Query(
+func GetFundingCoins(q Querier, accountID string, network *chain.Network, height int) ([]*Coin, error) {
+	return getSpendableCoins(q, accountID, network, height, "AND coins.frozen = FALSE")
+}
+
+func GetSpendableCoins(q Querier, accountID string, network *chain.Network, height int) ([]*Coin, error) {
+	return getSpendableCoins(q, accountID, network, height, "")
+}
+
+func getSpendableCoins(q Querier, accountID string, network *chain.Network, height int, fragment string) ([]*Coin, error) {
+	rows, err := q.Query(
 		coinQuery(`
 WHERE coins.spending_tx_hash IS NULL
 AND (
@@ -200,6 +211,7 @@ AND (
 AND coins.covenant_type = ? 
 AND coins.account_id = ?
 AND coins.type = ?
+`+fragment+`
 ORDER BY value ASC
 `),
 		height-network.CoinbaseMaturity,
@@ -222,6 +234,27 @@ ORDER BY value ASC
 	return coins, errors.WithStack(err)
 }
 
+func UpdateCoinFrozen(tx Transactor, accountID string, prevout *chain.Outpoint, frozen bool) error {
+	res, err := tx.Exec(
+		"UPDATE coins SET frozen = ? WHERE account_id = ? AND tx_hash = ? AND out_idx = ?",
+		frozen,
+		accountID,
+		prevout.Hash.String(),
+		prevout.Index,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if n == 0 {
+		return errors.Errorf("coin %s not found", prevout)
+	}
+	return nil
+}
+
 func GetUnspentCoins(q Querier, accountID string) ([]*Coin, error) {
 	rows, err := q.Query(
 		coinQuery("WHERE spending_block_height IS NULL AND account_id = ? ORDER BY block_height, tx_idx, out_idx ASC"),
@@ -258,6 +291,7 @@ SELECT
 	coins.tx_hash AS tx_hash, 
 	coins.out_idx AS out_idx,
 	coins.coinbase AS coinbase,
+	coins.frozen AS frozen,
 	0 as branch,
 	0 as idx
 FROM coins
@@ -352,6 +386,7 @@ func scanCoin(s Scanner, addlFields ...interface{}) (*Coin, error) {
 		&coin.Prevout.Hash,
 		&coin.Prevout.Index,
 		&coin.Coinbase,
+		&coin.Frozen,
 		&branch,
 		&index,
 	}, addlFields...)...)
@@ -380,6 +415,7 @@ SELECT
 	coins.tx_hash AS tx_hash, 
 	coins.out_idx AS out_idx,
 	coins.coinbase AS coinbase,
+	coins.frozen AS frozen,
 	addr.branch AS address_branch,
 	addr.idx AS address_index
 FROM coins
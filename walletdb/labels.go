@@ -0,0 +1,219 @@
+package walletdb
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+)
+
+const (
+	LabelTypeAddress     = "address"
+	LabelTypeTransaction = "transaction"
+	LabelTypeCoin        = "coin"
+)
+
+type Label struct {
+	Type      string `json:"type"`
+	Target    string `json:"target"`
+	Label     string `json:"label"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type Contact struct {
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+func SetLabel(tx Transactor, accountID, targetType, target, label string, createdAt int64) (*Label, error) {
+	_, err := tx.Exec(`
+INSERT INTO labels (account_id, target_type, target, label, created_at) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (account_id, target_type, target) DO UPDATE SET label = excluded.label
+`,
+		accountID,
+		targetType,
+		target,
+		label,
+		createdAt,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error setting label")
+	}
+	return GetLabel(tx, accountID, targetType, target)
+}
+
+// GetLabel returns sql.ErrNoRows if the target isn't labeled.
+func GetLabel(q Querier, accountID, targetType, target string) (*Label, error) {
+	label := &Label{
+		Type:   targetType,
+		Target: target,
+	}
+	err := q.QueryRow(
+		"SELECT label, created_at FROM labels WHERE account_id = ? AND target_type = ? AND target = ?",
+		accountID,
+		targetType,
+		target,
+	).Scan(&label.Label, &label.CreatedAt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return label, nil
+}
+
+// GetLabels returns an account's labels, optionally only those of
+// targetType.
+func GetLabels(q Querier, accountID, targetType string) ([]*Label, error) {
+	rows, err := q.Query(`
+SELECT target_type, target, label, created_at FROM labels
+WHERE account_id = ? AND (? = '' OR target_type = ?)
+ORDER BY target_type ASC, created_at ASC, target ASC
+`,
+		accountID,
+		targetType,
+		targetType,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting labels")
+	}
+	defer rows.Close()
+
+	out := make([]*Label, 0)
+	for rows.Next() {
+		label := new(Label)
+		if err := rows.Scan(&label.Type, &label.Target, &label.Label, &label.CreatedAt); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		out = append(out, label)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+func DeleteLabel(tx Transactor, accountID, targetType, target string) error {
+	res, err := tx.Exec(
+		"DELETE FROM labels WHERE account_id = ? AND target_type = ? AND target = ?",
+		accountID,
+		targetType,
+		target,
+	)
+	if err != nil {
+		return errors.Wrap(err, "error deleting label")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if affected == 0 {
+		return errors.WithStack(sql.ErrNoRows)
+	}
+	return nil
+}
+
+// GetAddressLabel returns the label for one of the account's own addresses,
+// or otherwise the name of the address book contact with that address.
+func GetAddressLabel(q Querier, accountID, address string) (string, error) {
+	var label string
+	err := q.QueryRow(`
+SELECT COALESCE(
+	(SELECT label FROM labels WHERE account_id = ? AND target_type = ? AND target = ?),
+	(SELECT name FROM address_book WHERE account_id = ? AND address = ?),
+	''
+)
+`,
+		accountID,
+		LabelTypeAddress,
+		address,
+		accountID,
+		address,
+	).Scan(&label)
+	return label, errors.WithStack(err)
+}
+
+// GetCoinLabel returns the coin's own label, falling back to the label of
+// the address holding it.
+func GetCoinLabel(q Querier, accountID, outpoint, address string) (string, error) {
+	var label string
+	err := q.QueryRow(`
+SELECT COALESCE(
+	(SELECT label FROM labels WHERE account_id = ? AND target_type = ? AND target = ?),
+	(SELECT label FROM labels WHERE account_id = ? AND target_type = ? AND target = ?),
+	''
+)
+`,
+		accountID,
+		LabelTypeCoin,
+		outpoint,
+		accountID,
+		LabelTypeAddress,
+		address,
+	).Scan(&label)
+	return label, errors.WithStack(err)
+}
+
+func CreateContact(tx Transactor, accountID, name, address string, createdAt int64) (*Contact, error) {
+	_, err := tx.Exec(
+		"INSERT INTO address_book (account_id, name, address, created_at) VALUES (?, ?, ?, ?)",
+		accountID,
+		name,
+		address,
+		createdAt,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating contact")
+	}
+	return &Contact{
+		Name:      name,
+		Address:   address,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// GetContact returns sql.ErrNoRows if there's no contact with that name.
+func GetContact(q Querier, accountID, name string) (*Contact, error) {
+	contact := &Contact{
+		Name: name,
+	}
+	err := q.QueryRow(
+		"SELECT address, created_at FROM address_book WHERE account_id = ? AND name = ?",
+		accountID,
+		name,
+	).Scan(&contact.Address, &contact.CreatedAt)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return contact, nil
+}
+
+func GetContacts(q Querier, accountID string) ([]*Contact, error) {
+	rows, err := q.Query(
+		"SELECT name, address, created_at FROM address_book WHERE account_id = ? ORDER BY name ASC",
+		accountID,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting contacts")
+	}
+	defer rows.Close()
+
+	out := make([]*Contact, 0)
+	for rows.Next() {
+		contact := new(Contact)
+		if err := rows.Scan(&contact.Name, &contact.Address, &contact.CreatedAt); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		out = append(out, contact)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+func DeleteContact(tx Transactor, accountID, name string) error {
+	res, err := tx.Exec("DELETE FROM address_book WHERE account_id = ? AND name = ?", accountID, name)
+	if err != nil {
+		return errors.Wrap(err, "error deleting contact")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if affected == 0 {
+		return errors.WithStack(sql.ErrNoRows)
+	}
+	return nil
+}
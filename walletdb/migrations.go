@@ -181,6 +181,161 @@ ON dutch_auction_listings(transfer_listing_tx_hash, transfer_listing_out_idx);
 `,
 		Name: "create_dutch_auction_listings",
 	},
+	{
+		Query: `
+ALTER TABLE accounts ADD COLUMN multisig_threshold INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE account_cosigners (
+	account_id VARCHAR NOT NULL,
+	idx INTEGER NOT NULL,
+	xpub VARCHAR(111) NOT NULL,
+	PRIMARY KEY (account_id, idx),
+	FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+`,
+		Name: "create_account_cosigners",
+	},
+	{
+		Query: `
+ALTER TABLE accounts ADD COLUMN external_signer VARCHAR NOT NULL DEFAULT '';
+`,
+		Name: "add_account_external_signer",
+	},
+	{
+		Query: `
+ALTER TABLE coins ADD COLUMN frozen BOOLEAN NOT NULL DEFAULT FALSE;
+`,
+		Name: "add_coins_frozen",
+	},
+	{
+		Query: `
+ALTER TABLE accounts ADD COLUMN auto_reveal BOOLEAN NOT NULL DEFAULT FALSE;
+`,
+		Name: "add_account_auto_reveal",
+	},
+	{
+		Query: `
+ALTER TABLE accounts ADD COLUMN auto_sweep BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE accounts ADD COLUMN auto_register_resource TEXT;
+ALTER TABLE name_history ADD COLUMN automated BOOLEAN NOT NULL DEFAULT FALSE;
+`,
+		Name: "add_auto_sweep",
+	},
+	{
+		Query: `
+ALTER TABLE names ADD COLUMN renewal_height INTEGER;
+ALTER TABLE accounts ADD COLUMN auto_renew_blocks INTEGER NOT NULL DEFAULT 0;
+`,
+		Name: "add_name_renewals",
+	},
+	{
+		Query: `
+ALTER TABLE transactions ADD COLUMN dropped BOOLEAN NOT NULL DEFAULT FALSE;
+`,
+		Name: "add_transactions_dropped",
+	},
+	{
+		Query: `
+CREATE TABLE webhooks (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	account_id VARCHAR NOT NULL REFERENCES accounts(id),
+	url VARCHAR NOT NULL,
+	secret VARCHAR NOT NULL,
+	created_at INTEGER NOT NULL
+);
+
+CREATE INDEX idx_webhooks_account_id ON webhooks(account_id);
+
+CREATE TABLE webhook_deliveries (
+	id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+	webhook_id INTEGER NOT NULL REFERENCES webhooks(id),
+	event_key VARCHAR NOT NULL,
+	event_type VARCHAR NOT NULL,
+	payload BLOB NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at INTEGER NOT NULL,
+	last_error VARCHAR,
+	delivered_at INTEGER,
+	failed BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at INTEGER NOT NULL,
+	UNIQUE(webhook_id, event_key)
+);
+
+CREATE INDEX idx_webhook_deliveries_next_attempt_at ON webhook_deliveries(next_attempt_at)
+	WHERE delivered_at IS NULL AND failed = FALSE;
+`,
+		Name: "add_webhooks",
+	},
+	{
+		Query: `
+CREATE TABLE labels (
+	account_id VARCHAR NOT NULL REFERENCES accounts(id),
+	target_type VARCHAR NOT NULL,
+	target VARCHAR NOT NULL,
+	label VARCHAR NOT NULL,
+	created_at INTEGER NOT NULL,
+	PRIMARY KEY (account_id, target_type, target)
+);
+
+CREATE TABLE address_book (
+	account_id VARCHAR NOT NULL REFERENCES accounts(id),
+	name VARCHAR NOT NULL,
+	address VARCHAR NOT NULL,
+	created_at INTEGER NOT NULL,
+	PRIMARY KEY (account_id, name),
+	UNIQUE (account_id, address)
+);
+`,
+		Name: "add_labels",
+	},
+	{
+		Query: `
+CREATE INDEX idx_coins_account_id_address ON coins(account_id, address);
+`,
+		Name: "add_coins_address_index",
+	},
+	{
+		Query: `
+ALTER TABLE accounts ADD COLUMN recv_gap_limit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN change_gap_limit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE accounts ADD COLUMN dutch_auction_gap_limit INTEGER NOT NULL DEFAULT 0;
+`,
+		Name: "add_account_gap_limits",
+	},
+	{
+		Query: `
+CREATE TABLE wallets (
+	id VARCHAR NOT NULL PRIMARY KEY,
+	seed TEXT NOT NULL,
+	created_at INTEGER NOT NULL
+);
+
+ALTER TABLE accounts ADD COLUMN wallet_id VARCHAR REFERENCES wallets(id);
+CREATE INDEX idx_accounts_wallet_id ON accounts(wallet_id);
+`,
+		Name: "create_wallets",
+	},
+	{
+		Query: `
+ALTER TABLE accounts ADD COLUMN mnemonic TEXT;
+ALTER TABLE wallets ADD COLUMN mnemonic TEXT;
+`,
+		Name: "add_mnemonics",
+	},
+	{
+		Query: `
+CREATE TABLE pending_replacements (
+	account_id VARCHAR NOT NULL,
+	hash VARCHAR(64) NOT NULL,
+	replaces_hash VARCHAR(64) NOT NULL,
+	raw BLOB NOT NULL,
+	PRIMARY KEY (account_id, hash)
+);
+
+CREATE INDEX idx_pending_replacements_replaces_hash ON pending_replacements(account_id, replaces_hash);
+`,
+		Name: "add_pending_replacements",
+	},
 }
 
 func MigrateDB(engine *Engine) error {
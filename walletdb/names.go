@@ -27,6 +27,7 @@ const (
 	NameActionRedeem                      NameHistoryType = "REDEEM"
 	NameActionUpdate                      NameHistoryType = "UPDATE"
 	NameActionRegister                    NameHistoryType = "REGISTER"
+	NameActionRenew                       NameHistoryType = "RENEW"
 	NameActionTransfer                    NameHistoryType = "TRANSFER"
 	NameActionTransferDutchAuctionListing NameHistoryType = "TRANSFER_DUTCH_AUCTION_LISTING"
 	NameActionFinalizeDutchAuctionListing NameHistoryType = "FINALIZE_DUTCH_AUCTION_LISTING"
@@ -54,9 +55,12 @@ type NameHistory struct {
 }
 
 type Name struct {
-	Name   string    `json:"name"`
-	Hash   string    `json:"hash"`
-	Status NameState `json:"status"`
+	Name          string    `json:"name"`
+	Hash          string    `json:"hash"`
+	Status        NameState `json:"status"`
+	RenewalHeight int       `json:"renewal_height,omitempty"`
+	ExpiryHeight  int       `json:"expiry_height,omitempty"`
+	ExpiresAt     int64     `json:"expires_at,omitempty"`
 }
 
 type RevealableBid struct {
@@ -97,12 +101,13 @@ type RichNameHistoryEntry struct {
 	OutIdx       int              `json:"out_idx"`
 	ParentTxHash *string          `json:"parent_tx_hash"`
 	ParentOutIdx *int             `json:"parent_out_idx"`
+	Automated    bool             `json:"automated"`
 	Transaction  *RichTransaction `json:"transaction"`
 }
 
 func GetNames(q Querier, accountID string, count, offset int) ([]*Name, error) {
 	rows, err := q.Query(`
-SELECT name, hash, status FROM names
+SELECT name, hash, status, renewal_height FROM names
 WHERE account_id = ?
 ORDER BY name ASC
 LIMIT ? OFFSET ?
@@ -114,18 +119,142 @@ LIMIT ? OFFSET ?
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting names")
 	}
+	defer rows.Close()
 
 	var names []*Name
 	for rows.Next() {
-		name := new(Name)
-		var status string
-		if err := rows.Scan(&name.Name, &name.Hash, &status); err != nil {
-			return nil, errors.Wrap(err, "error scanning names")
+		name, err := scanName(rows)
+		if err != nil {
+			return nil, err
 		}
-		name.Status = NameState(status)
 		names = append(names, name)
 	}
-	return names, nil
+	return names, errors.WithStack(rows.Err())
+}
+
+func GetName(q Querier, accountID string, name string) (*Name, error) {
+	row := q.QueryRow(`
+SELECT name, hash, status, renewal_height FROM names
+WHERE account_id = ? AND name = ?
+`,
+		accountID,
+		name,
+	)
+	if row.Err() != nil {
+		return nil, errors.Wrap(row.Err(), "error getting name")
+	}
+	return scanName(row)
+}
+
+// GetNameStatuses returns the status of every name the account has
+// interacted with, keyed by name.
+func GetNameStatuses(q Querier, accountID string) (map[string]NameState, error) {
+	rows, err := q.Query("SELECT name, status FROM names WHERE account_id = ?", accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting name statuses")
+	}
+	defer rows.Close()
+
+	out := make(map[string]NameState)
+	for rows.Next() {
+		var name, status string
+		if err := rows.Scan(&name, &status); err != nil {
+			return nil, errors.Wrap(err, "error scanning name statuses")
+		}
+		out[name] = NameState(status)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+// GetNamesDueForRenewal returns the account's owned names last renewed at
+// or before maxRenewalHeight, along with any owned names whose renewal
+// height is unknown.
+func GetNamesDueForRenewal(q Querier, accountID string, maxRenewalHeight int) ([]*Name, error) {
+	rows, err := q.Query(`
+SELECT name, hash, status, renewal_height FROM names
+WHERE account_id = ?
+AND status = ?
+AND (renewal_height IS NULL OR renewal_height <= ?)
+ORDER BY name ASC
+`,
+		accountID,
+		string(NameStatusOwned),
+		maxRenewalHeight,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting names due for renewal")
+	}
+	defer rows.Close()
+
+	var names []*Name
+	for rows.Next() {
+		name, err := scanName(rows)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, errors.WithStack(rows.Err())
+}
+
+// GetNamesWithoutRenewalHeight returns the account's owned names whose
+// renewal height hasn't been indexed yet, e.g. names owned before renewal
+// heights were tracked.
+func GetNamesWithoutRenewalHeight(q Querier, accountID string) ([]*Name, error) {
+	rows, err := q.Query(`
+SELECT name, hash, status, renewal_height FROM names
+WHERE account_id = ?
+AND status = ?
+AND renewal_height IS NULL
+ORDER BY name ASC
+`,
+		accountID,
+		string(NameStatusOwned),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting names without renewal heights")
+	}
+	defer rows.Close()
+
+	var names []*Name
+	for rows.Next() {
+		name, err := scanName(rows)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, errors.WithStack(rows.Err())
+}
+
+// UpdateNameRenewalHeight records the height at which an owned name was
+// last registered, renewed, or finalized.
+func UpdateNameRenewalHeight(tx Transactor, accountID string, nameHash []byte, height int) error {
+	_, err := tx.Exec(`
+UPDATE names SET renewal_height = ? WHERE account_id = ? AND hash = ? AND status = ?
+`,
+		height,
+		accountID,
+		hex.EncodeToString(nameHash),
+		string(NameStatusOwned),
+	)
+	return errors.Wrap(err, "error updating name renewal height")
+}
+
+func scanName(scanner Scanner) (*Name, error) {
+	name := new(Name)
+	var status string
+	var renewalHeight sql.NullInt64
+	if err := scanner.Scan(&name.Name, &name.Hash, &status, &renewalHeight); err != nil {
+		return nil, errors.Wrap(err, "error scanning names")
+	}
+	name.Status = NameState(status)
+	// renewal heights are left behind when names are transferred or
+	// revoked, but only mean something while the name is owned
+	if name.Status == NameStatusOwned {
+		name.RenewalHeight = int(renewalHeight.Int64)
+	}
+	return name, nil
 }
 
 func HasOwnedName(q Transactor, accountID string, name string) (bool, error) {
@@ -331,6 +460,7 @@ SELECT
 	coins.tx_hash AS tx_hash, 
 	coins.out_idx AS out_idx,
 	coins.coinbase AS coinbase,
+	coins.frozen AS frozen,
 	addr.branch AS address_branch,
 	addr.idx AS address_index,
 	hist.bid_value AS bid_value
@@ -367,6 +497,213 @@ AND coins.spending_tx_hash IS NULL
 	return bids, errors.WithStack(rows.Err())
 }
 
+func GetUnrevealedBidNames(q Querier, accountID string) ([]string, error) {
+	rows, err := q.Query(`
+SELECT DISTINCT name_history.name FROM coins
+INNER JOIN name_history ON name_history.account_id = coins.account_id AND name_history.tx_hash = coins.tx_hash AND name_history.out_idx = coins.out_idx
+WHERE coins.account_id = ?
+AND coins.covenant_type = ?
+AND coins.spending_tx_hash IS NULL
+ORDER BY name_history.name ASC
+`,
+		accountID,
+		uint8(chain.CovenantBid),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		names = append(names, name)
+	}
+	return names, errors.WithStack(rows.Err())
+}
+
+func GetUnspentRevealNames(q Querier, accountID string) ([]string, error) {
+	rows, err := q.Query(`
+SELECT DISTINCT name_history.name FROM coins
+INNER JOIN name_history ON name_history.account_id = coins.account_id AND name_history.tx_hash = coins.tx_hash AND name_history.out_idx = coins.out_idx
+WHERE coins.account_id = ?
+AND coins.covenant_type = ?
+AND coins.spending_tx_hash IS NULL
+ORDER BY name_history.name ASC
+`,
+		accountID,
+		uint8(chain.CovenantReveal),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		names = append(names, name)
+	}
+	return names, errors.WithStack(rows.Err())
+}
+
+// nameStatusByHistoryType maps the name history types that change a name's
+// status to the status they leave it in.
+var nameStatusByHistoryType = map[NameHistoryType]NameState{
+	NameActionOpen:                     NameStatusUnowned,
+	NameActionBid:                      NameStatusUnowned,
+	NameActionRegister:                 NameStatusOwned,
+	NameActionRevoke:                   NameStatusRevoked,
+	NameActionTransfer:                 NameStatusTransferring,
+	NameActionFinalizeFillDutchAuction: NameStatusOwned,
+	NameActionFinalizeIn:               NameStatusOwned,
+	NameActionFinalizeOut:              NameStatusTransferred,
+}
+
+// RestoreNameStatus recomputes a name's status from the most recent name
+// history entry that changed it. Names without any history left are
+// removed.
+func RestoreNameStatus(tx Transactor, accountID string, name string) error {
+	rows, err := tx.Query(`
+SELECT name_history.type FROM name_history
+JOIN transactions ON (transactions.hash = name_history.tx_hash AND transactions.account_id = name_history.account_id)
+WHERE name_history.account_id = ? AND name_history.name = ?
+ORDER BY CASE
+	WHEN transactions.block_height = -1 THEN 2147483647
+	ELSE transactions.block_height
+	END DESC, transactions.idx DESC, name_history.out_idx DESC
+`,
+		accountID,
+		name,
+	)
+	if err != nil {
+		return errors.Wrap(err, "error getting name history")
+	}
+
+	var found bool
+	status := NameStatusUnowned
+	for rows.Next() {
+		var historyType string
+		if err := rows.Scan(&historyType); err != nil {
+			rows.Close()
+			return errors.Wrap(err, "error scanning name history row")
+		}
+		found = true
+		if s, ok := nameStatusByHistoryType[NameHistoryType(historyType)]; ok {
+			status = s
+			break
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if !found {
+		_, err := tx.Exec("DELETE FROM names WHERE account_id = ? AND name = ?", accountID, name)
+		return errors.Wrap(err, "error deleting name")
+	}
+	return UpsertName(tx, accountID, name, status)
+}
+
+// MarkNameHistoryAutomated flags the name history entries created by a
+// transaction the wallet sent on its own.
+func MarkNameHistoryAutomated(tx Transactor, accountID string, txHash string) error {
+	_, err := tx.Exec(
+		"UPDATE name_history SET automated = TRUE WHERE account_id = ? AND tx_hash = ?",
+		accountID,
+		txHash,
+	)
+	return errors.WithStack(err)
+}
+
+// GetPendingAutomatedTransactions returns the unconfirmed, undropped
+// transactions the wallet sent on its own that created name history of
+// the given type.
+func GetPendingAutomatedTransactions(q Querier, accountID string, historyType NameHistoryType) ([]*Transaction, error) {
+	rows, err := q.Query(`
+SELECT DISTINCT
+	transactions.hash,
+	transactions.idx,
+	transactions.block_height,
+	transactions.block_hash,
+	transactions.raw,
+	transactions.time,
+	transactions.dropped
+FROM transactions
+INNER JOIN name_history ON name_history.account_id = transactions.account_id AND name_history.tx_hash = transactions.hash
+WHERE transactions.account_id = ?
+AND transactions.block_height = -1
+AND transactions.dropped = FALSE
+AND name_history.type = ?
+AND name_history.automated = TRUE
+ORDER BY transactions.hash ASC
+`,
+		accountID,
+		string(historyType),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	var out []*Transaction
+	for rows.Next() {
+		tx := new(Transaction)
+		err := rows.Scan(
+			&tx.Hash,
+			&tx.Idx,
+			&tx.BlockHeight,
+			&tx.BlockHash,
+			&tx.Raw,
+			&tx.Time,
+			&tx.Dropped,
+		)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		out = append(out, tx)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+// GetPendingActionNames returns the names with unconfirmed, undropped name
+// history of the given type.
+func GetPendingActionNames(q Querier, accountID string, historyType NameHistoryType) ([]string, error) {
+	rows, err := q.Query(`
+SELECT DISTINCT name_history.name FROM name_history
+INNER JOIN transactions ON transactions.account_id = name_history.account_id AND transactions.hash = name_history.tx_hash
+WHERE name_history.account_id = ?
+AND name_history.type = ?
+AND transactions.block_height = -1
+AND transactions.dropped = FALSE
+ORDER BY name_history.name ASC
+`,
+		accountID,
+		string(historyType),
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		names = append(names, name)
+	}
+	return names, errors.WithStack(rows.Err())
+}
+
 func GetRedeemableReveals(q Querier, accountID string, name string) ([]*Coin, error) {
 	rows, err := q.Query(
 		coinQuery(`
@@ -404,12 +741,14 @@ SELECT
 	name_history.out_idx,
 	name_history.parent_tx_hash,
 	name_history.parent_out_idx,
+	name_history.automated,
 	transactions.hash,
 	transactions.idx,
 	transactions.block_height,
 	transactions.block_hash,
 	transactions.raw,
-	transactions.time
+	transactions.time,
+	transactions.dropped
 FROM name_history
 JOIN transactions ON (transactions.hash = name_history.tx_hash AND transactions.account_id = name_history.account_id) AND transactions.account_id = name_history.account_id
 WHERE name_history.account_id = ? AND name_history.name = ?
@@ -436,18 +775,21 @@ LIMIT ? OFFSET ?
 		var parentTxHash sql.NullString
 		var parentOutIdx sql.NullInt32
 		var rawTx []byte
+		var dropped bool
 		err := rows.Scan(
 			&entry.Name,
 			&entry.Type,
 			&entry.OutIdx,
 			&parentTxHash,
 			&parentOutIdx,
+			&entry.Automated,
 			&entry.Transaction.Hash,
 			&entry.Transaction.Index,
 			&entry.Transaction.Height,
 			&entry.Transaction.Block,
 			&rawTx,
 			&entry.Transaction.Time,
+			&dropped,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, "error scanning name history row")
@@ -460,6 +802,7 @@ LIMIT ? OFFSET ?
 			entry.ParentOutIdx = &v
 		}
 		entry.Transaction.Hex = hex.EncodeToString(rawTx)
+		entry.Transaction.Status = txStatus(entry.Transaction.Height, dropped)
 		if err := fillRichTransaction(q, accountID, entry.Transaction, rawTx); err != nil {
 			return nil, errors.Wrap(err, "error filling rich transaction in name history")
 		}
@@ -554,3 +897,25 @@ LIMIT ? OFFSET ?
 	}
 	return unspents, err
 }
+
+func GetNamesByTxHash(q Querier, accountID string, txHash string) ([]string, error) {
+	rows, err := q.Query(
+		"SELECT DISTINCT name FROM name_history WHERE account_id = ? AND tx_hash = ? ORDER BY name ASC",
+		accountID,
+		txHash,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		names = append(names, name)
+	}
+	return names, errors.WithStack(rows.Err())
+}
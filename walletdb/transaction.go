@@ -9,6 +9,15 @@ import (
 	"github.com/pkg/errors"
 )
 
+const (
+	TxStatusPending   = "PENDING"
+	TxStatusConfirmed = "CONFIRMED"
+	TxStatusDropped   = "DROPPED"
+
+	TxDirectionIn  = "in"
+	TxDirectionOut = "out"
+)
+
 type Transaction struct {
 	Hash        string
 	Idx         int
@@ -16,6 +25,7 @@ type Transaction struct {
 	BlockHash   string
 	Raw         []byte
 	Time        int
+	Dropped     bool
 }
 
 func UpsertTransaction(tx Transactor, accountID string, txObj *Transaction) (*Transaction, error) {
@@ -28,7 +38,7 @@ INSERT INTO transactions (
 	block_hash,
 	raw,
 	time
-) VALUES(?, ?, ?, ?, ?, ?, ?) ON CONFLICT (account_id, hash) DO UPDATE SET block_height = ?, idx = ?, block_hash = ?, time = ?
+) VALUES(?, ?, ?, ?, ?, ?, ?) ON CONFLICT (account_id, hash) DO UPDATE SET block_height = ?, idx = ?, block_hash = ?, time = ?, dropped = FALSE
 `,
 		accountID,
 		txObj.Hash,
@@ -45,6 +55,16 @@ INSERT INTO transactions (
 	return txObj, errors.WithStack(err)
 }
 
+func UpdateTransactionRaw(tx Transactor, accountID string, hash string, raw []byte) error {
+	_, err := tx.Exec(
+		"UPDATE transactions SET raw = ? WHERE account_id = ? AND hash = ?",
+		raw,
+		accountID,
+		hash,
+	)
+	return errors.WithStack(err)
+}
+
 func GetTransactionByOutpoint(q Transactor, accountID string, hash gcrypto.Hash) (*Transaction, error) {
 	row := q.QueryRow(`
 SELECT
@@ -53,7 +73,8 @@ SELECT
 	block_height,
 	block_hash,
 	raw,
-	time
+	time,
+	dropped
 FROM transactions WHERE account_id = ? AND hash = ?
 `,
 		accountID,
@@ -71,6 +92,7 @@ FROM transactions WHERE account_id = ? AND hash = ?
 		&tx.BlockHash,
 		&tx.Raw,
 		&tx.Time,
+		&tx.Dropped,
 	)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -78,17 +100,62 @@ FROM transactions WHERE account_id = ? AND hash = ?
 	return tx, nil
 }
 
+// GetPendingTransactions returns the account's unconfirmed transactions
+// that haven't been dropped.
+func GetPendingTransactions(q Querier, accountID string) ([]*Transaction, error) {
+	rows, err := q.Query(`
+SELECT
+	hash,
+	idx,
+	block_height,
+	block_hash,
+	raw,
+	time,
+	dropped
+FROM transactions WHERE account_id = ? AND block_height = -1 AND dropped = FALSE
+`,
+		accountID,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	var out []*Transaction
+	for rows.Next() {
+		tx := new(Transaction)
+		err := rows.Scan(
+			&tx.Hash,
+			&tx.Idx,
+			&tx.BlockHeight,
+			&tx.BlockHash,
+			&tx.Raw,
+			&tx.Time,
+			&tx.Dropped,
+		)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		out = append(out, tx)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
 type RichTransaction struct {
-	Hash    gcrypto.Hash  `json:"hash"`
-	Height  int           `json:"height"`
-	Block   string        `json:"block"`
-	Time    int           `json:"time"`
-	Index   int           `json:"index"`
-	Version int           `json:"version"`
-	Inputs  []*RichInput  `json:"inputs"`
-	Outputs []*RichOutput `json:"outputs"`
-	Hex     string        `json:"hex"`
-	Fee     uint64        `json:"fee"`
+	Hash      gcrypto.Hash  `json:"hash"`
+	Height    int           `json:"height"`
+	Block     string        `json:"block"`
+	Time      int           `json:"time"`
+	Index     int           `json:"index"`
+	Version   int           `json:"version"`
+	Inputs    []*RichInput  `json:"inputs"`
+	Outputs   []*RichOutput `json:"outputs"`
+	Hex       string        `json:"hex"`
+	Fee       uint64        `json:"fee"`
+	Status    string        `json:"status"`
+	Direction string        `json:"direction"`
+	Value     uint64        `json:"value"`
+	Label     string        `json:"label"`
 }
 
 type RichInput struct {
@@ -117,55 +184,36 @@ type RichAddress struct {
 	Address    *chain.Address   `json:"address"`
 	Derivation chain.Derivation `json:"derivation"`
 	Own        bool             `json:"own"`
+	Label      string           `json:"label"`
 }
 
 func ListTransactions(q Querier, accountID string, count, offset int) ([]*RichTransaction, error) {
-	txRows, err := q.Query(`
-SELECT
-	hash,
-	idx,
-	block_height,
-	block_hash,
-	raw,
-	time
-FROM transactions
-WHERE account_id = ?
-ORDER BY block_height DESC LIMIT ? OFFSET ?
-`,
-		accountID,
-		count,
-		offset,
-	)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-	defer txRows.Close()
-
-	out := make([]*RichTransaction, 0)
-	for txRows.Next() {
-		out, err = inflateTx(q, accountID, txRows, out)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-	}
-	return out, nil
+	txs, _, err := SearchTransactions(q, accountID, &TransactionFilter{
+		Count:  count,
+		Offset: offset,
+	})
+	return txs, err
 }
 
-func inflateTx(q Querier, accountID string, r *sql.Rows, out []*RichTransaction) ([]*RichTransaction, error) {
+func inflateTx(q Querier, accountID string, r *sql.Rows, out []*RichTransaction, addlFields ...interface{}) ([]*RichTransaction, error) {
 	tx := new(RichTransaction)
 	var raw []byte
-	err := r.Scan(
+	var dropped bool
+	fields := []interface{}{
 		&tx.Hash,
 		&tx.Index,
 		&tx.Height,
 		&tx.Block,
 		&raw,
 		&tx.Time,
-	)
+		&dropped,
+	}
+	err := r.Scan(append(fields, addlFields...)...)
 	if err != nil {
 		return nil, err
 	}
 	tx.Hex = hex.EncodeToString(raw)
+	tx.Status = txStatus(tx.Height, dropped)
 	if err := fillRichTransaction(q, accountID, tx, raw); err != nil {
 		return nil, err
 	}
@@ -180,6 +228,8 @@ func fillRichTransaction(q Querier, accountID string, tx *RichTransaction, rawTx
 
 	var totalInputs uint64
 	var totalOutputs uint64
+	var ownInputs uint64
+	var ownOutputs uint64
 	hasAllInputs := true
 	for i, input := range protoTx.Inputs {
 		coin, err := GetCoinByPrevout(q, accountID, input.Prevout)
@@ -190,6 +240,10 @@ func fillRichTransaction(q Querier, accountID string, tx *RichTransaction, rawTx
 		if coin == nil {
 			hasAllInputs = false
 		} else {
+			label, err := GetAddressLabel(q, accountID, coin.Address.String())
+			if err != nil {
+				return err
+			}
 			rc = &RichCoin{
 				Version: 0,
 				Height:  coin.Height,
@@ -198,11 +252,13 @@ func fillRichTransaction(q Querier, accountID string, tx *RichTransaction, rawTx
 					Address:    coin.Address,
 					Derivation: coin.Derivation,
 					Own:        true,
+					Label:      label,
 				},
 				Covenant: coin.Covenant,
 				Coinbase: coin.Coinbase,
 			}
 			totalInputs += coin.Value
+			ownInputs += coin.Value
 		}
 
 		tx.Inputs = append(tx.Inputs, &RichInput{
@@ -224,6 +280,11 @@ func fillRichTransaction(q Querier, accountID string, tx *RichTransaction, rawTx
 		var deriv chain.Derivation
 		if coin != nil {
 			deriv = coin.Derivation
+			ownOutputs += output.Value
+		}
+		label, err := GetAddressLabel(q, accountID, output.Address.String())
+		if err != nil {
+			return err
 		}
 
 		tx.Outputs = append(tx.Outputs, &RichOutput{
@@ -232,6 +293,7 @@ func fillRichTransaction(q Querier, accountID string, tx *RichTransaction, rawTx
 				Address:    output.Address,
 				Derivation: deriv,
 				Own:        coin != nil,
+				Label:      label,
 			},
 			Covenant: output.Covenant,
 		})
@@ -242,6 +304,231 @@ func fillRichTransaction(q Querier, accountID string, tx *RichTransaction, rawTx
 	if hasAllInputs {
 		tx.Fee = totalInputs - totalOutputs
 	}
+	tx.Direction, tx.Value = txDirection(ownInputs, ownOutputs)
+
+	label, err := GetLabel(q, accountID, LabelTypeTransaction, tx.Hash.String())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if label != nil {
+		tx.Label = label.Label
+	}
 
 	return nil
 }
+
+// txDirection returns whether a transaction moved value into or out of the
+// account, and how much. Outgoing values include fees.
+func txDirection(ownInputs, ownOutputs uint64) (string, uint64) {
+	if ownOutputs > ownInputs {
+		return TxDirectionIn, ownOutputs - ownInputs
+	}
+	return TxDirectionOut, ownInputs - ownOutputs
+}
+
+func txStatus(height int, dropped bool) string {
+	switch {
+	case dropped:
+		return TxStatusDropped
+	case height == -1:
+		return TxStatusPending
+	default:
+		return TxStatusConfirmed
+	}
+}
+
+// DeleteTransaction removes an unconfirmed transaction along with any coins
+// and name history it created, and marks the coins it spent as unspent.
+func DeleteTransaction(tx Transactor, accountID string, hash string) error {
+	if err := unwindTransaction(tx, accountID, hash); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(
+		"DELETE FROM transactions WHERE account_id = ? AND hash = ? AND block_height = -1",
+		accountID,
+		hash,
+	)
+	return errors.WithStack(err)
+}
+
+// DropTransaction marks an unconfirmed transaction as dropped, along with
+// any unconfirmed transactions that spend its outputs. Like
+// AbandonTransaction, the coins it spent are released, the coins and name
+// history it created are removed, and the status of every name it touched
+// is restored. The transaction itself is kept so it still shows up in the
+// account's history.
+func DropTransaction(tx Transactor, accountID string, hash string) error {
+	names := make(map[string]bool)
+	if err := dropTransaction(tx, accountID, hash, names); err != nil {
+		return err
+	}
+
+	for name := range names {
+		if err := RestoreNameStatus(tx, accountID, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dropTransaction(tx Transactor, accountID string, hash string, names map[string]bool) error {
+	rows, err := tx.Query(
+		"SELECT DISTINCT spending_tx_hash FROM coins WHERE account_id = ? AND tx_hash = ? AND spending_tx_hash IS NOT NULL",
+		accountID,
+		hash,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var children []string
+	for rows.Next() {
+		var child string
+		if err := rows.Scan(&child); err != nil {
+			rows.Close()
+			return errors.WithStack(err)
+		}
+		children = append(children, child)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, child := range children {
+		if err := dropTransaction(tx, accountID, child, names); err != nil {
+			return err
+		}
+	}
+
+	txNames, err := GetNamesByTxHash(tx, accountID, hash)
+	if err != nil {
+		return err
+	}
+	for _, name := range txNames {
+		names[name] = true
+	}
+
+	if err := unwindTransaction(tx, accountID, hash); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE transactions SET dropped = TRUE WHERE account_id = ? AND hash = ? AND block_height = -1",
+		accountID,
+		hash,
+	)
+	return errors.WithStack(err)
+}
+
+// AbandonTransaction removes an unconfirmed transaction like
+// DeleteTransaction, then restores the status of every name it touched
+// from the name history that remains.
+func AbandonTransaction(tx Transactor, accountID string, hash string) error {
+	names, err := GetNamesByTxHash(tx, accountID, hash)
+	if err != nil {
+		return err
+	}
+
+	if err := DeleteTransaction(tx, accountID, hash); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := RestoreNameStatus(tx, accountID, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unwindTransaction(tx Transactor, accountID string, hash string) error {
+	_, err := tx.Exec(
+		"UPDATE coins SET spending_tx_hash = NULL WHERE account_id = ? AND spending_tx_hash = ?",
+		accountID,
+		hash,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = tx.Exec("DELETE FROM coins WHERE account_id = ? AND tx_hash = ?", accountID, hash)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = tx.Exec("DELETE FROM name_history WHERE account_id = ? AND tx_hash = ?", accountID, hash)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = tx.Exec("DELETE FROM pending_replacements WHERE account_id = ? AND replaces_hash = ?", accountID, hash)
+	return errors.WithStack(err)
+}
+
+// PendingReplacement is a fee bump replacement that still needs signatures.
+// It's kept apart from the account's transactions so that the transaction
+// it replaces stays in the wallet until the replacement is broadcast.
+type PendingReplacement struct {
+	Hash         string
+	ReplacesHash string
+	Raw          []byte
+}
+
+func CreatePendingReplacement(tx Transactor, accountID string, replacement *PendingReplacement) error {
+	_, err := tx.Exec(
+		"INSERT OR REPLACE INTO pending_replacements (account_id, hash, replaces_hash, raw) VALUES (?, ?, ?, ?)",
+		accountID,
+		replacement.Hash,
+		replacement.ReplacesHash,
+		replacement.Raw,
+	)
+	return errors.WithStack(err)
+}
+
+func GetPendingReplacement(q Querier, accountID string, hash string) (*PendingReplacement, error) {
+	replacement := new(PendingReplacement)
+	err := q.QueryRow(
+		"SELECT hash, replaces_hash, raw FROM pending_replacements WHERE account_id = ? AND hash = ?",
+		accountID,
+		hash,
+	).Scan(&replacement.Hash, &replacement.ReplacesHash, &replacement.Raw)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return replacement, nil
+}
+
+// ReplaceTransactionHash repoints name history and dutch auction records
+// from oldHash to newHash. The replacement must preserve the output
+// indices of every non-change output.
+func ReplaceTransactionHash(tx Transactor, accountID string, oldHash string, newHash string) error {
+	queries := []string{
+		"UPDATE name_history SET tx_hash = ? WHERE account_id = ? AND tx_hash = ?",
+		"UPDATE name_history SET parent_tx_hash = ? WHERE account_id = ? AND parent_tx_hash = ?",
+		"UPDATE dutch_auction_listings SET transfer_listing_tx_hash = ? WHERE account_id = ? AND transfer_listing_tx_hash = ?",
+		"UPDATE dutch_auction_listings SET finalize_listing_tx_hash = ? WHERE account_id = ? AND finalize_listing_tx_hash = ?",
+		"UPDATE dutch_auction_listings SET fill_tx_hash = ? WHERE account_id = ? AND fill_tx_hash = ?",
+		"UPDATE dutch_auction_listings SET transfer_cancel_tx_hash = ? WHERE account_id = ? AND transfer_cancel_tx_hash = ?",
+		"UPDATE dutch_auction_listings SET finalize_cancel_tx_hash = ? WHERE account_id = ? AND finalize_cancel_tx_hash = ?",
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, newHash, accountID, oldHash); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func HasSpentOutputs(q Querier, accountID string, hash string) (bool, error) {
+	var count int
+	row := q.QueryRow(
+		"SELECT COUNT(*) FROM coins WHERE account_id = ? AND tx_hash = ? AND spending_tx_hash IS NOT NULL",
+		accountID,
+		hash,
+	)
+	if err := row.Scan(&count); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return count > 0, nil
+}
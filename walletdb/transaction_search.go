@@ -0,0 +1,289 @@
+package walletdb
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/kurumiimari/gohan/chain"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	TxSortHeight = "height"
+	TxSortTime   = "time"
+)
+
+// TransactionFilter narrows down a transaction search. Nil and empty fields
+// don't filter anything.
+type TransactionFilter struct {
+	Name       string
+	NamePrefix string
+	Covenant   *chain.CovenantType
+	Address    string
+	Direction  string
+	MinValue   *uint64
+	MaxValue   *uint64
+	MinHeight  *int
+	MaxHeight  *int
+	StartTime  *int
+	EndTime    *int
+	Status     string
+	Sort       string
+	Ascending  bool
+	Cursor     *TransactionCursor
+	Count      int
+	Offset     int
+}
+
+// TransactionCursor marks the position of the last transaction returned by
+// a search, so that the next page picks up right after it even if new
+// transactions arrive in the meantime.
+type TransactionCursor struct {
+	Key  int
+	Hash string
+}
+
+func (c *TransactionCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", c.Key, c.Hash)))
+}
+
+func ParseTransactionCursor(s string) (*TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("invalid cursor")
+	}
+	c := new(TransactionCursor)
+	if c.Key, err = strconv.Atoi(parts[0]); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	if hash, err := hex.DecodeString(parts[1]); err != nil || len(hash) != 32 {
+		return nil, errors.New("invalid cursor")
+	}
+	c.Hash = parts[1]
+	return c, nil
+}
+
+func (f *TransactionFilter) Validate() error {
+	switch f.Sort {
+	case "", TxSortHeight, TxSortTime:
+	default:
+		return errors.New("sort must be height or time")
+	}
+	switch f.Direction {
+	case "", TxDirectionIn, TxDirectionOut:
+	default:
+		return errors.New("direction must be in or out")
+	}
+	switch f.Status {
+	case "", TxStatusPending, TxStatusConfirmed, TxStatusDropped:
+	default:
+		return errors.New("status must be PENDING, CONFIRMED, or DROPPED")
+	}
+	if f.Count < 1 {
+		return errors.New("count must be positive")
+	}
+	if f.Offset < 0 {
+		return errors.New("offset must not be negative")
+	}
+	if f.Offset > 0 && (f.Cursor != nil || f.postFiltered()) {
+		return errors.New("offset can't be combined with a cursor or covenant and address filters")
+	}
+	return nil
+}
+
+// postFiltered reports whether the filter matches on the contents of
+// transactions, which isn't possible in SQL.
+func (f *TransactionFilter) postFiltered() bool {
+	return f.Covenant != nil || f.Address != ""
+}
+
+func (f *TransactionFilter) matches(tx *RichTransaction) bool {
+	if f.Covenant != nil {
+		var found bool
+		for _, out := range tx.Outputs {
+			if out.Covenant.Type == *f.Covenant {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.Address != "" {
+		var found bool
+		for _, out := range tx.Outputs {
+			if out.Address.Address.String() == f.Address {
+				found = true
+				break
+			}
+		}
+		for _, in := range tx.Inputs {
+			if found {
+				break
+			}
+			if in.Coin != nil && in.Coin.Address.Address.String() == f.Address {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SearchTransactions returns up to filter.Count of an account's
+// transactions that match filter, along with a cursor for the next page.
+// The cursor is nil once there are no more transactions. Covenant and
+// address filters are applied after fetching from the database, so a page
+// may take several queries to fill.
+func SearchTransactions(q Querier, accountID string, filter *TransactionFilter) ([]*RichTransaction, *TransactionCursor, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	keyCol := "t.block_height"
+	if filter.Sort == TxSortTime {
+		keyCol = "t.time"
+	}
+	// transactions at the same height or time are listed by hash, and in
+	// reverse when ascending
+	dir, tieDir := "DESC", "ASC"
+	cmp, tieCmp := "<", ">"
+	if filter.Ascending {
+		dir, tieDir = tieDir, dir
+		cmp, tieCmp = tieCmp, cmp
+	}
+
+	where := []string{"t.account_id = ?"}
+	args := []interface{}{accountID}
+	if filter.Name != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM name_history h WHERE h.account_id = t.account_id AND h.tx_hash = t.hash AND h.name = ?)")
+		args = append(args, filter.Name)
+	}
+	if filter.NamePrefix != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM name_history h WHERE h.account_id = t.account_id AND h.tx_hash = t.hash AND substr(h.name, 1, length(?)) = ?)")
+		args = append(args, filter.NamePrefix, filter.NamePrefix)
+	}
+
+	net := `(
+	(SELECT COALESCE(SUM(c.value), 0) FROM coins c WHERE c.account_id = t.account_id AND c.tx_hash = t.hash) -
+	(SELECT COALESCE(SUM(c.value), 0) FROM coins c WHERE c.account_id = t.account_id AND c.spending_tx_hash = t.hash)
+)`
+	switch filter.Direction {
+	case TxDirectionIn:
+		where = append(where, net+" > 0")
+	case TxDirectionOut:
+		where = append(where, net+" <= 0")
+	}
+	if filter.MinValue != nil {
+		where = append(where, "ABS"+net+" >= ?")
+		args = append(args, *filter.MinValue)
+	}
+	if filter.MaxValue != nil {
+		where = append(where, "ABS"+net+" <= ?")
+		args = append(args, *filter.MaxValue)
+	}
+
+	if filter.MinHeight != nil {
+		where = append(where, "t.block_height >= ?")
+		args = append(args, *filter.MinHeight)
+	}
+	if filter.MaxHeight != nil {
+		where = append(where, "t.block_height != -1 AND t.block_height <= ?")
+		args = append(args, *filter.MaxHeight)
+	}
+	if filter.StartTime != nil {
+		where = append(where, "t.time >= ?")
+		args = append(args, *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		where = append(where, "t.time != -1 AND t.time <= ?")
+		args = append(args, *filter.EndTime)
+	}
+
+	switch filter.Status {
+	case TxStatusPending:
+		where = append(where, "t.block_height = -1 AND t.dropped = FALSE")
+	case TxStatusConfirmed:
+		where = append(where, "t.block_height != -1")
+	case TxStatusDropped:
+		where = append(where, "t.dropped = TRUE")
+	}
+
+	out := make([]*RichTransaction, 0)
+	cursor := filter.Cursor
+	offset := filter.Offset
+	for {
+		pageWhere := where
+		pageArgs := args
+		if cursor != nil {
+			pageWhere = append(pageWhere, fmt.Sprintf("(%s %s ? OR (%s = ? AND t.hash %s ?))", keyCol, cmp, keyCol, tieCmp))
+			pageArgs = append(pageArgs, cursor.Key, cursor.Key, cursor.Hash)
+		}
+		pageArgs = append(pageArgs, filter.Count, offset)
+
+		rows, err := q.Query(fmt.Sprintf(`
+SELECT
+	t.hash,
+	t.idx,
+	t.block_height,
+	t.block_hash,
+	t.raw,
+	t.time,
+	t.dropped,
+	%s
+FROM transactions t
+WHERE %s
+ORDER BY %s %s, t.hash %s
+LIMIT ? OFFSET ?
+`, keyCol, strings.Join(pageWhere, "\nAND "), keyCol, dir, tieDir),
+			pageArgs...,
+		)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "error searching transactions")
+		}
+
+		var fetched int
+		var last *TransactionCursor
+		for rows.Next() {
+			fetched++
+			last = new(TransactionCursor)
+			txs, err := inflateTx(q, accountID, rows, nil, &last.Key)
+			if err != nil {
+				rows.Close()
+				return nil, nil, errors.WithStack(err)
+			}
+			last.Hash = txs[0].Hash.String()
+			if !filter.matches(txs[0]) {
+				continue
+			}
+			out = append(out, txs[0])
+			if len(out) == filter.Count {
+				break
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+
+		if len(out) == filter.Count {
+			return out, last, nil
+		}
+		if fetched < filter.Count {
+			return out, nil, nil
+		}
+		cursor = last
+		offset = 0
+	}
+}
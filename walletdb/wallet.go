@@ -0,0 +1,61 @@
+package walletdb
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+)
+
+// WalletOpts describes a seed that accounts are derived from. Seed is the
+// encrypted master key, and Mnemonic the encrypted mnemonic entropy.
+type WalletOpts struct {
+	ID        string
+	Seed      string
+	Mnemonic  string
+	CreatedAt int64
+}
+
+func CreateWallet(tx Transactor, opts *WalletOpts) error {
+	_, err := tx.Exec(
+		"INSERT INTO wallets (id, seed, mnemonic, created_at) VALUES (?, ?, ?, ?)",
+		opts.ID,
+		opts.Seed,
+		sql.NullString{String: opts.Mnemonic, Valid: opts.Mnemonic != ""},
+		opts.CreatedAt,
+	)
+	return errors.Wrap(err, "error creating wallet")
+}
+
+func GetAllWallets(q Querier) ([]*WalletOpts, error) {
+	rows, err := q.Query("SELECT id, seed, mnemonic, created_at FROM wallets ORDER BY id")
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting wallets")
+	}
+	defer rows.Close()
+
+	var out []*WalletOpts
+	for rows.Next() {
+		opts := new(WalletOpts)
+		var mnemonic sql.NullString
+		if err := rows.Scan(&opts.ID, &opts.Seed, &mnemonic, &opts.CreatedAt); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		opts.Mnemonic = mnemonic.String
+		out = append(out, opts)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+func UpdateWalletSeed(tx Transactor, id string, seed string, mnemonic string) error {
+	_, err := tx.Exec(
+		"UPDATE wallets SET seed = ?, mnemonic = ? WHERE id = ?",
+		seed,
+		sql.NullString{String: mnemonic, Valid: mnemonic != ""},
+		id,
+	)
+	return errors.Wrap(err, "error updating wallet seed")
+}
+
+func DeleteWallet(tx Transactor, id string) error {
+	_, err := tx.Exec("DELETE FROM wallets WHERE id = ?", id)
+	return errors.Wrap(err, "error deleting wallet")
+}
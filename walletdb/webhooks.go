@@ -0,0 +1,233 @@
+package walletdb
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+)
+
+type Webhook struct {
+	ID                int    `json:"id"`
+	AccountID         string `json:"account_id"`
+	URL               string `json:"url"`
+	Secret            string `json:"secret,omitempty"`
+	CreatedAt         int64  `json:"created_at"`
+	PendingDeliveries int    `json:"pending_deliveries"`
+	FailedDeliveries  int    `json:"failed_deliveries"`
+	LastDeliveryError string `json:"last_delivery_error,omitempty"`
+}
+
+type WebhookDelivery struct {
+	ID        int
+	WebhookID int
+	URL       string
+	Secret    string
+	EventType string
+	Payload   []byte
+	Attempts  int
+}
+
+func CreateWebhook(tx Transactor, accountID, url, secret string, createdAt int64) (*Webhook, error) {
+	res, err := tx.Exec(
+		"INSERT INTO webhooks (account_id, url, secret, created_at) VALUES (?, ?, ?, ?)",
+		accountID,
+		url,
+		secret,
+		createdAt,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating webhook")
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Webhook{
+		ID:        int(id),
+		AccountID: accountID,
+		URL:       url,
+		Secret:    secret,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// GetWebhooks returns an account's webhooks without their secrets.
+func GetWebhooks(q Querier, accountID string) ([]*Webhook, error) {
+	rows, err := q.Query(`
+SELECT
+	webhooks.id,
+	webhooks.account_id,
+	webhooks.url,
+	webhooks.created_at,
+	(SELECT COUNT(*) FROM webhook_deliveries d WHERE d.webhook_id = webhooks.id AND d.delivered_at IS NULL AND d.failed = FALSE),
+	(SELECT COUNT(*) FROM webhook_deliveries d WHERE d.webhook_id = webhooks.id AND d.failed = TRUE),
+	(SELECT d.last_error FROM webhook_deliveries d WHERE d.webhook_id = webhooks.id AND d.last_error IS NOT NULL ORDER BY d.id DESC LIMIT 1)
+FROM webhooks
+WHERE webhooks.account_id = ?
+ORDER BY webhooks.id ASC
+`,
+		accountID,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting webhooks")
+	}
+	defer rows.Close()
+
+	var out []*Webhook
+	for rows.Next() {
+		hook := new(Webhook)
+		var lastErr sql.NullString
+		err := rows.Scan(
+			&hook.ID,
+			&hook.AccountID,
+			&hook.URL,
+			&hook.CreatedAt,
+			&hook.PendingDeliveries,
+			&hook.FailedDeliveries,
+			&lastErr,
+		)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		hook.LastDeliveryError = lastErr.String
+		out = append(out, hook)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+func HasWebhooks(q Querier, accountID string) (bool, error) {
+	var count int
+	row := q.QueryRow("SELECT COUNT(*) FROM webhooks WHERE account_id = ?", accountID)
+	if err := row.Scan(&count); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return count > 0, nil
+}
+
+// DeleteWebhook deletes a webhook along with its queued deliveries.
+func DeleteWebhook(tx Transactor, accountID string, id int) error {
+	res, err := tx.Exec("DELETE FROM webhooks WHERE account_id = ? AND id = ?", accountID, id)
+	if err != nil {
+		return errors.Wrap(err, "error deleting webhook")
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if affected == 0 {
+		return errors.WithStack(sql.ErrNoRows)
+	}
+	_, err = tx.Exec("DELETE FROM webhook_deliveries WHERE webhook_id = ?", id)
+	return errors.Wrap(err, "error deleting webhook deliveries")
+}
+
+// QueueWebhookDelivery queues payload for delivery to each of an account's
+// webhooks. Events are identified by key, so queueing the same event twice
+// is a no-op.
+func QueueWebhookDelivery(tx Transactor, accountID, key, eventType string, payload []byte, now int64) error {
+	_, err := tx.Exec(`
+INSERT INTO webhook_deliveries (
+	webhook_id,
+	event_key,
+	event_type,
+	payload,
+	next_attempt_at,
+	created_at
+) SELECT id, ?, ?, ?, ?, ? FROM webhooks WHERE account_id = ?
+ON CONFLICT (webhook_id, event_key) DO NOTHING
+`,
+		key,
+		eventType,
+		payload,
+		now,
+		now,
+		accountID,
+	)
+	return errors.Wrap(err, "error queueing webhook delivery")
+}
+
+// GetDueWebhookDeliveries returns up to limit undelivered payloads whose
+// next attempt is due.
+func GetDueWebhookDeliveries(q Querier, accountID string, now int64, limit int) ([]*WebhookDelivery, error) {
+	rows, err := q.Query(`
+SELECT
+	d.id,
+	d.webhook_id,
+	webhooks.url,
+	webhooks.secret,
+	d.event_type,
+	d.payload,
+	d.attempts
+FROM webhook_deliveries d
+JOIN webhooks ON webhooks.id = d.webhook_id
+WHERE webhooks.account_id = ?
+AND d.delivered_at IS NULL
+AND d.failed = FALSE
+AND d.next_attempt_at <= ?
+ORDER BY d.next_attempt_at ASC, d.id ASC
+LIMIT ?
+`,
+		accountID,
+		now,
+		limit,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting webhook deliveries")
+	}
+	defer rows.Close()
+
+	var out []*WebhookDelivery
+	for rows.Next() {
+		d := new(WebhookDelivery)
+		err := rows.Scan(
+			&d.ID,
+			&d.WebhookID,
+			&d.URL,
+			&d.Secret,
+			&d.EventType,
+			&d.Payload,
+			&d.Attempts,
+		)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		out = append(out, d)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+func MarkWebhookDelivered(tx Transactor, id int, now int64) error {
+	_, err := tx.Exec(
+		"UPDATE webhook_deliveries SET attempts = attempts + 1, delivered_at = ?, last_error = NULL WHERE id = ?",
+		now,
+		id,
+	)
+	return errors.Wrap(err, "error updating webhook delivery")
+}
+
+// MarkWebhookAttemptFailed records a failed delivery attempt. The delivery
+// is retried at nextAttempt, or given up on if failed is set.
+func MarkWebhookAttemptFailed(tx Transactor, id int, deliveryErr string, nextAttempt int64, failed bool) error {
+	_, err := tx.Exec(
+		"UPDATE webhook_deliveries SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?, failed = ? WHERE id = ?",
+		deliveryErr,
+		nextAttempt,
+		failed,
+		id,
+	)
+	return errors.Wrap(err, "error updating webhook delivery")
+}
+
+// GetReceivedValue returns the value of the non-name outputs of a
+// transaction paid to the account.
+func GetReceivedValue(q Querier, accountID string, hash string) (uint64, error) {
+	var value uint64
+	row := q.QueryRow(
+		"SELECT COALESCE(SUM(value), 0) FROM coins WHERE account_id = ? AND tx_hash = ? AND covenant_type = 0",
+		accountID,
+		hash,
+	)
+	if err := row.Scan(&value); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return value, nil
+}